@@ -0,0 +1,163 @@
+// Package auctions summarizes the AuctionInformationMessages from a
+// DEEP or TOPS day into a per-symbol auction schedule: the projected
+// match times, automatic extensions, and final prices of the opening,
+// closing, IPO, halt, and volatility auctions.
+package auctions
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Auction is the accumulated state of one auction, built from the
+// stream of updates IEX disseminates as the auction approaches.
+type Auction struct {
+	Symbol string
+	// The auction type name: "Opening", "Closing", "IPO", "Halt" or
+	// "Volatility".
+	Type string
+	// The final projected time of the auction match. Automatic
+	// extensions move it later.
+	ScheduledTime time.Time
+	// The highest extension number seen.
+	Extensions int
+	// Values from the most recent update.
+	PairedShares    uint32
+	ReferencePrice  float64
+	ClearingPrice   float64
+	ImbalanceShares uint32
+	// Number of updates seen for this auction.
+	Updates int
+}
+
+// auctionKey identifies an auction within a day. Recurring halt or
+// volatility auctions for the same symbol are merged.
+type auctionKey struct {
+	symbol      string
+	auctionType uint8
+}
+
+// Schedule accumulates AuctionInformationMessages into per-symbol
+// auction summaries.
+type Schedule struct {
+	auctions map[auctionKey]*Auction
+}
+
+// NewSchedule creates an empty schedule.
+func NewSchedule() *Schedule {
+	return &Schedule{
+		auctions: make(map[auctionKey]*Auction),
+	}
+}
+
+// OnMessage feeds one decoded message into the schedule. Messages
+// other than AuctionInformationMessages are ignored, so the full
+// stream of a day can be fed directly.
+func (s *Schedule) OnMessage(msg iextp.Message) {
+	m, ok := msg.(*tops.AuctionInformationMessage)
+	if !ok {
+		return
+	}
+
+	key := auctionKey{m.Symbol, m.AuctionType}
+	auction, ok := s.auctions[key]
+	if !ok {
+		auction = &Auction{
+			Symbol: m.Symbol,
+			Type:   AuctionTypeName(m.AuctionType),
+		}
+		s.auctions[key] = auction
+	}
+
+	auction.ScheduledTime = m.ScheduledAuctionTime
+	if int(m.ExtensionNumber) > auction.Extensions {
+		auction.Extensions = int(m.ExtensionNumber)
+	}
+	auction.PairedShares = m.PairedShares
+	auction.ReferencePrice = m.ReferencePrice
+	auction.ClearingPrice = m.IndicativeClearingPrice
+	auction.ImbalanceShares = m.ImbalanceShares
+	auction.Updates++
+}
+
+// Auctions returns the accumulated auctions, ordered by scheduled
+// time and then symbol.
+func (s *Schedule) Auctions() []*Auction {
+	auctions := make([]*Auction, 0, len(s.auctions))
+	for _, auction := range s.auctions {
+		auctions = append(auctions, auction)
+	}
+	sort.Slice(auctions, func(i, j int) bool {
+		if !auctions[i].ScheduledTime.Equal(auctions[j].ScheduledTime) {
+			return auctions[i].ScheduledTime.Before(auctions[j].ScheduledTime)
+		}
+		return auctions[i].Symbol < auctions[j].Symbol
+	})
+
+	return auctions
+}
+
+var csvHeader = []string{
+	"symbol",
+	"auctionType",
+	"scheduledTime",
+	"extensions",
+	"pairedShares",
+	"referencePrice",
+	"clearingPrice",
+	"imbalanceShares",
+	"updates",
+}
+
+// WriteCSV writes the schedule as CSV, one row per auction.
+func (s *Schedule) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, auction := range s.Auctions() {
+		row := []string{
+			auction.Symbol,
+			auction.Type,
+			auction.ScheduledTime.Format(time.RFC3339),
+			strconv.Itoa(auction.Extensions),
+			strconv.FormatUint(uint64(auction.PairedShares), 10),
+			strconv.FormatFloat(auction.ReferencePrice, 'f', 4, 64),
+			strconv.FormatFloat(auction.ClearingPrice, 'f', 4, 64),
+			strconv.FormatUint(uint64(auction.ImbalanceShares), 10),
+			strconv.Itoa(auction.Updates),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// AuctionTypeName returns the human-readable name of an auction type
+// byte, or "Unknown" for unrecognized values.
+func AuctionTypeName(auctionType uint8) string {
+	switch auctionType {
+	case tops.OpeningAuction:
+		return "Opening"
+	case tops.ClosingAuction:
+		return "Closing"
+	case tops.IPOAuction:
+		return "IPO"
+	case tops.HaltAuction:
+		return "Halt"
+	case tops.VolatilityAuction:
+		return "Volatility"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,98 @@
+package auctions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestSchedule(t *testing.T) {
+	open := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	close := time.Date(2017, time.April, 17, 16, 0, 0, 0, time.UTC)
+
+	s := NewSchedule()
+	// Non-auction messages are ignored.
+	s.OnMessage(&tops.TradeReportMessage{Symbol: "ZIEXT"})
+
+	// Two updates for the opening auction; the second extends it.
+	s.OnMessage(&tops.AuctionInformationMessage{
+		AuctionType:             tops.OpeningAuction,
+		Symbol:                  "ZIEXT",
+		ScheduledAuctionTime:    open,
+		ReferencePrice:          99.00,
+		IndicativeClearingPrice: 99.02,
+		PairedShares:            1000,
+	})
+	s.OnMessage(&tops.AuctionInformationMessage{
+		AuctionType:             tops.OpeningAuction,
+		Symbol:                  "ZIEXT",
+		ScheduledAuctionTime:    open.Add(time.Minute),
+		ExtensionNumber:         1,
+		ReferencePrice:          99.05,
+		IndicativeClearingPrice: 99.07,
+		PairedShares:            1500,
+	})
+	s.OnMessage(&tops.AuctionInformationMessage{
+		AuctionType:          tops.ClosingAuction,
+		Symbol:               "ZIEXT",
+		ScheduledAuctionTime: close,
+	})
+
+	auctions := s.Auctions()
+	if len(auctions) != 2 {
+		t.Fatalf("expected 2 auctions, got: %v", len(auctions))
+	}
+
+	opening := auctions[0]
+	if opening.Type != "Opening" || opening.Updates != 2 {
+		t.Errorf("unexpected auction: %+v", opening)
+	}
+	if !opening.ScheduledTime.Equal(open.Add(time.Minute)) || opening.Extensions != 1 {
+		t.Errorf("expected extended schedule, got: %+v", opening)
+	}
+	if opening.ReferencePrice != 99.05 || opening.ClearingPrice != 99.07 {
+		t.Errorf("expected final prices, got: %+v", opening)
+	}
+
+	if auctions[1].Type != "Closing" {
+		t.Errorf("unexpected auction order: %+v", auctions[1])
+	}
+}
+
+func TestScheduleWriteCSV(t *testing.T) {
+	s := NewSchedule()
+	s.OnMessage(&tops.AuctionInformationMessage{
+		AuctionType:             tops.ClosingAuction,
+		Symbol:                  "ZIEXT",
+		ScheduledAuctionTime:    time.Date(2017, time.April, 17, 16, 0, 0, 0, time.UTC),
+		ReferencePrice:          99.05,
+		IndicativeClearingPrice: 99.07,
+		PairedShares:            1000,
+	})
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	expected := "ZIEXT,Closing,2017-04-17T16:00:00Z,0,1000,99.0500,99.0700,0,1"
+	if lines[1] != expected {
+		t.Errorf("expected row %q, got: %q", expected, lines[1])
+	}
+}
+
+func TestAuctionTypeName(t *testing.T) {
+	if name := AuctionTypeName(tops.IPOAuction); name != "IPO" {
+		t.Errorf("unexpected name: %v", name)
+	}
+	if name := AuctionTypeName(0xff); name != "Unknown" {
+		t.Errorf("unexpected name: %v", name)
+	}
+}
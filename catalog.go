@@ -0,0 +1,182 @@
+package iex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// CatalogEntry records one downloaded HIST archive and what has been
+// done with it so far.
+type CatalogEntry struct {
+	// The date of the archive, as reported by the HIST endpoint,
+	// e.g. "20170417".
+	Date string `json:"date"`
+	// The feed contained in the archive, e.g. "TOPS" or "DEEP".
+	Feed string `json:"feed"`
+	// The local path of the downloaded file.
+	Path string `json:"path,omitempty"`
+	// The size of the file, in bytes.
+	Size int64 `json:"size,omitempty"`
+	// The SHA256 of the file, hex encoded.
+	SHA256 string `json:"sha256,omitempty"`
+	// Whether the file has been decoded end to end without error.
+	Decoded bool `json:"decoded,omitempty"`
+	// Paths of outputs derived from the archive (bar files, tick
+	// stores, ...), keyed by a caller-chosen output name.
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+// Catalog tracks the state of a local HIST dataset: which dates and
+// feeds have been downloaded, their sizes and checksums, whether they
+// have decoded cleanly, and what outputs have been derived from them.
+// Pipelines query it to know what work remains instead of re-listing
+// and re-hashing the download directory on every run. It is persisted
+// as a JSON file next to the data.
+type Catalog struct {
+	path    string
+	entries map[catalogKey]*CatalogEntry
+}
+
+type catalogKey struct {
+	date string
+	feed string
+}
+
+// catalogFile is the on-disk form of a Catalog.
+type catalogFile struct {
+	Entries []*CatalogEntry `json:"entries"`
+}
+
+// OpenCatalog loads the catalog at the given path, or returns an
+// empty catalog backed by that path if the file does not exist yet.
+func OpenCatalog(path string) (*Catalog, error) {
+	c := &Catalog{
+		path:    path,
+		entries: make(map[catalogKey]*CatalogEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	for _, entry := range file.Entries {
+		c.entries[catalogKey{entry.Date, entry.Feed}] = entry
+	}
+
+	return c, nil
+}
+
+// Save writes the catalog back to the path it was opened from.
+func (c *Catalog) Save() error {
+	file := catalogFile{Entries: c.Entries()}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Entries returns all entries, sorted by date then feed.
+func (c *Catalog) Entries() []*CatalogEntry {
+	entries := make([]*CatalogEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return entries[i].Feed < entries[j].Feed
+	})
+
+	return entries
+}
+
+// Get returns the entry for the given date and feed, or nil if none
+// is recorded.
+func (c *Catalog) Get(date, feed string) *CatalogEntry {
+	return c.entries[catalogKey{date, feed}]
+}
+
+// Record adds or replaces the entry for the given date and feed.
+func (c *Catalog) Record(entry *CatalogEntry) {
+	c.entries[catalogKey{entry.Date, entry.Feed}] = entry
+}
+
+// MarkDecoded records that the archive for the given date and feed
+// has been decoded end to end without error.
+func (c *Catalog) MarkDecoded(date, feed string) {
+	if entry := c.Get(date, feed); entry != nil {
+		entry.Decoded = true
+	}
+}
+
+// RecordOutput records the path of an output derived from the archive
+// for the given date and feed, under a caller-chosen name such as
+// "bars" or "ticks".
+func (c *Catalog) RecordOutput(date, feed, name, path string) {
+	entry := c.Get(date, feed)
+	if entry == nil {
+		return
+	}
+
+	if entry.Outputs == nil {
+		entry.Outputs = make(map[string]string)
+	}
+	entry.Outputs[name] = path
+}
+
+// Missing filters the given available HIST files (as listed by the
+// HIST endpoint) down to those not yet recorded in the catalog: the
+// downloads that remain.
+func (c *Catalog) Missing(available []*HIST) []*HIST {
+	var missing []*HIST
+	for _, hist := range available {
+		if c.Get(hist.Date, hist.Feed) == nil {
+			missing = append(missing, hist)
+		}
+	}
+
+	return missing
+}
+
+// Undecoded returns the downloaded archives not yet decoded cleanly,
+// sorted by date then feed: the decodes that remain.
+func (c *Catalog) Undecoded() []*CatalogEntry {
+	var entries []*CatalogEntry
+	for _, entry := range c.Entries() {
+		if !entry.Decoded {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// MissingOutput returns the decoded archives lacking a derived output
+// of the given name, sorted by date then feed: the derivations that
+// remain.
+func (c *Catalog) MissingOutput(name string) []*CatalogEntry {
+	var entries []*CatalogEntry
+	for _, entry := range c.Entries() {
+		if !entry.Decoded {
+			continue
+		}
+		if _, ok := entry.Outputs[name]; !ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
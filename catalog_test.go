@@ -0,0 +1,95 @@
+package iex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	c, err := OpenCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Entries()) != 0 {
+		t.Fatalf("expected an empty catalog, got: %+v", c.Entries())
+	}
+
+	c.Record(&CatalogEntry{
+		Date:   "20170418",
+		Feed:   "TOPS",
+		Path:   "/data/tops-20170418.pcap.gz",
+		Size:   123456,
+		SHA256: "abcdef",
+	})
+	c.Record(&CatalogEntry{
+		Date: "20170417",
+		Feed: "TOPS",
+		Path: "/data/tops-20170417.pcap.gz",
+	})
+	c.MarkDecoded("20170417", "TOPS")
+	c.RecordOutput("20170417", "TOPS", "bars", "/data/bars-20170417.csv")
+	// Updates to unknown entries are dropped, not panics.
+	c.MarkDecoded("20170419", "TOPS")
+	c.RecordOutput("20170419", "TOPS", "bars", "/data/bars-20170419.csv")
+
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and verify the round trip.
+	c, err = OpenCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %v", len(entries))
+	}
+	if entries[0].Date != "20170417" || entries[1].Date != "20170418" {
+		t.Errorf("expected entries sorted by date: %+v", entries)
+	}
+	if !entries[0].Decoded || entries[0].Outputs["bars"] != "/data/bars-20170417.csv" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if got := c.Get("20170418", "TOPS"); got == nil || got.SHA256 != "abcdef" {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+
+	available := []*HIST{
+		{Date: "20170417", Feed: "TOPS"},
+		{Date: "20170418", Feed: "TOPS"},
+		{Date: "20170418", Feed: "DEEP"},
+	}
+	missing := c.Missing(available)
+	if len(missing) != 1 || missing[0].Feed != "DEEP" {
+		t.Errorf("unexpected missing downloads: %+v", missing)
+	}
+
+	undecoded := c.Undecoded()
+	if len(undecoded) != 1 || undecoded[0].Date != "20170418" {
+		t.Errorf("unexpected undecoded entries: %+v", undecoded)
+	}
+
+	// 20170418 is not decoded yet, so only decoded entries missing the
+	// output are reported.
+	c.MarkDecoded("20170418", "TOPS")
+	needBars := c.MissingOutput("bars")
+	if len(needBars) != 1 || needBars[0].Date != "20170418" {
+		t.Errorf("unexpected entries missing bars: %+v", needBars)
+	}
+}
+
+func TestOpenCatalog_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenCatalog(path); err == nil {
+		t.Error("expected an error for a corrupt catalog")
+	}
+}
@@ -1,10 +1,13 @@
 package iex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -17,14 +20,149 @@ type HTTPClient interface {
 	Get(url string) (resp *http.Response, err error)
 }
 
+// RequestDoer is implemented by HTTP clients that can execute an
+// arbitrary request, notably http.Client. Clients that implement it
+// have the context of WithContext threaded through their requests.
+type RequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 // Client provides methods to interact with IEX's HTTP API for developers.
+//
+// Client is safe for concurrent use by multiple goroutines, provided
+// the underlying HTTPClient is (http.Client is). Identical
+// simultaneous GET requests are coalesced into a single HTTP request
+// whose response is shared by all callers, which avoids hammering the
+// API when many goroutines request the same ref-data.
 type Client struct {
 	client HTTPClient
+	// Optional context applied to every request; see WithContext.
+	ctx context.Context
+	// IEX Cloud configuration; zero values target the legacy
+	// api.iextrading.com endpoint. See NewCloudClient.
+	cloudBase string
+	version   string
+	token     string
+	// Mutable state, shared with WithContext clones.
+	state *clientState
+}
+
+// clientState is the mutable state of a Client, held behind a pointer
+// so WithContext clones share it.
+type clientState struct {
+	mu       sync.Mutex
+	inflight map[string]*inflightCall
+	usage    map[string]*EndpointUsage
+	budget   int64
+	used     int64
+}
+
+// EndpointUsage counts the requests made to a single endpoint and the
+// total size of its responses.
+type EndpointUsage struct {
+	// Number of calls made to the endpoint.
+	Calls int64
+	// Total bytes of response data returned by the endpoint, as a
+	// proxy for the message weight of metered plans.
+	Bytes int64
+}
+
+// BudgetExceededError is returned by client methods once the
+// configured usage budget has been spent.
+type BudgetExceededError struct {
+	// The configured budget, in response bytes.
+	Budget int64
+	// The response bytes consumed so far.
+	Used int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("usage budget exceeded: %v of %v bytes used",
+		e.Used, e.Budget)
+}
+
+// inflightCall is an HTTP GET in progress that late-arriving callers
+// for the same URL attach to instead of issuing their own request.
+type inflightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
 }
 
 // NewClient create a new client
 func NewClient(client HTTPClient) *Client {
-	return &Client{client}
+	return &Client{
+		client: client,
+		state: &clientState{
+			inflight: make(map[string]*inflightCall),
+			usage:    make(map[string]*EndpointUsage),
+		},
+	}
+}
+
+// WithContext returns a copy of the client whose requests carry the
+// given context, so callers can cancel slow requests or attach
+// deadlines and tracing spans. The copy shares the original's usage
+// accounting, budget, and request coalescing. Cancelling the HTTP
+// request itself requires the underlying HTTPClient to implement
+// RequestDoer (http.Client does); otherwise the context only bounds
+// the wait on coalesced in-flight calls.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+// SetBudget limits the total response bytes the client will consume.
+// Once the budget is spent, client methods return a
+// *BudgetExceededError. A budget of 0 (the default) means no limit.
+func (c *Client) SetBudget(bytes int64) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.budget = bytes
+}
+
+// Usage returns a snapshot of per-endpoint usage accumulated by this
+// client, keyed by API route (e.g. "/ref-data/symbols").
+func (c *Client) Usage() map[string]EndpointUsage {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	usage := make(map[string]EndpointUsage, len(c.state.usage))
+	for route, u := range c.state.usage {
+		usage[route] = *u
+	}
+
+	return usage
+}
+
+// checkBudget returns a *BudgetExceededError if the usage budget has
+// been spent.
+func (c *Client) checkBudget() error {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if c.state.budget > 0 && c.state.used >= c.state.budget {
+		return &BudgetExceededError{Budget: c.state.budget, Used: c.state.used}
+	}
+
+	return nil
+}
+
+// recordUsage accounts a completed call against the given route.
+func (c *Client) recordUsage(route string, bytes int) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	u, ok := c.state.usage[route]
+	if !ok {
+		u = &EndpointUsage{}
+		c.state.usage[route] = u
+	}
+
+	u.Calls++
+	u.Bytes += int64(bytes)
+	c.state.used += int64(bytes)
 }
 
 // GetTOPS provides IEX’s aggregated best quoted bid and offer
@@ -111,6 +249,53 @@ type deepRequest struct {
 	Symbols string `url:"symbols"`
 }
 
+// The channels that may be requested from the DEEP endpoint.
+const (
+	DEEPChannelBook          = "book"
+	DEEPChannelTrades        = "trades"
+	DEEPChannelSystemEvent   = "system-event"
+	DEEPChannelTradingStatus = "trading-status"
+	DEEPChannelOpHaltStatus  = "op-halt-status"
+	DEEPChannelSSRStatus     = "ssr-status"
+	DEEPChannelSecurityEvent = "security-event"
+	DEEPChannelTradeBreaks   = "trade-breaks"
+	DEEPChannelAuction       = "auction"
+)
+
+var deepChannels = map[string]bool{
+	DEEPChannelBook:          true,
+	DEEPChannelTrades:        true,
+	DEEPChannelSystemEvent:   true,
+	DEEPChannelTradingStatus: true,
+	DEEPChannelOpHaltStatus:  true,
+	DEEPChannelSSRStatus:     true,
+	DEEPChannelSecurityEvent: true,
+	DEEPChannelTradeBreaks:   true,
+	DEEPChannelAuction:       true,
+}
+
+// GetDEEPChannels requests only the given channels of the DEEP
+// payload, e.g. only trades or only book, to cut payload size. The
+// channel names are the DEEPChannel constants; only the fields of the
+// result corresponding to requested channels are populated.
+func (c *Client) GetDEEPChannels(symbol string, channels []string) (*DEEP, error) {
+	for _, channel := range channels {
+		if !deepChannels[channel] {
+			return nil, fmt.Errorf("unknown DEEP channel: %v", channel)
+		}
+	}
+
+	req := &deepChannelsRequest{symbol, channels}
+	result := &DEEP{}
+	err := c.getJSON("/deep", req, &result)
+	return result, err
+}
+
+type deepChannelsRequest struct {
+	Symbols  string   `url:"symbols"`
+	Channels []string `url:"channels,comma,omitempty"`
+}
+
 // GetBook shows IEX’s bids and asks for given symbols.
 //
 // A maximumum of 10 symbols may be requested.
@@ -311,6 +496,34 @@ func (c *Client) GetSymbols() ([]*Symbol, error) {
 	return result, err
 }
 
+// GetISINMapping returns the symbols associated with the given ISIN,
+// where the ref-data mapping endpoint is available. The returned
+// mappings carry other vendors' identifiers (FIGI) so IEX datasets
+// can be joined with external data.
+func (c *Client) GetISINMapping(isin string) ([]*SymbolMapping, error) {
+	req := &isinMappingRequest{isin}
+	var result []*SymbolMapping
+	err := c.getJSON("/ref-data/isin", req, &result)
+	return result, err
+}
+
+type isinMappingRequest struct {
+	ISIN string `url:"isin"`
+}
+
+// GetFIGIMapping returns the symbols associated with the given FIGI,
+// where the ref-data mapping endpoint is available.
+func (c *Client) GetFIGIMapping(figi string) ([]*SymbolMapping, error) {
+	req := &figiMappingRequest{figi}
+	var result []*SymbolMapping
+	err := c.getJSON("/ref-data/figi", req, &result)
+	return result, err
+}
+
+type figiMappingRequest struct {
+	FIGI string `url:"figi"`
+}
+
 // GetIntradayStats gets intra day volume and pricing data
 func (c *Client) GetIntradayStats() (*IntradayStats, error) {
 	var result *IntradayStats
@@ -499,32 +712,109 @@ func (c *Client) GetChart(symbol string, daterange string) ([]*Chart, error) {
 }
 
 func (c *Client) getJSON(route string, request interface{}, response interface{}) error {
+	if err := c.checkBudget(); err != nil {
+		return err
+	}
+
 	url := c.endpoint(route)
 
 	values, err := query.Values(request)
 	if err != nil {
 		return err
 	}
+	if c.token != "" {
+		values.Set("token", c.token)
+	}
 	queryString := values.Encode()
 	if queryString != "" {
-		url = url + "?" + queryString
+		// Some routes embed their own query string; join onto it
+		// rather than appending a second '?'.
+		if strings.Contains(url, "?") {
+			url = url + "&" + queryString
+		} else {
+			url = url + "?" + queryString
+		}
 	}
 
-	resp, err := c.client.Get(url)
+	body, err := c.getBody(url)
 	if err != nil {
 		return err
 	}
+
+	c.recordUsage(route, len(body))
+	return json.Unmarshal(body, response)
+}
+
+// getBody performs an HTTP GET for the given URL, coalescing
+// identical simultaneous requests into a single call whose response
+// body is shared by all callers.
+func (c *Client) getBody(url string) ([]byte, error) {
+	c.state.mu.Lock()
+	if call, ok := c.state.inflight[url]; ok {
+		c.state.mu.Unlock()
+		if c.ctx != nil {
+			select {
+			case <-call.done:
+			case <-c.ctx.Done():
+				return nil, c.ctx.Err()
+			}
+		} else {
+			<-call.done
+		}
+		return call.body, call.err
+	}
+
+	call := &inflightCall{done: make(chan struct{})}
+	c.state.inflight[url] = call
+	c.state.mu.Unlock()
+
+	call.body, call.err = c.fetch(url)
+
+	c.state.mu.Lock()
+	delete(c.state.inflight, url)
+	c.state.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
+
+func (c *Client) fetch(url string) ([]byte, error) {
+	resp, err := c.get(url)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("%v: %v", resp.Status, string(body))
+		return nil, fmt.Errorf("%v: %v", resp.Status, string(body))
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// get issues the HTTP GET, threading the client's context through
+// when one is set and the underlying client can execute an arbitrary
+// request.
+func (c *Client) get(url string) (*http.Response, error) {
+	if c.ctx != nil {
+		if doer, ok := c.client.(RequestDoer); ok {
+			req, err := http.NewRequestWithContext(
+				c.ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			return doer.Do(req)
+		}
 	}
 
-	dec := json.NewDecoder(resp.Body)
-	return dec.Decode(response)
+	return c.client.Get(url)
 }
 
 func (c *Client) endpoint(route string) string {
-	return baseEndpoint + route
+	if c.cloudBase == "" {
+		return baseEndpoint + route
+	}
+
+	return c.cloudBase + "/" + c.version + route
 }
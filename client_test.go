@@ -1,9 +1,13 @@
 package iex
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -704,3 +708,319 @@ func TestGetChart(t *testing.T) {
 		t.Fatalf("got unexpected empty result")
 	}
 }
+
+// blockingHTTPClient counts GETs and blocks each one until release is
+// closed.
+type blockingHTTPClient struct {
+	mu      sync.Mutex
+	gets    int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingHTTPClient) Get(url string) (*http.Response, error) {
+	c.mu.Lock()
+	c.gets++
+	if c.gets == 1 {
+		close(c.started)
+	}
+	c.mu.Unlock()
+
+	<-c.release
+
+	w := httptest.NewRecorder()
+	w.WriteString(`[{"Symbol": "AAPL", "IsEnabled": true}]`)
+	return w.Result(), nil
+}
+
+func TestClientDeduplicatesConcurrentRequests(t *testing.T) {
+	httpClient := &blockingHTTPClient{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	client := NewClient(httpClient)
+
+	var wg sync.WaitGroup
+	results := make([]error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			symbols, err := client.GetSymbols()
+			if err == nil && len(symbols) != 1 {
+				err = fmt.Errorf("unexpected symbols: %+v", symbols)
+			}
+			results[i] = err
+		}(i)
+	}
+
+	// Wait for the first request to reach the HTTP client, give the
+	// rest a moment to attach to it, then let it complete.
+	<-httpClient.started
+	time.Sleep(50 * time.Millisecond)
+	close(httpClient.release)
+	wg.Wait()
+
+	for i, err := range results {
+		if err != nil {
+			t.Errorf("goroutine %v: %v", i, err)
+		}
+	}
+
+	if httpClient.gets != 1 {
+		t.Errorf("expected 1 HTTP GET, got: %v", httpClient.gets)
+	}
+}
+
+func TestClientUsage(t *testing.T) {
+	body := `[{"Symbol": "AAPL", "IsEnabled": true}]`
+	client := NewClient(&mockHTTPClient{body: body})
+
+	if _, err := client.GetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := client.Usage()
+	u, ok := usage["/ref-data/symbols"]
+	if !ok {
+		t.Fatalf("expected usage for /ref-data/symbols, got: %v", usage)
+	}
+
+	if u.Calls != 2 {
+		t.Errorf("expected 2 calls, got: %v", u.Calls)
+	}
+	if u.Bytes != 2*int64(len(body)) {
+		t.Errorf("expected %v bytes, got: %v", 2*len(body), u.Bytes)
+	}
+}
+
+func TestClientBudget(t *testing.T) {
+	body := `[{"Symbol": "AAPL", "IsEnabled": true}]`
+	client := NewClient(&mockHTTPClient{body: body})
+	client.SetBudget(int64(len(body)))
+
+	if _, err := client.GetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.GetSymbols()
+	if err == nil {
+		t.Fatal("expected budget error")
+	}
+
+	budgetErr, ok := err.(*BudgetExceededError)
+	if !ok {
+		t.Fatalf("expected *BudgetExceededError, got: %T", err)
+	}
+	if budgetErr.Used != int64(len(body)) {
+		t.Errorf("unexpected used bytes: %v", budgetErr.Used)
+	}
+}
+
+// urlCapturingClient records the URL of each GET.
+type urlCapturingClient struct {
+	mockHTTPClient
+	url string
+}
+
+func (c *urlCapturingClient) Get(url string) (*http.Response, error) {
+	c.url = url
+	return c.mockHTTPClient.Get(url)
+}
+
+func TestGetDEEPChannels(t *testing.T) {
+	httpClient := &urlCapturingClient{
+		mockHTTPClient: mockHTTPClient{body: `{"symbol": "SNAP"}`},
+	}
+	client := NewClient(httpClient)
+
+	deep, err := client.GetDEEPChannels("SNAP",
+		[]string{DEEPChannelBook, DEEPChannelTrades})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deep.Symbol != "SNAP" {
+		t.Errorf("unexpected result: %+v", deep)
+	}
+
+	if !strings.Contains(httpClient.url, "channels=book%2Ctrades") {
+		t.Errorf("expected channels parameter in URL, got: %v", httpClient.url)
+	}
+	if !strings.Contains(httpClient.url, "symbols=SNAP") {
+		t.Errorf("expected symbols parameter in URL, got: %v", httpClient.url)
+	}
+}
+
+func TestGetDEEPChannels_Unknown(t *testing.T) {
+	client := NewClient(&mockHTTPClient{})
+	if _, err := client.GetDEEPChannels("SNAP", []string{"bogus"}); err == nil {
+		t.Fatal("expected error for unknown channel")
+	}
+}
+
+// routingHTTPClient serves a different response body depending on the
+// requested URL, for methods that hit multiple endpoints.
+type routingHTTPClient struct {
+	// Maps a URL substring to the response body served for it.
+	routes map[string]string
+}
+
+func (c *routingHTTPClient) Get(url string) (*http.Response, error) {
+	for route, body := range c.routes {
+		if strings.Contains(url, route) {
+			w := httptest.NewRecorder()
+			w.WriteString(body)
+			w.WriteHeader(200)
+			return w.Result(), nil
+		}
+	}
+
+	w := httptest.NewRecorder()
+	w.WriteHeader(404)
+	return w.Result(), nil
+}
+
+func TestGetSnapshot(t *testing.T) {
+	httpClient := &routingHTTPClient{routes: map[string]string{
+		"/stock/market/batch":  `{"SNAP": {"quote": {"symbol": "SNAP", "latestPrice": 99.05}}}`,
+		"/deep/book":           `{"SNAP": {"bids": [{"price": 99.00, "size": 100}], "asks": []}}`,
+		"/tops/last":           `[{"symbol": "SNAP", "price": 99.05, "size": 100}]`,
+		"/stock/SNAP/stats":    `{"companyName": "Snap Inc."}`,
+		"/deep/trading-status": `{"SNAP": {"status": "T"}}`,
+	}}
+
+	client := NewClient(httpClient)
+	snapshot, err := client.GetSnapshot("SNAP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Symbol != "SNAP" {
+		t.Errorf("unexpected symbol: %v", snapshot.Symbol)
+	}
+	if snapshot.Quote == nil || snapshot.Quote.LatestPrice != 99.05 {
+		t.Errorf("unexpected quote: %+v", snapshot.Quote)
+	}
+	if snapshot.Book == nil || len(snapshot.Book.Bids) != 1 {
+		t.Errorf("unexpected book: %+v", snapshot.Book)
+	}
+	if snapshot.LastTrade == nil || snapshot.LastTrade.Size != 100 {
+		t.Errorf("unexpected last trade: %+v", snapshot.LastTrade)
+	}
+	if snapshot.KeyStats == nil || snapshot.KeyStats.CompanyName != "Snap Inc." {
+		t.Errorf("unexpected key stats: %+v", snapshot.KeyStats)
+	}
+	if snapshot.TradingStatus == nil || snapshot.TradingStatus.Status != "T" {
+		t.Errorf("unexpected trading status: %+v", snapshot.TradingStatus)
+	}
+}
+
+func TestGetSnapshot_Error(t *testing.T) {
+	// Only some of the underlying endpoints respond; the first error
+	// is surfaced.
+	httpClient := &routingHTTPClient{routes: map[string]string{
+		"/tops/last": `[{"symbol": "SNAP", "price": 99.05, "size": 100}]`,
+	}}
+
+	client := NewClient(httpClient)
+	if _, err := client.GetSnapshot("SNAP"); err == nil {
+		t.Fatal("expected error when an underlying request fails")
+	}
+}
+
+func TestGetSnapshots(t *testing.T) {
+	httpClient := &routingHTTPClient{routes: map[string]string{
+		"/stock/market/batch":  `{"SNAP": {"quote": {"symbol": "SNAP"}}, "FB": {"quote": {"symbol": "FB"}}}`,
+		"/deep/book":           `{}`,
+		"/tops/last":           `[]`,
+		"/stock/SNAP/stats":    `{"companyName": "Snap Inc."}`,
+		"/stock/FB/stats":      `{"companyName": "Facebook"}`,
+		"/deep/trading-status": `{}`,
+	}}
+
+	client := NewClient(httpClient)
+	snapshots, errs := client.GetSnapshots([]string{"SNAP", "FB"}, SnapshotOptions{})
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got: %v", len(snapshots))
+	}
+	if snapshots["FB"].KeyStats.CompanyName != "Facebook" {
+		t.Errorf("unexpected snapshot: %+v", snapshots["FB"])
+	}
+}
+
+func TestGetSnapshots_PartialFailure(t *testing.T) {
+	// Stats responds only for SNAP; the other symbol's snapshot fails.
+	httpClient := &routingHTTPClient{routes: map[string]string{
+		"/stock/market/batch":  `{"SNAP": {"quote": {"symbol": "SNAP"}}}`,
+		"/deep/book":           `{}`,
+		"/tops/last":           `[]`,
+		"/stock/SNAP/stats":    `{"companyName": "Snap Inc."}`,
+		"/deep/trading-status": `{}`,
+	}}
+
+	client := NewClient(httpClient)
+	snapshots, errs := client.GetSnapshots([]string{"SNAP", "FB"}, SnapshotOptions{Concurrency: 1})
+	if _, ok := snapshots["SNAP"]; !ok {
+		t.Errorf("expected SNAP snapshot, got: %v", snapshots)
+	}
+	if _, ok := errs["FB"]; !ok {
+		t.Errorf("expected FB error, got: %v", errs)
+	}
+}
+
+func TestClientWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			fmt.Fprint(w, "[]")
+		}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 1)
+	go func() {
+		// Point the request at the test server by fetching directly.
+		_, err := client.WithContext(ctx).getBody(server.URL)
+		errs <- err
+	}()
+
+	cancel()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected an error from the cancelled request")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled request did not return")
+	}
+}
+
+func TestClientWithContext_SharedBudget(t *testing.T) {
+	client := NewClient(&mockHTTPClient{body: "[]", code: 200})
+	client.SetBudget(1)
+
+	clone := client.WithContext(context.Background())
+	if _, err := clone.GetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The clone's usage counts against the original's budget.
+	if _, err := client.GetSymbols(); err == nil {
+		t.Fatal("expected the shared budget to be spent")
+	} else if _, ok := err.(*BudgetExceededError); !ok {
+		t.Fatalf("expected a *BudgetExceededError, got: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package iex
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts away time-keeping so that components that act on
+// wall-clock boundaries (heartbeats, bar intervals, watchdogs) can be
+// driven by a fake clock in tests rather than time.Sleep.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a Ticker that delivers ticks every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the interface of time.Ticker, to allow fake
+// implementations.
+type Ticker interface {
+	// Chan returns the channel on which ticks are delivered.
+	Chan() <-chan time.Time
+	// Stop turns off the ticker. As with time.Ticker, Stop does not
+	// close the channel.
+	Stop()
+}
+
+// SystemClock is a Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t *systemTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+// FakeClock is a Clock whose time only moves when Advance is called,
+// for use in tests.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+	waiters []chan struct{}
+}
+
+// NewFakeClock creates a FakeClock initialized to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now implements Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker implements Clock.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTicker{
+		clock:    c,
+		interval: d,
+		next:     c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+	c.tickers = append(c.tickers, t)
+
+	for _, w := range c.waiters {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+
+	return t
+}
+
+// Advance moves the clock forward by d, delivering ticks to any
+// tickers whose intervals have elapsed.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		t.advanceTo(c.now)
+	}
+}
+
+// BlockUntil waits until at least n tickers have been created,
+// so tests can synchronize with components that set up tickers
+// asynchronously.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	if len(c.tickers) >= n {
+		c.mu.Unlock()
+		return
+	}
+
+	w := make(chan struct{}, 1)
+	c.waiters = append(c.waiters, w)
+	c.mu.Unlock()
+
+	for {
+		<-w
+		c.mu.Lock()
+		ok := len(c.tickers) >= n
+		c.mu.Unlock()
+		if ok {
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock    *FakeClock
+	interval time.Duration
+	next     time.Time
+	ch       chan time.Time
+	stopped  bool
+}
+
+// advanceTo delivers any ticks due at or before now.
+// Called with the clock's lock held.
+func (t *fakeTicker) advanceTo(now time.Time) {
+	for !t.stopped && !t.next.After(now) {
+		select {
+		case t.ch <- t.next:
+		default: // Drop the tick, as time.Ticker does.
+		}
+		t.next = t.next.Add(t.interval)
+	}
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.stopped = true
+}
@@ -0,0 +1,47 @@
+package iex
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	select {
+	case tick := <-ticker.Chan():
+		t.Fatalf("unexpected tick before clock advanced: %v", tick)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case tick := <-ticker.Chan():
+		expected := start.Add(time.Minute)
+		if !tick.Equal(expected) {
+			t.Fatalf("expected tick at %v, got: %v", expected, tick)
+		}
+	default:
+		t.Fatal("expected a tick after advancing the clock")
+	}
+
+	if now := clock.Now(); !now.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected Now %v, got: %v", start.Add(time.Minute), now)
+	}
+}
+
+func TestFakeClockStoppedTicker(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ticker := clock.NewTicker(time.Second)
+	ticker.Stop()
+
+	clock.Advance(time.Minute)
+	select {
+	case tick := <-ticker.Chan():
+		t.Fatalf("unexpected tick from stopped ticker: %v", tick)
+	default:
+	}
+}
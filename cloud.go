@@ -0,0 +1,42 @@
+package iex
+
+// IEX Cloud base endpoints. The legacy api.iextrading.com API is
+// deprecated; new integrations should use NewCloudClient.
+const (
+	// CloudEndpoint is the production IEX Cloud API.
+	CloudEndpoint = "https://cloud.iexapis.com"
+	// SandboxEndpoint serves scrambled test data and does not count
+	// against plan quotas. Sandbox requests require a test token
+	// (prefixed Tpk_/Tsk_).
+	SandboxEndpoint = "https://sandbox.iexapis.com"
+	// DefaultCloudVersion is the API version used when none is
+	// selected.
+	DefaultCloudVersion = "stable"
+)
+
+// NewCloudClient creates a Client targeting the production IEX Cloud
+// API with the given API token, which is attached to every request.
+// The response struct types are shared with the legacy client where
+// the endpoints map cleanly; Cloud-only response fields are simply
+// left zero on the legacy API and vice versa.
+func NewCloudClient(client HTTPClient, token string) *Client {
+	c := NewClient(client)
+	c.cloudBase = CloudEndpoint
+	c.version = DefaultCloudVersion
+	c.token = token
+	return c
+}
+
+// NewSandboxClient creates a Client targeting the IEX Cloud sandbox,
+// for integration testing with a test token against scrambled data.
+func NewSandboxClient(client HTTPClient, token string) *Client {
+	c := NewCloudClient(client, token)
+	c.cloudBase = SandboxEndpoint
+	return c
+}
+
+// SetAPIVersion selects the IEX Cloud API version, e.g. "stable",
+// "latest", or "v1". It has no effect on the legacy client.
+func (c *Client) SetAPIVersion(version string) {
+	c.version = version
+}
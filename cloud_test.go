@@ -0,0 +1,82 @@
+package iex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordingHTTPClient records the URLs requested, answering each with
+// a fixed body.
+type recordingHTTPClient struct {
+	body string
+	urls []string
+}
+
+func (c *recordingHTTPClient) Get(url string) (*http.Response, error) {
+	c.urls = append(c.urls, url)
+
+	w := httptest.NewRecorder()
+	w.WriteString(c.body)
+	w.WriteHeader(200)
+	return w.Result(), nil
+}
+
+func TestCloudClient(t *testing.T) {
+	httpClient := &recordingHTTPClient{body: "[]"}
+	client := NewCloudClient(httpClient, "pk_test")
+
+	if _, err := client.GetTOPS([]string{"SNAP"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://cloud.iexapis.com/stable/tops?symbols=SNAP&token=pk_test"
+	if len(httpClient.urls) != 1 || httpClient.urls[0] != expected {
+		t.Errorf("unexpected request URLs: %v", httpClient.urls)
+	}
+}
+
+func TestCloudClient_SandboxAndVersion(t *testing.T) {
+	httpClient := &recordingHTTPClient{body: "[]"}
+	client := NewSandboxClient(httpClient, "Tpk_test")
+	client.SetAPIVersion("v1")
+
+	if _, err := client.GetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://sandbox.iexapis.com/v1/ref-data/symbols?token=Tpk_test"
+	if len(httpClient.urls) != 1 || httpClient.urls[0] != expected {
+		t.Errorf("unexpected request URLs: %v", httpClient.urls)
+	}
+}
+
+// Routes that embed their own query string must have the token joined
+// with '&', not a second '?'.
+func TestCloudClient_EmbeddedQuery(t *testing.T) {
+	httpClient := &recordingHTTPClient{body: "[]"}
+	client := NewCloudClient(httpClient, "pk_test")
+
+	if _, err := client.GetList("gainers"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://cloud.iexapis.com/stable/stock/market/list/gainers?displayPercent=true&token=pk_test"
+	if len(httpClient.urls) != 1 || httpClient.urls[0] != expected {
+		t.Errorf("unexpected request URLs: %v", httpClient.urls)
+	}
+}
+
+func TestLegacyClientEndpointUnchanged(t *testing.T) {
+	httpClient := &recordingHTTPClient{body: "[]"}
+	client := NewClient(httpClient)
+
+	if _, err := client.GetTOPS([]string{"SNAP"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://api.iextrading.com/1.0/tops?symbols=SNAP"
+	if len(httpClient.urls) != 1 || httpClient.urls[0] != expected {
+		t.Errorf("unexpected request URLs: %v", httpClient.urls)
+	}
+}
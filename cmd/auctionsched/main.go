@@ -0,0 +1,56 @@
+// auctionsched scans a DEEP or TOPS pcap dump for auction
+// information messages and prints the day's auction schedule as CSV:
+// the opening/closing/IPO/halt auctions per symbol with their final
+// projected match times, extensions, and prices.
+//
+//	auctionsched file.pcap.gz
+//
+// The pcap dump is read from the given file, or from stdin if no file
+// is given, and may be gzipped.
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/auctions"
+	"github.com/timpalpant/go-iex/pcapio"
+)
+
+func main() {
+	input := os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scanner := iex.NewPcapScanner(packetSource)
+	schedule := auctions.NewSchedule()
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			log.Fatal(err)
+		}
+
+		schedule.OnMessage(msg)
+	}
+
+	if err := schedule.WriteCSV(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
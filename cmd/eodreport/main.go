@@ -0,0 +1,57 @@
+// eodreport scans a HIST pcap dump and prints per-symbol end-of-day
+// summaries as CSV: official open/close, total and odd-lot volume,
+// halts, closing auction results, VWAP, and the day's high/low with
+// their times.
+//
+//	eodreport file.pcap.gz > 20170417.csv
+//
+// The pcap dump is read from the given file, or from stdin if no file
+// is given, and may be gzipped. Run it once per daily dump to produce
+// one CSV per day.
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/pcapio"
+	"github.com/timpalpant/go-iex/summary"
+)
+
+func main() {
+	input := os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scanner := iex.NewPcapScanner(packetSource)
+	report := summary.NewReport()
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			log.Fatal(err)
+		}
+
+		report.OnMessage(msg)
+	}
+
+	if err := report.WriteCSV(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/timpalpant/go-iex/auctions"
+)
+
+// runAuctions prints the day's auction schedule as CSV, mirroring the
+// standalone auctionsched tool.
+func runAuctions(args []string) error {
+	fs := flag.NewFlagSet("goiex auctions", flag.ExitOnError)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	schedule := auctions.NewSchedule()
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		schedule.OnMessage(msg)
+	}
+
+	return schedule.WriteCSV(os.Stdout)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Recurring jobs can keep their settings in a JSON config file instead
+// of long shell commands. Top-level keys are flag names applied to
+// every subcommand that has that flag; a key named after a subcommand
+// holds a section of flag values for that subcommand only:
+//
+//	{
+//	  "universe": "sp500.json",
+//	  "tojson": {"envelope": true, "shard-dir": "/data/ticks"}
+//	}
+//
+// Precedence, lowest to highest: top-level keys, subcommand section,
+// GOIEX_* environment variables (e.g. GOIEX_SHARD_DIR), explicit
+// command-line flags.
+
+// envPrefix is the prefix of environment variables that override
+// config-file values, e.g. GOIEX_UNIVERSE for -universe.
+const envPrefix = "GOIEX_"
+
+// configFlag adds the shared -config flag to the flag set, returning
+// an apply function to call after parsing. The apply function fills in
+// flags that were not given on the command line from the config file
+// and the environment.
+func configFlag(fs *flag.FlagSet) func() error {
+	path := fs.String("config", "",
+		"read default flag values from the given JSON config file "+
+			"(or $GOIEX_CONFIG)")
+
+	return func() error {
+		return applyConfig(fs, *path)
+	}
+}
+
+func applyConfig(fs *flag.FlagSet, path string) error {
+	if path == "" {
+		path = os.Getenv(envPrefix + "CONFIG")
+	}
+
+	// Flags given explicitly on the command line always win.
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	if path != "" {
+		if err := applyConfigFile(fs, path, given); err != nil {
+			return err
+		}
+	}
+
+	return applyEnv(fs, given)
+}
+
+func applyConfigFile(fs *flag.FlagSet, path string, given map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("config %v: %v", path, err)
+	}
+
+	// The section for this subcommand, if any, overrides the
+	// top-level defaults, so apply the defaults first.
+	name := strings.TrimPrefix(fs.Name(), "goiex ")
+	for key, value := range config {
+		if _, isSection := value.(map[string]interface{}); isSection {
+			continue
+		}
+
+		// Top-level keys apply to every subcommand; skip ones this
+		// subcommand does not have.
+		if fs.Lookup(key) == nil || given[key] {
+			continue
+		}
+
+		if err := fs.Set(key, configValue(value)); err != nil {
+			return fmt.Errorf("config %v: %v: %v", path, key, err)
+		}
+	}
+
+	section, _ := config[name].(map[string]interface{})
+	for key, value := range section {
+		if given[key] {
+			continue
+		}
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("config %v: %v: unknown flag %q", path, name, key)
+		}
+
+		if err := fs.Set(key, configValue(value)); err != nil {
+			return fmt.Errorf("config %v: %v.%v: %v", path, name, key, err)
+		}
+	}
+
+	return nil
+}
+
+// applyEnv fills in flags from GOIEX_* environment variables, with
+// dashes in the flag name replaced by underscores.
+func applyEnv(fs *flag.FlagSet, given map[string]bool) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || given[f.Name] || f.Name == "config" {
+			return
+		}
+
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("%v: %v", name, setErr)
+		}
+	})
+
+	return err
+}
+
+// configValue renders a decoded JSON scalar as a flag value.
+func configValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("goiex tojson", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.String("universe", "", "")
+	fs.String("shard-dir", "", "")
+	fs.Bool("envelope", false, "")
+	fs.Int("precision", 4, "")
+	return fs
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "goiex.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"universe": "sp500.json",
+		"precision": 2,
+		"tojson": {"envelope": true, "shard-dir": "/data/ticks"},
+		"tocsv": {"matrix": "close"}
+	}`)
+
+	fs := newTestFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(fs, path); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"universe":  "sp500.json",
+		"precision": "2",
+		"envelope":  "true",
+		"shard-dir": "/data/ticks",
+	}
+	for name, value := range expected {
+		if got := fs.Lookup(name).Value.String(); got != value {
+			t.Errorf("flag %v = %q, expected %q", name, got, value)
+		}
+	}
+}
+
+func TestApplyConfig_Precedence(t *testing.T) {
+	path := writeConfig(t, `{
+		"universe": "from-config.json",
+		"tojson": {"shard-dir": "from-config", "precision": 2}
+	}`)
+	t.Setenv("GOIEX_SHARD_DIR", "from-env")
+
+	fs := newTestFlagSet()
+	if err := fs.Parse([]string{"-universe", "from-flag.json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(fs, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Explicit flags beat everything; env beats the config file; the
+	// config file fills in the rest.
+	expected := map[string]string{
+		"universe":  "from-flag.json",
+		"shard-dir": "from-env",
+		"precision": "2",
+	}
+	for name, value := range expected {
+		if got := fs.Lookup(name).Value.String(); got != value {
+			t.Errorf("flag %v = %q, expected %q", name, got, value)
+		}
+	}
+}
+
+func TestApplyConfig_UnknownSectionFlag(t *testing.T) {
+	path := writeConfig(t, `{"tojson": {"bogus": true}}`)
+
+	fs := newTestFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(fs, path); err == nil {
+		t.Error("expected error for unknown flag in subcommand section")
+	}
+}
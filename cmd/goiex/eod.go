@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/timpalpant/go-iex/summary"
+)
+
+// runEOD prints per-symbol end-of-day summaries as CSV, mirroring the
+// standalone eodreport tool.
+func runEOD(args []string) error {
+	fs := flag.NewFlagSet("goiex eod", flag.ExitOnError)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report := summary.NewReport()
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		report.OnMessage(msg)
+	}
+
+	return report.WriteCSV(os.Stdout)
+}
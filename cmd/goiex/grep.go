@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// runGrep extracts all messages for a symbol within a time window,
+// mirroring the standalone tickgrep tool.
+func runGrep(args []string) error {
+	fs := flag.NewFlagSet("goiex grep", flag.ExitOnError)
+	symbol := fs.String("symbol", "",
+		"symbol to extract (required unless -universe is given)")
+	from := fs.String("from", "", "start of the time window, e.g. 15:59:00")
+	to := fs.String("to", "", "end of the time window, e.g. 16:01:00")
+	loadUniverse := universeFlag(fs)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	universe, err := loadUniverse()
+	if err != nil {
+		return err
+	}
+	if *symbol == "" && universe == nil {
+		fs.Usage()
+		return fmt.Errorf("one of -symbol or -universe is required")
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	output := bufio.NewWriter(os.Stdout)
+	defer output.Flush()
+	enc := json.NewEncoder(output)
+
+	parsed := iex.ParseSymbol(*symbol)
+	if *symbol != "" && universe == nil {
+		// A symbol pattern can only match its raw form or its bare
+		// base, so a raw 8-byte prefilter on those two skips the body
+		// decode of everything else in the file. The decoded checks
+		// below still apply to whatever survives.
+		if filter, err := tops.NewSymbolFilter(parsed.Raw, parsed.Base); err == nil {
+			scanner.SetRawFilter(filter.Match)
+		}
+	}
+
+	var window timeWindow
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		ts, hasTime := iex.MessageTimestamp(msg)
+		if hasTime {
+			if err := window.resolve(*from, *to, ts); err != nil {
+				return err
+			}
+
+			if window.after(ts) {
+				// Feed timestamps are (nearly) ordered, so once
+				// we are well past the window there is nothing
+				// left to find.
+				break
+			}
+
+			if window.before(ts) {
+				continue
+			}
+		}
+
+		msgSymbol, ok := iex.MessageSymbol(msg)
+		if !ok {
+			continue
+		}
+		if *symbol != "" && !parsed.Matches(msgSymbol) {
+			continue
+		}
+		if universe != nil && !universe.Contains(msgSymbol) {
+			continue
+		}
+
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// timeWindow is a [from, to] window whose bounds may be given as bare
+// wall-clock times, resolved against the date of the first message.
+type timeWindow struct {
+	resolved bool
+	from, to time.Time
+}
+
+// How far past the end of the window to keep scanning, to tolerate
+// slightly out-of-order event timestamps.
+const windowSlack = time.Minute
+
+func (w *timeWindow) resolve(from, to string, ts time.Time) error {
+	if w.resolved {
+		return nil
+	}
+
+	var err error
+	if w.from, err = parseTime(from, ts); err != nil {
+		return err
+	}
+	if w.to, err = parseTime(to, ts); err != nil {
+		return err
+	}
+
+	w.resolved = true
+	return nil
+}
+
+func (w *timeWindow) before(ts time.Time) bool {
+	return !w.from.IsZero() && ts.Before(w.from)
+}
+
+func (w *timeWindow) after(ts time.Time) bool {
+	return !w.to.IsZero() && ts.After(w.to.Add(windowSlack))
+}
+
+// parseTime parses an RFC3339 timestamp or a bare wall-clock time on
+// the date of the given reference timestamp. An empty value means no
+// bound.
+func parseTime(value string, reference time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("15:04:05", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := reference.UTC().Date()
+	return time.Date(year, month, day,
+		t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+}
@@ -0,0 +1,70 @@
+// goiex is the unified command-line entrypoint for this repository's
+// pcap tools. Each subcommand reads an IEX HIST pcap dump from a file
+// argument or stdin (optionally gzipped) and shares the same
+// data-source detection, symbol filtering, and output sinks:
+//
+//	goiex tojson [flags] [file.pcap.gz]   decode messages to NDJSON
+//	goiex tocsv [flags] [file.pcap.gz]    consolidate trades to CSV bars
+//	goiex grep [flags] [file.pcap.gz]     extract a symbol/time window
+//	goiex auctions [file.pcap.gz]         print the day's auction schedule
+//	goiex eod [file.pcap.gz]              print per-symbol daily summaries
+//	goiex tca [flags] [file.pcap.gz]      benchmark a trade list (TCA)
+//	goiex timeline [flags] [file.pcap.gz] print the halt/SSR timeline
+//	goiex skew [file.pcap.gz]             report capture-vs-send clock skew
+//
+// Run goiex <subcommand> -h for the flags of each subcommand.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is one goiex subcommand.
+type command struct {
+	name     string
+	synopsis string
+	run      func(args []string) error
+}
+
+var commands = []command{
+	{"tojson", "decode messages to NDJSON", runToJSON},
+	{"tocsv", "consolidate trades to CSV bars", runToCSV},
+	{"grep", "extract messages for a symbol or time window", runGrep},
+	{"auctions", "print the day's auction schedule as CSV", runAuctions},
+	{"eod", "print per-symbol end-of-day summaries as CSV", runEOD},
+	{"tca", "benchmark a trade list against arrival quotes", runTCA},
+	{"timeline", "print the day's halt and SSR status timeline", runTimeline},
+	{"skew", "report capture-vs-send-time clock skew statistics", runSkew},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "goiex %v: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "goiex: unknown subcommand %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: goiex <subcommand> [flags] [file.pcap.gz]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "subcommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10v %v\n", cmd.name, cmd.synopsis)
+	}
+}
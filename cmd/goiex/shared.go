@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/pcapio"
+)
+
+// newScanner opens the subcommand's input — the first positional
+// argument, or stdin if none is given — and returns a scanner over
+// it. The caller must call the returned cleanup function when done.
+func newScanner(fs *flag.FlagSet) (*iex.PcapScanner, func(), error) {
+	input := os.Stdin
+	cleanup := func() {}
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return nil, nil, err
+		}
+		input = f
+		cleanup = func() { f.Close() }
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(input)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return iex.NewPcapScanner(packetSource), cleanup, nil
+}
+
+// universeFlag adds the shared -universe flag to the flag set,
+// returning a loader to call after parsing. A nil universe means no
+// filtering.
+func universeFlag(fs *flag.FlagSet) func() (*iex.Universe, error) {
+	path := fs.String("universe", "",
+		"only include symbols in the given universe spec file "+
+			"(plain text or JSON)")
+
+	return func() (*iex.Universe, error) {
+		if *path == "" {
+			return nil, nil
+		}
+
+		return iex.LoadUniverse(*path)
+	}
+}
+
+// inUniverse reports whether the given message should be included
+// under the given universe filter.
+func inUniverse(universe *iex.Universe, msg iextp.Message) bool {
+	if universe == nil {
+		return true
+	}
+
+	symbol, ok := iex.MessageSymbol(msg)
+	return !ok || universe.Contains(symbol)
+}
+
+// writeMetadata writes a sidecar JSON file of feed metadata.
+func writeMetadata(metadata iex.FeedMetadata, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metadata)
+}
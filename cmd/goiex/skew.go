@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/pcapio"
+)
+
+// runSkew compares pcap capture timestamps against IEX-TP send times
+// across the dump. It reads segment headers only, so it runs much
+// faster than a full decode.
+func runSkew(args []string) error {
+	fs := flag.NewFlagSet("goiex skew", flag.ExitOnError)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	input := os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	// The capture timestamps live in the pcap record headers, so this
+	// command needs the data source itself, not a PcapScanner.
+	source, err := pcapio.NewPcapDataSource(input)
+	if err != nil {
+		return err
+	}
+
+	skew := iex.NewCaptureSkew()
+	var header iextp.SegmentHeader
+	for {
+		payload, err := source.NextPayload()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		if err := header.Unmarshal(payload); err != nil {
+			return err
+		}
+
+		skew.Observe(source.CaptureInfo().Timestamp, header.SendTime)
+	}
+
+	report := skew.Report()
+	fmt.Printf("segments: %v\n", report.Count)
+	if report.Count == 0 {
+		return nil
+	}
+
+	fmt.Printf("negative: %v (%.4f%%)\n", report.Negative,
+		100*float64(report.Negative)/float64(report.Count))
+	fmt.Printf("min:      %v\n", report.Min)
+	fmt.Printf("p50:      %v\n", report.P50)
+	fmt.Printf("p90:      %v\n", report.P90)
+	fmt.Printf("p99:      %v\n", report.P99)
+	fmt.Printf("max:      %v\n", report.Max)
+	fmt.Printf("mean:     %v\n", report.Mean)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timpalpant/go-iex/tca"
+)
+
+// runTCA benchmarks a trade list against the day's decoded TOPS data.
+func runTCA(args []string) error {
+	fs := flag.NewFlagSet("goiex tca", flag.ExitOnError)
+	executionsFile := fs.String("executions", "",
+		"CSV trade list to benchmark, with columns "+
+			"symbol,time,side,size,price (required)")
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	if *executionsFile == "" {
+		fs.Usage()
+		return fmt.Errorf("-executions is required")
+	}
+
+	f, err := os.Open(*executionsFile)
+	if err != nil {
+		return err
+	}
+	executions, err := tca.ReadExecutions(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	calc := tca.NewCalculator(executions)
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		calc.OnMessage(msg)
+	}
+
+	return calc.WriteCSV(os.Stdout)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timpalpant/go-iex/timeline"
+)
+
+// runTimeline prints the day's halt and short sale restriction
+// timeline.
+func runTimeline(args []string) error {
+	fs := flag.NewFlagSet("goiex timeline", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or json")
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("invalid format: %q", *format)
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	events := timeline.NewTimeline()
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		events.OnMessage(msg)
+	}
+
+	if *format == "json" {
+		return events.WriteJSON(os.Stdout)
+	}
+
+	return events.WriteCSV(os.Stdout)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/collector"
+	"github.com/timpalpant/go-iex/consolidator"
+)
+
+var csvHeader = []string{
+	"symbol",
+	"time",
+	"open",
+	"high",
+	"low",
+	"close",
+	"volume",
+}
+
+// runToCSV consolidates trades into minute bars as CSV, mirroring the
+// standalone pcap2csv tool.
+func runToCSV(args []string) error {
+	fs := flag.NewFlagSet("goiex tocsv", flag.ExitOnError)
+	interval := fs.Duration("interval", time.Minute,
+		"bar interval, e.g. 1s, 5s, 1m, 5m, 1h, 24h")
+	matrixField := fs.String("matrix", "",
+		"emit a wide by-minute matrix of the given bar field "+
+			"(close or volume) instead of long rows")
+	columns := fs.String("columns", "",
+		"append extra bar columns to long rows (comma separated): "+
+			"vwap, count, notional")
+	from := fs.String("from", "", "start of the time window, e.g. 09:30:00")
+	to := fs.String("to", "", "end of the time window, e.g. 16:00:00")
+	metadataFile := fs.String("metadata", "",
+		"write a sidecar JSON file of feed metadata to the given path")
+	precision := fs.Int("precision", consolidator.DefaultPriceFormat.Precision,
+		"number of decimal places for prices")
+	scaled := fs.Bool("scaled", false,
+		"emit integer prices in 1/10000ths of a dollar, "+
+			"matching the wire format")
+	enrich := fs.Bool("enrich", false,
+		"append securityType and sector columns to long rows, "+
+			"fetched from the IEX reference data endpoints")
+	stats := fs.Bool("stats", false,
+		"report message-rate percentiles, the peak second, and the "+
+			"busiest symbols and minutes to stderr")
+	loadUniverse := universeFlag(fs)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	var rates *collector.RateStats
+	if *stats {
+		rates = collector.NewRateStats()
+	}
+
+	universe, err := loadUniverse()
+	if err != nil {
+		return err
+	}
+
+	extras, err := parseBarColumns(*columns)
+	if err != nil {
+		return err
+	}
+	if len(extras) > 0 && *matrixField != "" {
+		return fmt.Errorf("-columns only applies to long rows, not -matrix output")
+	}
+
+	var enricher *iex.Enricher
+	if *enrich {
+		if *matrixField != "" {
+			return fmt.Errorf("-enrich only applies to long rows, not -matrix output")
+		}
+
+		enricher = iex.NewEnricher(iex.NewClient(http.DefaultClient))
+	}
+
+	priceFormat := consolidator.PriceFormat{
+		Precision: *precision,
+		Scaled:    *scaled,
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var flushMatrix func() error
+	var emit func(bars []*consolidator.Bar) error
+	if *matrixField != "" {
+		field, err := consolidator.ParseMatrixField(*matrixField)
+		if err != nil {
+			return err
+		}
+
+		matrix := consolidator.NewMatrix(*interval)
+		matrix.SetPriceFormat(priceFormat)
+		emit = func(bars []*consolidator.Bar) error {
+			for _, bar := range bars {
+				matrix.Add(bar)
+			}
+
+			return nil
+		}
+		flushMatrix = func() error {
+			return matrix.WriteCSV(os.Stdout, field)
+		}
+	} else {
+		columns := append([]string{}, csvHeader...)
+		columns = append(columns, extras...)
+		if enricher != nil {
+			columns = append(columns, "securityType", "sector")
+		}
+
+		writer := csv.NewWriter(os.Stdout)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		defer writer.Flush()
+
+		formatter := consolidator.NewPriceFormatter(priceFormat)
+		emit = func(bars []*consolidator.Bar) error {
+			return writeBars(bars, writer, formatter, enricher, extras, *interval)
+		}
+	}
+
+	con := consolidator.NewConsolidator(*interval, emit)
+	var window timeWindow
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		if ts, ok := iex.MessageTimestamp(msg); ok {
+			if err := window.resolve(*from, *to, ts); err != nil {
+				return err
+			}
+			if window.after(ts) {
+				break
+			}
+			if window.before(ts) {
+				continue
+			}
+		}
+
+		if !inUniverse(universe, msg) {
+			continue
+		}
+
+		if rates != nil {
+			if ts, ok := iex.MessageTimestamp(msg); ok {
+				symbol, _ := iex.MessageSymbol(msg)
+				rates.Observe(ts, symbol)
+			}
+		}
+
+		if err := con.OnMessage(msg); err != nil {
+			return err
+		}
+	}
+
+	if err := con.Close(); err != nil {
+		return err
+	}
+
+	if flushMatrix != nil {
+		if err := flushMatrix(); err != nil {
+			return err
+		}
+	}
+
+	if *metadataFile != "" {
+		if err := writeMetadata(scanner.Metadata(), *metadataFile); err != nil {
+			return err
+		}
+	}
+
+	if rates != nil {
+		if err := rates.WriteReport(os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeBar(bar *consolidator.Bar, w *csv.Writer, format *consolidator.PriceFormatter,
+	enricher *iex.Enricher, extras []string, interval time.Duration) error {
+	row := []string{
+		bar.Symbol,
+		// Bar times are derived from the trades; truncate to the
+		// bar interval for interval-aligned output rows.
+		bar.OpenTime.Truncate(interval).Format(time.RFC3339),
+		format.Format(bar.Open),
+		format.Format(bar.High),
+		format.Format(bar.Low),
+		format.Format(bar.Close),
+		strconv.FormatInt(bar.Volume, 10),
+	}
+
+	for _, col := range extras {
+		switch col {
+		case "vwap":
+			row = append(row, format.Format(bar.VWAP()))
+		case "count":
+			row = append(row, strconv.FormatInt(bar.Trades, 10))
+		case "notional":
+			row = append(row, strconv.FormatFloat(bar.Notional, 'f', 2, 64))
+		}
+	}
+
+	if enricher != nil {
+		info, err := enricher.Info(bar.Symbol)
+		if err != nil {
+			return err
+		}
+
+		row = append(row, info.SecurityType, info.Sector)
+	}
+
+	return w.Write(row)
+}
+
+func writeBars(bars []*consolidator.Bar, w *csv.Writer, format *consolidator.PriceFormatter,
+	enricher *iex.Enricher, extras []string, interval time.Duration) error {
+	for _, bar := range bars {
+		if err := writeBar(bar, w, format, enricher, extras, interval); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseBarColumns parses the -columns flag into a validated list of
+// extra column names.
+func parseBarColumns(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var cols []string
+	for _, col := range strings.Split(value, ",") {
+		switch col = strings.TrimSpace(col); col {
+		case "vwap", "count", "notional":
+			cols = append(cols, col)
+		default:
+			return nil, fmt.Errorf("unknown bar column: %q", col)
+		}
+	}
+
+	return cols, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/collector"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/sinks"
+)
+
+// runToJSON decodes messages to NDJSON on stdout or to file sinks,
+// mirroring the standalone pcap2json tool.
+func runToJSON(args []string) error {
+	fs := flag.NewFlagSet("goiex tojson", flag.ExitOnError)
+	metadataFile := fs.String("metadata", "",
+		"write a sidecar JSON file of feed metadata to the given path")
+	envelope := fs.Bool("envelope", false,
+		"wrap each message in a schema-versioned envelope with its "+
+			"feed, message type, and capture time")
+	enrich := fs.Bool("enrich", false,
+		"set securityType and sector on each envelope, fetched from "+
+			"the IEX reference data endpoints (requires -envelope)")
+	shardDir := fs.String("shard-dir", "",
+		"write one <SYMBOL>.ndjson file per symbol to the given "+
+			"directory instead of an interleaved stream on stdout")
+	partition := fs.String("partition", "",
+		"partition output files by event time into intervals of the "+
+			"given size (hour, day, or a duration like 30m); combines "+
+			"with -shard-dir for per-symbol, per-interval files")
+	partitionDir := fs.String("partition-dir", "",
+		"write time-partitioned ticks-<start>.ndjson files to the "+
+			"given directory (requires -partition)")
+	stats := fs.Bool("stats", false,
+		"report message-rate percentiles, the peak second, and the "+
+			"busiest symbols and minutes to stderr")
+	loadUniverse := universeFlag(fs)
+	loadConfig := configFlag(fs)
+	fs.Parse(args)
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	var rates *collector.RateStats
+	if *stats {
+		rates = collector.NewRateStats()
+	}
+
+	universe, err := loadUniverse()
+	if err != nil {
+		return err
+	}
+
+	var enricher *iex.Enricher
+	if *enrich {
+		if !*envelope {
+			return fmt.Errorf("-enrich requires -envelope")
+		}
+
+		enricher = iex.NewEnricher(iex.NewClient(http.DefaultClient))
+	}
+
+	var partitionInterval time.Duration
+	if *partition != "" {
+		if partitionInterval, err = sinks.ParsePartition(*partition); err != nil {
+			return err
+		}
+	}
+
+	// Sinks, when configured, replace the interleaved stdout stream;
+	// multiple sinks are fanned out with independent queues.
+	var sink sinks.Sink
+	var configured []sinks.Sink
+	if *shardDir != "" || *partitionDir != "" {
+		if *enrich {
+			return fmt.Errorf("-enrich is not supported with file sinks")
+		}
+
+		codecName := "json"
+		if *envelope {
+			codecName = "json-envelope"
+		}
+		codec, err := sinks.LookupCodec(codecName)
+		if err != nil {
+			return err
+		}
+
+		if *shardDir != "" {
+			sharded := sinks.NewShardedFileSink(*shardDir, codec)
+			if partitionInterval > 0 {
+				sharded.SetPartition(partitionInterval)
+			}
+			configured = append(configured, sharded)
+		}
+		if *partitionDir != "" {
+			if partitionInterval == 0 {
+				return fmt.Errorf("-partition-dir requires -partition")
+			}
+			configured = append(configured, sinks.NewPartitionedFileSink(
+				*partitionDir, codec, partitionInterval))
+		}
+	}
+	switch len(configured) {
+	case 0:
+	case 1:
+		sink = configured[0]
+	default:
+		fanout := sinks.NewFanout()
+		for _, s := range configured {
+			fanout.Add(s)
+		}
+		sink = fanout
+	}
+
+	scanner, cleanup, err := newScanner(fs)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	output := bufio.NewWriter(os.Stdout)
+	defer output.Flush()
+	enc := json.NewEncoder(output)
+
+	for {
+		e, err := scanner.NextMessageEnvelope()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+
+		if msg, ok := e.Message.(*iextp.UnsupportedMessage); ok {
+			log.Printf("WARNING: Unsupported message type %v", byte(msg.MessageType))
+		}
+
+		if !inUniverse(universe, e.Message) {
+			continue
+		}
+
+		if rates != nil {
+			symbol, _ := iex.MessageSymbol(e.Message)
+			rates.Observe(e.Timestamp, symbol)
+		}
+
+		if sink != nil {
+			if err := sink.Write(e.Message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if *envelope {
+			exported, err := iex.NewExportedMessage(
+				e.Message, scanner.Metadata().Feed, e.Timestamp)
+			if err != nil {
+				// Unsupported messages cannot be exported in a
+				// typed envelope; skip them.
+				continue
+			}
+
+			if enricher != nil {
+				if symbol, ok := iex.MessageSymbol(e.Message); ok {
+					info, err := enricher.Info(symbol)
+					if err != nil {
+						return err
+					}
+
+					exported.SecurityType = info.SecurityType
+					exported.Sector = info.Sector
+				}
+			}
+
+			enc.Encode(exported)
+		} else {
+			enc.Encode(e.Message)
+		}
+	}
+
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			return err
+		}
+	}
+
+	if *metadataFile != "" {
+		if err := writeMetadata(scanner.Metadata(), *metadataFile); err != nil {
+			return err
+		}
+	}
+
+	if rates != nil {
+		if err := rates.WriteReport(os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// histwatch polls the HIST endpoint until files for a date are
+// published (T+1), then prints them as JSON or runs a command:
+//
+//	histwatch -date 20170417 -exec ./etl.sh
+//
+// The file links are appended to the command's arguments, replacing
+// hand-rolled cron + curl availability scripts.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+import "github.com/timpalpant/go-iex"
+
+func main() {
+	date := flag.String("date", "",
+		"date to watch for, as YYYYMMDD (default: today)")
+	interval := flag.Duration("interval", iex.DefaultHISTPollInterval,
+		"how often to poll for new files")
+	execCmd := flag.String("exec", "",
+		"command to run when files appear, with the file links "+
+			"appended as arguments (default: print the files as JSON)")
+	flag.Parse()
+
+	watchDate := time.Now()
+	if *date != "" {
+		var err error
+		watchDate, err = time.Parse("20060102", *date)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	client := iex.NewClient(&http.Client{
+		Timeout: 30 * time.Second,
+	})
+
+	watcher := iex.NewHISTWatcher(client)
+	watcher.SetInterval(*interval)
+
+	hist, err := watcher.WaitForHIST(watchDate)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *execCmd == "" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(hist); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	args := flag.Args()
+	for _, h := range hist {
+		args = append(args, h.Link)
+	}
+
+	cmd := exec.Command(*execCmd, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,171 @@
+// tickgrep extracts all messages for a symbol within a time window
+// from a HIST pcap dump and prints them as JSON:
+//
+//	tickgrep -symbol AAPL -from 15:59:00 -to 16:01:00 file.pcap.gz
+//
+// The pcap dump is read from the given file, or from stdin if no file
+// is given, and may be gzipped. Times are interpreted as UTC
+// wall-clock times on the date of the feed; full RFC3339 timestamps
+// are also accepted.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/pcapio"
+)
+
+func main() {
+	symbol := flag.String("symbol", "",
+		"symbol to extract (required unless -universe is given)")
+	universeFile := flag.String("universe", "",
+		"extract all symbols in the given universe spec file "+
+			"(plain text or JSON)")
+	from := flag.String("from", "", "start of the time window, e.g. 15:59:00")
+	to := flag.String("to", "", "end of the time window, e.g. 16:01:00")
+	flag.Parse()
+
+	if *symbol == "" && *universeFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var universe *iex.Universe
+	if *universeFile != "" {
+		var err error
+		if universe, err = iex.LoadUniverse(*universeFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	input := os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scanner := iex.NewPcapScanner(packetSource)
+	output := bufio.NewWriter(os.Stdout)
+	defer output.Flush()
+	enc := json.NewEncoder(output)
+
+	parsed := iex.ParseSymbol(*symbol)
+	var window timeWindow
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			log.Fatal(err)
+		}
+
+		ts, hasTime := iex.MessageTimestamp(msg)
+		if hasTime {
+			if err := window.resolve(*from, *to, ts); err != nil {
+				log.Fatal(err)
+			}
+
+			if window.after(ts) {
+				// Feed timestamps are (nearly) ordered, so once
+				// we are well past the window there is nothing
+				// left to find.
+				break
+			}
+
+			if window.before(ts) {
+				continue
+			}
+		}
+
+		msgSymbol, ok := iex.MessageSymbol(msg)
+		if !ok {
+			continue
+		}
+		if *symbol != "" && !parsed.Matches(msgSymbol) {
+			continue
+		}
+		if universe != nil && !universe.Contains(msgSymbol) {
+			continue
+		}
+
+		if err := enc.Encode(msg); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// timeWindow is a [from, to] window whose bounds may be given as bare
+// wall-clock times, resolved against the date of the first message.
+type timeWindow struct {
+	resolved bool
+	from, to time.Time
+}
+
+// How far past the end of the window to keep scanning, to tolerate
+// slightly out-of-order event timestamps.
+const windowSlack = time.Minute
+
+func (w *timeWindow) resolve(from, to string, ts time.Time) error {
+	if w.resolved {
+		return nil
+	}
+
+	var err error
+	if w.from, err = parseTime(from, ts); err != nil {
+		return err
+	}
+	if w.to, err = parseTime(to, ts); err != nil {
+		return err
+	}
+
+	w.resolved = true
+	return nil
+}
+
+func (w *timeWindow) before(ts time.Time) bool {
+	return !w.from.IsZero() && ts.Before(w.from)
+}
+
+func (w *timeWindow) after(ts time.Time) bool {
+	return !w.to.IsZero() && ts.After(w.to.Add(windowSlack))
+}
+
+// parseTime parses an RFC3339 timestamp or a bare wall-clock time on
+// the date of the given reference timestamp. An empty value means no
+// bound.
+func parseTime(value string, reference time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("15:04:05", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := reference.UTC().Date()
+	return time.Date(year, month, day,
+		t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+}
@@ -0,0 +1,143 @@
+// Package collector implements a concurrent-safe accumulator of
+// per-symbol feed statistics, suitable for summarizing a full day of
+// messages fed from multiple processing goroutines.
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// The number of independently locked shards. Symbols are assigned to
+// shards by hash, so concurrent updates for different symbols rarely
+// contend on the same lock.
+const numShards = 16
+
+// SymbolStats are the accumulated totals for a single symbol.
+type SymbolStats struct {
+	Symbol       string  `json:"symbol"`
+	Trades       int64   `json:"trades"`
+	Volume       int64   `json:"volume"`
+	Notional     float64 `json:"notional"`
+	QuoteUpdates int64   `json:"quoteUpdates"`
+	Halts        int64   `json:"halts"`
+}
+
+type shard struct {
+	mu    sync.Mutex
+	stats map[string]*SymbolStats
+}
+
+// Collector accumulates per-symbol statistics from a stream of
+// decoded messages. It is safe for concurrent use by multiple
+// goroutines.
+type Collector struct {
+	shards [numShards]shard
+}
+
+// NewCollector creates an empty Collector.
+func NewCollector() *Collector {
+	c := &Collector{}
+	for i := range c.shards {
+		c.shards[i].stats = make(map[string]*SymbolStats)
+	}
+
+	return c
+}
+
+// OnMessage folds the given message into the per-symbol totals.
+// Message types that do not carry a symbol are ignored.
+func (c *Collector) OnMessage(msg iextp.Message) {
+	switch m := msg.(type) {
+	case *tops.TradeReportMessage:
+		s := &c.shards[shardFor(m.Symbol)]
+		s.mu.Lock()
+		stats := s.get(m.Symbol)
+		stats.Trades++
+		stats.Volume += int64(m.Size)
+		stats.Notional += float64(m.Size) * m.Price
+		s.mu.Unlock()
+	case *tops.QuoteUpdateMessage:
+		s := &c.shards[shardFor(m.Symbol)]
+		s.mu.Lock()
+		s.get(m.Symbol).QuoteUpdates++
+		s.mu.Unlock()
+	case *tops.TradingStatusMessage:
+		if m.TradingStatus != tops.TradingHalt {
+			return
+		}
+
+		s := &c.shards[shardFor(m.Symbol)]
+		s.mu.Lock()
+		s.get(m.Symbol).Halts++
+		s.mu.Unlock()
+	}
+}
+
+// Snapshot returns the accumulated statistics for all symbols,
+// sorted by symbol.
+func (c *Collector) Snapshot() []SymbolStats {
+	var result []SymbolStats
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for _, stats := range s.stats {
+			result = append(result, *stats)
+		}
+		s.mu.Unlock()
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Symbol < result[j].Symbol
+	})
+
+	return result
+}
+
+// WriteJSON writes the accumulated statistics to w as a JSON array
+// sorted by symbol.
+func (c *Collector) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Snapshot())
+}
+
+// WriteTable writes the accumulated statistics to w as an aligned
+// summary table sorted by symbol.
+func (c *Collector) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYMBOL\tTRADES\tVOLUME\tNOTIONAL\tQUOTES\tHALTS")
+	for _, stats := range c.Snapshot() {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%.2f\t%v\t%v\n",
+			stats.Symbol, stats.Trades, stats.Volume,
+			stats.Notional, stats.QuoteUpdates, stats.Halts)
+	}
+
+	return tw.Flush()
+}
+
+// get returns the stats entry for symbol, creating it if necessary.
+// The shard lock must be held.
+func (s *shard) get(symbol string) *SymbolStats {
+	stats, ok := s.stats[symbol]
+	if !ok {
+		stats = &SymbolStats{Symbol: symbol}
+		s.stats[symbol] = stats
+	}
+
+	return stats
+}
+
+func shardFor(symbol string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return h.Sum32() % numShards
+}
@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	c.OnMessage(&tops.TradeReportMessage{
+		Symbol: "ZIEXT", Size: 100, Price: 99.05,
+	})
+	c.OnMessage(&tops.TradeReportMessage{
+		Symbol: "ZIEXT", Size: 50, Price: 99.10,
+	})
+	c.OnMessage(&tops.QuoteUpdateMessage{Symbol: "ZIEXT"})
+	c.OnMessage(&tops.TradingStatusMessage{
+		Symbol: "AAPL", TradingStatus: tops.TradingHalt,
+	})
+	// Non-halt status updates are not counted.
+	c.OnMessage(&tops.TradingStatusMessage{
+		Symbol: "AAPL", TradingStatus: tops.Trading,
+	})
+
+	snapshot := c.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("expected 2 symbols, got: %v", len(snapshot))
+	}
+
+	aapl, ziext := snapshot[0], snapshot[1]
+	if aapl.Symbol != "AAPL" || aapl.Halts != 1 || aapl.Trades != 0 {
+		t.Errorf("unexpected AAPL stats: %+v", aapl)
+	}
+
+	if ziext.Symbol != "ZIEXT" || ziext.Trades != 2 || ziext.Volume != 150 ||
+		ziext.QuoteUpdates != 1 {
+		t.Errorf("unexpected ZIEXT stats: %+v", ziext)
+	}
+
+	expectedNotional := 100*99.05 + 50*99.10
+	if ziext.Notional != expectedNotional {
+		t.Errorf("expected notional %v, got: %v",
+			expectedNotional, ziext.Notional)
+	}
+}
+
+func TestCollectorConcurrent(t *testing.T) {
+	c := NewCollector()
+	symbols := []string{"AAPL", "FB", "SPY", "ZIEXT"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				for _, symbol := range symbols {
+					c.OnMessage(&tops.TradeReportMessage{
+						Symbol: symbol, Size: 1, Price: 1.0,
+					})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, stats := range c.Snapshot() {
+		if stats.Trades != 8000 || stats.Volume != 8000 {
+			t.Errorf("unexpected stats for %v: %+v", stats.Symbol, stats)
+		}
+	}
+}
+
+func TestCollectorWriteTable(t *testing.T) {
+	c := NewCollector()
+	c.OnMessage(&tops.TradeReportMessage{
+		Symbol: "ZIEXT", Size: 100, Price: 99.05,
+	})
+
+	var buf bytes.Buffer
+	if err := c.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got:\n%s", buf.String())
+	}
+
+	if !strings.HasPrefix(lines[0], "SYMBOL") ||
+		!strings.HasPrefix(lines[1], "ZIEXT") {
+		t.Errorf("unexpected table output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SymbolCount is a message count attributed to one symbol.
+type SymbolCount struct {
+	Symbol string
+	Count  int64
+}
+
+// BucketCount is a message count attributed to one time bucket.
+type BucketCount struct {
+	Time  time.Time
+	Count int64
+}
+
+// RateStats accumulates message rates from a feed, bucketed by the
+// message timestamps, for capacity planning of systems consuming the
+// live feed. Unlike Collector it counts every message, not just
+// trades and quotes.
+type RateStats struct {
+	perSecond map[int64]int64
+	perMinute map[int64]int64
+	perSymbol map[string]int64
+	total     int64
+}
+
+// NewRateStats creates an empty rate accumulator.
+func NewRateStats() *RateStats {
+	return &RateStats{
+		perSecond: make(map[int64]int64),
+		perMinute: make(map[int64]int64),
+		perSymbol: make(map[string]int64),
+	}
+}
+
+// Observe counts one message with the given timestamp. symbol may be
+// empty for message types that do not carry one.
+func (r *RateStats) Observe(ts time.Time, symbol string) {
+	r.perSecond[ts.Unix()]++
+	r.perMinute[ts.Truncate(time.Minute).Unix()]++
+	if symbol != "" {
+		r.perSymbol[symbol]++
+	}
+	r.total++
+}
+
+// Total returns the number of messages observed.
+func (r *RateStats) Total() int64 {
+	return r.total
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) of
+// messages-per-second over the seconds observed, or zero if nothing
+// has been observed.
+func (r *RateStats) Percentile(p float64) int64 {
+	if len(r.perSecond) == 0 {
+		return 0
+	}
+
+	counts := make([]int64, 0, len(r.perSecond))
+	for _, count := range r.perSecond {
+		counts = append(counts, count)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i] < counts[j] })
+
+	i := int(p/100*float64(len(counts))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(counts) {
+		i = len(counts) - 1
+	}
+	return counts[i]
+}
+
+// Peak returns the busiest second observed and its message count.
+func (r *RateStats) Peak() BucketCount {
+	return maxBucket(r.perSecond)
+}
+
+// BusiestMinutes returns the n busiest minutes, descending by count.
+func (r *RateStats) BusiestMinutes(n int) []BucketCount {
+	buckets := make([]BucketCount, 0, len(r.perMinute))
+	for sec, count := range r.perMinute {
+		buckets = append(buckets, BucketCount{time.Unix(sec, 0).UTC(), count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Time.Before(buckets[j].Time)
+	})
+
+	if len(buckets) > n {
+		buckets = buckets[:n]
+	}
+	return buckets
+}
+
+// BusiestSymbols returns the n symbols with the most messages,
+// descending by count.
+func (r *RateStats) BusiestSymbols(n int) []SymbolCount {
+	symbols := make([]SymbolCount, 0, len(r.perSymbol))
+	for symbol, count := range r.perSymbol {
+		symbols = append(symbols, SymbolCount{symbol, count})
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Count != symbols[j].Count {
+			return symbols[i].Count > symbols[j].Count
+		}
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+
+	if len(symbols) > n {
+		symbols = symbols[:n]
+	}
+	return symbols
+}
+
+// WriteReport writes a human-readable rate report, as printed by the
+// command-line tools' -stats flag.
+func (r *RateStats) WriteReport(w io.Writer) error {
+	peak := r.Peak()
+	if _, err := fmt.Fprintf(w,
+		"messages: %v over %v seconds\n"+
+			"rate percentiles (msgs/sec): p50=%v p90=%v p99=%v max=%v\n"+
+			"peak second: %v (%v msgs)\n",
+		r.total, len(r.perSecond),
+		r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Percentile(100),
+		peak.Time.Format(time.RFC3339), peak.Count); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "busiest symbols:"); err != nil {
+		return err
+	}
+	for _, s := range r.BusiestSymbols(5) {
+		if _, err := fmt.Fprintf(w, "  %v: %v\n", s.Symbol, s.Count); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "busiest minutes:"); err != nil {
+		return err
+	}
+	for _, b := range r.BusiestMinutes(5) {
+		if _, err := fmt.Fprintf(w, "  %v: %v\n",
+			b.Time.Format("15:04"), b.Count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxBucket returns the bucket with the highest count, breaking ties
+// by earliest time.
+func maxBucket(buckets map[int64]int64) BucketCount {
+	var best BucketCount
+	for sec, count := range buckets {
+		ts := time.Unix(sec, 0).UTC()
+		if count > best.Count || (count == best.Count && ts.Before(best.Time)) {
+			best = BucketCount{ts, count}
+		}
+	}
+	return best
+}
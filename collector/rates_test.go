@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateStats(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	r := NewRateStats()
+
+	// 3 messages in the first second, 1 in the next, 2 a minute later.
+	r.Observe(ts, "ZIEXT")
+	r.Observe(ts.Add(100*time.Millisecond), "ZIEXT")
+	r.Observe(ts.Add(200*time.Millisecond), "SNAP")
+	r.Observe(ts.Add(time.Second), "SNAP")
+	r.Observe(ts.Add(time.Minute), "")
+	r.Observe(ts.Add(time.Minute+time.Second), "ZIEXT")
+
+	if r.Total() != 6 {
+		t.Errorf("unexpected total: %v", r.Total())
+	}
+
+	// Per-second counts are [3, 1, 1, 1].
+	if p := r.Percentile(100); p != 3 {
+		t.Errorf("unexpected p100: %v", p)
+	}
+	if p := r.Percentile(50); p != 1 {
+		t.Errorf("unexpected p50: %v", p)
+	}
+
+	peak := r.Peak()
+	if !peak.Time.Equal(ts) || peak.Count != 3 {
+		t.Errorf("unexpected peak: %+v", peak)
+	}
+
+	symbols := r.BusiestSymbols(2)
+	if len(symbols) != 2 || symbols[0].Symbol != "ZIEXT" || symbols[0].Count != 3 {
+		t.Errorf("unexpected busiest symbols: %+v", symbols)
+	}
+
+	minutes := r.BusiestMinutes(1)
+	if len(minutes) != 1 || !minutes[0].Time.Equal(ts.Truncate(time.Minute)) ||
+		minutes[0].Count != 4 {
+		t.Errorf("unexpected busiest minutes: %+v", minutes)
+	}
+}
+
+func TestRateStatsWriteReport(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	r := NewRateStats()
+	r.Observe(ts, "ZIEXT")
+	r.Observe(ts, "SNAP")
+
+	var buf bytes.Buffer
+	if err := r.WriteReport(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	report := buf.String()
+	for _, want := range []string{
+		"messages: 2 over 1 seconds",
+		"ZIEXT: 1",
+		"09:30: 2",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
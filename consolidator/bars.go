@@ -8,15 +8,46 @@ import (
 )
 
 // Bar represents trades aggregated over a time interval.
+//
+// OpenTime and CloseTime are derived from the trades themselves:
+// the timestamps of the first and last trade in the bar. Callers that
+// want interval-aligned times can truncate OpenTime to their bar
+// interval.
 type Bar struct {
-	Symbol    string
-	OpenTime  time.Time
-	CloseTime time.Time
-	Open      float64
-	High      float64
-	Low       float64
-	Close     float64
-	Volume    int64
+	Symbol    string    `json:"symbol"`
+	OpenTime  time.Time `json:"openTime"`
+	CloseTime time.Time `json:"closeTime"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    int64     `json:"volume"`
+	// The number of trades aggregated into the bar.
+	Trades int64 `json:"trades,omitempty"`
+	// The total dollar value traded: the sum of price * size.
+	Notional float64 `json:"notional,omitempty"`
+	// Optional symbol-level metadata from ref-data, filled in by
+	// AnnotateSecurityTypes. See iex.Company.IssueType for values.
+	SecurityType string `json:"securityType,omitempty"`
+}
+
+// VWAP returns the volume-weighted average price of the bar's trades,
+// or zero for a bar with no volume.
+func (b *Bar) VWAP() float64 {
+	if b.Volume == 0 {
+		return 0
+	}
+
+	return b.Notional / float64(b.Volume)
+}
+
+// AnnotateSecurityTypes fills in the SecurityType of each bar from the
+// given map of symbol -> issue type, as obtained from the ref-data
+// endpoints (see iex.Client.GetCompany).
+func AnnotateSecurityTypes(bars []*Bar, issueTypes map[string]string) {
+	for _, bar := range bars {
+		bar.SecurityType = issueTypes[bar.Symbol]
+	}
 }
 
 // Construct a Bar for each distinct symbol in the given list
@@ -78,4 +109,6 @@ func updateBar(bar *Bar, trade *tops.TradeReportMessage) {
 	bar.CloseTime = trade.Timestamp
 	bar.Close = price
 	bar.Volume += int64(trade.Size)
+	bar.Trades++
+	bar.Notional += price * float64(trade.Size)
 }
@@ -0,0 +1,91 @@
+package consolidator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func testTrade(symbol string, ts time.Time, price float64, size uint32) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		MessageType: tops.TradeReport,
+		Timestamp:   ts,
+		Symbol:      symbol,
+		Price:       price,
+		Size:        size,
+	}
+}
+
+func TestMakeBar(t *testing.T) {
+	start := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trades := []*tops.TradeReportMessage{
+		testTrade("AAPL", start.Add(30*time.Second), 141.30, 50),
+		testTrade("AAPL", start.Add(5*time.Second), 141.35, 100),
+		testTrade("AAPL", start.Add(45*time.Second), 141.28, 200),
+	}
+
+	bar := MakeBar(trades)
+	if bar.Symbol != "AAPL" {
+		t.Errorf("expected symbol AAPL, got: %v", bar.Symbol)
+	}
+	if !bar.OpenTime.Equal(start.Add(5 * time.Second)) {
+		t.Errorf("expected open time of first trade, got: %v", bar.OpenTime)
+	}
+	if !bar.CloseTime.Equal(start.Add(45 * time.Second)) {
+		t.Errorf("expected close time of last trade, got: %v", bar.CloseTime)
+	}
+	if bar.Open != 141.35 || bar.Close != 141.28 {
+		t.Errorf("unexpected open/close: %v/%v", bar.Open, bar.Close)
+	}
+	if bar.High != 141.35 || bar.Low != 141.28 {
+		t.Errorf("unexpected high/low: %v/%v", bar.High, bar.Low)
+	}
+	if bar.Volume != 350 {
+		t.Errorf("expected volume 350, got: %v", bar.Volume)
+	}
+}
+
+func TestAnnotateSecurityTypes(t *testing.T) {
+	bars := []*Bar{
+		{Symbol: "AAPL"},
+		{Symbol: "SPY"},
+		{Symbol: "ZVZZT"},
+	}
+
+	AnnotateSecurityTypes(bars, map[string]string{
+		"AAPL": "cs",
+		"SPY":  "et",
+	})
+
+	if bars[0].SecurityType != "cs" {
+		t.Errorf("expected cs, got: %v", bars[0].SecurityType)
+	}
+	if bars[1].SecurityType != "et" {
+		t.Errorf("expected et, got: %v", bars[1].SecurityType)
+	}
+	if bars[2].SecurityType != "" {
+		t.Errorf("expected empty security type, got: %v", bars[2].SecurityType)
+	}
+}
+
+func TestBarVWAP(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	bar := MakeBar([]*tops.TradeReportMessage{
+		testTrade("SNAP", ts, 20.00, 100),
+		testTrade("SNAP", ts.Add(time.Second), 21.00, 300),
+	})
+
+	if bar.Trades != 2 {
+		t.Errorf("unexpected trade count: %v", bar.Trades)
+	}
+	if bar.Notional != 20.00*100+21.00*300 {
+		t.Errorf("unexpected notional: %v", bar.Notional)
+	}
+	if vwap := bar.VWAP(); vwap != 20.75 {
+		t.Errorf("unexpected VWAP: %v", vwap)
+	}
+	if vwap := (&Bar{}).VWAP(); vwap != 0 {
+		t.Errorf("unexpected empty-bar VWAP: %v", vwap)
+	}
+}
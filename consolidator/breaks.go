@@ -0,0 +1,92 @@
+package consolidator
+
+import (
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// BreakTracker links TradeBreakMessages to the earlier
+// TradeReportMessages they void, by TradeID. Feed it the full trade
+// stream and it can emit a corrected stream with broken trades
+// removed.
+type BreakTracker struct {
+	// Trades seen so far, in arrival order.
+	trades []*tops.TradeReportMessage
+	// TradeIDs voided by a trade break.
+	broken map[int64]bool
+	// Breaks whose original trade has not (yet) been seen.
+	unmatched []*tops.TradeBreakMessage
+}
+
+// NewBreakTracker creates an empty tracker.
+func NewBreakTracker() *BreakTracker {
+	return &BreakTracker{
+		broken: make(map[int64]bool),
+	}
+}
+
+// OnTrade records a trade report.
+func (t *BreakTracker) OnTrade(msg *tops.TradeReportMessage) {
+	t.trades = append(t.trades, msg)
+}
+
+// OnTradeBreak records a trade break, returning the original trade it
+// voids, or ok=false if no trade with that TradeID has been seen.
+func (t *BreakTracker) OnTradeBreak(msg *tops.TradeBreakMessage) (original *tops.TradeReportMessage, ok bool) {
+	t.broken[msg.TradeID] = true
+	for _, trade := range t.trades {
+		if trade.TradeID == msg.TradeID {
+			return trade, true
+		}
+	}
+
+	t.unmatched = append(t.unmatched, msg)
+	return nil, false
+}
+
+// IsBroken returns true if a break has been recorded for the given
+// TradeID.
+func (t *BreakTracker) IsBroken(tradeID int64) bool {
+	return t.broken[tradeID]
+}
+
+// CorrectedTrades returns the trades seen so far in arrival order,
+// with broken trades removed.
+func (t *BreakTracker) CorrectedTrades() []*tops.TradeReportMessage {
+	corrected := make([]*tops.TradeReportMessage, 0, len(t.trades))
+	for _, trade := range t.trades {
+		if !t.broken[trade.TradeID] {
+			corrected = append(corrected, trade)
+		}
+	}
+
+	return corrected
+}
+
+// UnmatchedBreaks returns the breaks whose original trade was never
+// seen, e.g. because the break referred to a previous session.
+func (t *BreakTracker) UnmatchedBreaks() []*tops.TradeBreakMessage {
+	return t.unmatched
+}
+
+// MakeCorrectedBars builds bars from the given trades with any broken
+// trades removed, so bars affected by trade breaks are rebuilt from
+// only the trades that still stand.
+func MakeCorrectedBars(trades []*tops.TradeReportMessage, breaks []*tops.TradeBreakMessage) []*Bar {
+	tracker := NewBreakTracker()
+	for _, trade := range trades {
+		tracker.OnTrade(trade)
+	}
+	for _, brk := range breaks {
+		tracker.OnTradeBreak(brk)
+	}
+
+	return MakeBars(tracker.CorrectedTrades())
+}
+
+// Reset discards all recorded trades and breaks, for use at session
+// rollovers when trade IDs start over.
+func (t *BreakTracker) Reset() {
+	t.trades = nil
+	t.broken = make(map[int64]bool)
+	t.unmatched = nil
+}
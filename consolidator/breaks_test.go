@@ -0,0 +1,67 @@
+package consolidator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestBreakTracker(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trade1 := &tops.TradeReportMessage{
+		Symbol: "ZIEXT", Price: 99.05, Size: 100, TradeID: 1, Timestamp: ts,
+	}
+	trade2 := &tops.TradeReportMessage{
+		Symbol: "ZIEXT", Price: 99.10, Size: 50, TradeID: 2,
+		Timestamp: ts.Add(time.Second),
+	}
+
+	tracker := NewBreakTracker()
+	tracker.OnTrade(trade1)
+	tracker.OnTrade(trade2)
+
+	original, ok := tracker.OnTradeBreak(&tops.TradeBreakMessage{TradeID: 1})
+	if !ok || original != trade1 {
+		t.Fatalf("expected break to match trade1, got: %+v (ok=%v)", original, ok)
+	}
+
+	if !tracker.IsBroken(1) || tracker.IsBroken(2) {
+		t.Error("unexpected broken state")
+	}
+
+	corrected := tracker.CorrectedTrades()
+	if len(corrected) != 1 || corrected[0] != trade2 {
+		t.Errorf("unexpected corrected trades: %+v", corrected)
+	}
+
+	// A break for a trade we never saw is unmatched.
+	if _, ok := tracker.OnTradeBreak(&tops.TradeBreakMessage{TradeID: 99}); ok {
+		t.Error("expected unmatched break")
+	}
+	if len(tracker.UnmatchedBreaks()) != 1 {
+		t.Errorf("unexpected unmatched breaks: %+v", tracker.UnmatchedBreaks())
+	}
+}
+
+func TestMakeCorrectedBars(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trades := []*tops.TradeReportMessage{
+		{Symbol: "ZIEXT", Price: 99.05, Size: 100, TradeID: 1, Timestamp: ts},
+		{Symbol: "ZIEXT", Price: 150.00, Size: 10, TradeID: 2,
+			Timestamp: ts.Add(time.Second)},
+		{Symbol: "ZIEXT", Price: 99.10, Size: 50, TradeID: 3,
+			Timestamp: ts.Add(2 * time.Second)},
+	}
+	breaks := []*tops.TradeBreakMessage{{TradeID: 2}}
+
+	bars := MakeCorrectedBars(trades, breaks)
+	if len(bars) != 1 {
+		t.Fatalf("expected 1 bar, got: %v", len(bars))
+	}
+
+	bar := bars[0]
+	if bar.High != 99.10 || bar.Volume != 150 {
+		t.Errorf("expected broken trade excluded from bar, got: %+v", bar)
+	}
+}
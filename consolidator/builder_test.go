@@ -0,0 +1,159 @@
+package consolidator
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func builderTrade(symbol string, ts time.Time, price float64, size uint32) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		Symbol:    symbol,
+		Timestamp: ts,
+		Price:     price,
+		Size:      size,
+	}
+}
+
+func TestBarBuilder(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	b := NewBarBuilder(time.Minute)
+
+	if completed := b.Add(builderTrade("SNAP", t0, 22.50, 100)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+	if completed := b.Add(builderTrade("FB", t0.Add(10*time.Second), 140.0, 200)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+	b.Add(builderTrade("SNAP", t0.Add(30*time.Second), 22.60, 50))
+
+	// The first trade of the next minute completes the interval.
+	completed := b.Add(builderTrade("SNAP", t0.Add(90*time.Second), 22.70, 25))
+	if len(completed) != 2 {
+		t.Fatalf("expected 2 completed bars, got: %v", len(completed))
+	}
+	if completed[0].Symbol != "FB" || completed[1].Symbol != "SNAP" {
+		t.Errorf("expected bars sorted by symbol: %v, %v",
+			completed[0].Symbol, completed[1].Symbol)
+	}
+	snap := completed[1]
+	if snap.Open != 22.50 || snap.Close != 22.60 || snap.Volume != 150 {
+		t.Errorf("unexpected SNAP bar: %+v", snap)
+	}
+
+	final := b.Flush()
+	if len(final) != 1 || final[0].Symbol != "SNAP" || final[0].Volume != 25 {
+		t.Errorf("unexpected final bars: %+v", final)
+	}
+	if again := b.Flush(); again != nil {
+		t.Errorf("expected empty flush, got: %v", again)
+	}
+}
+
+// A job checkpointed mid-interval and resumed from the serialized
+// state must produce the same bars as an uninterrupted run.
+func TestBarBuilder_CheckpointResume(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trades := []*tops.TradeReportMessage{
+		builderTrade("SNAP", t0, 22.50, 100),
+		builderTrade("SNAP", t0.Add(15*time.Second), 22.40, 50),
+		builderTrade("FB", t0.Add(20*time.Second), 140.0, 200),
+		builderTrade("SNAP", t0.Add(45*time.Second), 22.65, 75),
+		builderTrade("FB", t0.Add(70*time.Second), 141.0, 100),
+	}
+
+	uninterrupted := NewBarBuilder(time.Minute)
+	var wantBars []*Bar
+	for _, trade := range trades {
+		wantBars = append(wantBars, uninterrupted.Add(trade)...)
+	}
+	wantBars = append(wantBars, uninterrupted.Flush()...)
+
+	// Checkpoint after the third trade, round-tripping through JSON.
+	resumable := NewBarBuilder(time.Minute)
+	for _, trade := range trades[:3] {
+		resumable.Add(trade)
+	}
+
+	checkpoint := &Checkpoint{
+		SessionID:      1116143616,
+		SequenceNumber: 3,
+		Bars:           resumable.State(),
+	}
+	var buf bytes.Buffer
+	if err := checkpoint.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := ReadCheckpoint(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.SessionID != checkpoint.SessionID ||
+		restored.SequenceNumber != checkpoint.SequenceNumber {
+		t.Errorf("unexpected checkpoint: %+v", restored)
+	}
+
+	resumed := RestoreBarBuilder(restored.Bars)
+	var gotBars []*Bar
+	for _, trade := range trades[3:] {
+		gotBars = append(gotBars, resumed.Add(trade)...)
+	}
+	gotBars = append(gotBars, resumed.Flush()...)
+
+	if !reflect.DeepEqual(gotBars, wantBars) {
+		t.Errorf("resumed bars differ from uninterrupted run:\ngot:  %v\nwant: %v",
+			gotBars, wantBars)
+	}
+}
+
+func TestBarBuilderTimeBase(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	// The trade's event time is just inside the first minute, but its
+	// capture time is in the next one: the chosen base decides which
+	// interval it lands in.
+	straddler := &iex.MessageEnvelope{
+		Message:     builderTrade("SNAP", t0.Add(59*time.Second), 20.05, 100),
+		Timestamp:   t0.Add(59 * time.Second),
+		CaptureTime: t0.Add(61 * time.Second),
+	}
+	later := &iex.MessageEnvelope{
+		Message:     builderTrade("SNAP", t0.Add(90*time.Second), 20.10, 100),
+		Timestamp:   t0.Add(90 * time.Second),
+		CaptureTime: t0.Add(90 * time.Second),
+	}
+
+	byEvent := NewBarBuilder(time.Minute)
+	if completed := byEvent.AddEnvelope(straddler); completed != nil {
+		t.Fatalf("unexpected completed bars: %v", completed)
+	}
+	if completed := byEvent.AddEnvelope(later); len(completed) != 1 {
+		t.Errorf("expected the event-time interval to close, got: %v", completed)
+	}
+
+	byCapture := NewBarBuilder(time.Minute)
+	byCapture.SetTimeBase(iex.TimeBaseCaptureTime)
+	byCapture.AddEnvelope(straddler)
+	if completed := byCapture.AddEnvelope(later); completed != nil {
+		t.Errorf("expected both trades in one capture-time interval, got: %v", completed)
+	}
+	bars := byCapture.Flush()
+	if len(bars) != 1 || bars[0].Volume != 200 {
+		t.Errorf("unexpected capture-time bars: %+v", bars)
+	}
+	if !bars[0].OpenTime.Equal(straddler.CaptureTime) {
+		t.Errorf("unexpected open time: %v", bars[0].OpenTime)
+	}
+
+	// Non-trade messages are ignored.
+	if completed := byEvent.AddEnvelope(&iex.MessageEnvelope{
+		Message: &tops.SystemEventMessage{},
+	}); completed != nil {
+		t.Errorf("unexpected bars from a non-trade: %v", completed)
+	}
+}
@@ -0,0 +1,55 @@
+package consolidator
+
+import (
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Consolidator streams messages into bars of a fixed interval,
+// delivering each interval's completed bars to a callback as the
+// interval closes. It wraps a BarBuilder behind the OnMessage shape
+// used throughout this repository, so replay loops can feed it every
+// message without bucketing trades themselves. Any interval works:
+// time.Second, 5*time.Minute, time.Hour, 24*time.Hour for daily bars.
+type Consolidator struct {
+	builder *BarBuilder
+	onBars  func(bars []*Bar) error
+}
+
+// NewConsolidator creates a consolidator emitting bars of the given
+// interval to the given callback, sorted by symbol.
+func NewConsolidator(interval time.Duration, onBars func(bars []*Bar) error) *Consolidator {
+	return &Consolidator{
+		builder: NewBarBuilder(interval),
+		onBars:  onBars,
+	}
+}
+
+// OnMessage folds the message into the current interval's bars.
+// Messages other than trade reports are ignored. When the message
+// starts a new interval, the completed bars of the previous interval
+// are delivered to the callback; its error aborts the stream.
+func (c *Consolidator) OnMessage(msg iextp.Message) error {
+	trade, ok := msg.(*tops.TradeReportMessage)
+	if !ok {
+		return nil
+	}
+
+	if completed := c.builder.Add(trade); len(completed) > 0 {
+		return c.onBars(completed)
+	}
+
+	return nil
+}
+
+// Close delivers the final partial interval to the callback. It
+// should be called once after the last message.
+func (c *Consolidator) Close() error {
+	if bars := c.builder.Flush(); len(bars) > 0 {
+		return c.onBars(bars)
+	}
+
+	return nil
+}
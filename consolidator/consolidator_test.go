@@ -0,0 +1,84 @@
+package consolidator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestConsolidator(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	var emitted [][]*Bar
+	c := NewConsolidator(time.Minute, func(bars []*Bar) error {
+		emitted = append(emitted, bars)
+		return nil
+	})
+
+	// Two intervals of trades, interleaved with a non-trade message.
+	trades := []struct {
+		symbol string
+		offset time.Duration
+		price  float64
+	}{
+		{"SNAP", 0, 20.05},
+		{"FB", 10 * time.Second, 140.10},
+		{"SNAP", 30 * time.Second, 20.10},
+		{"SNAP", 90 * time.Second, 20.15},
+	}
+	for _, trade := range trades {
+		msg := testTrade(trade.symbol, t0.Add(trade.offset), trade.price, 100)
+		if err := c.OnMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.OnMessage(&tops.SystemEventMessage{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(emitted) != 2 {
+		t.Fatalf("expected 2 intervals, got: %v", len(emitted))
+	}
+
+	first := symbolsOf(emitted[0])
+	if !reflect.DeepEqual(first, []string{"FB", "SNAP"}) {
+		t.Errorf("unexpected first interval: %v", first)
+	}
+	second := symbolsOf(emitted[1])
+	if !reflect.DeepEqual(second, []string{"SNAP"}) {
+		t.Errorf("unexpected second interval: %v", second)
+	}
+	if close := emitted[1][0].Close; close != 20.15 {
+		t.Errorf("unexpected second interval close: %v", close)
+	}
+}
+
+func TestConsolidator_CallbackError(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	boom := errors.New("sink full")
+
+	c := NewConsolidator(time.Minute, func(bars []*Bar) error {
+		return boom
+	})
+
+	if err := c.OnMessage(testTrade("SNAP", t0, 20.05, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != boom {
+		t.Errorf("expected the callback error, got: %v", err)
+	}
+}
+
+func symbolsOf(bars []*Bar) []string {
+	symbols := make([]string, len(bars))
+	for i, bar := range bars {
+		symbols[i] = bar.Symbol
+	}
+	return symbols
+}
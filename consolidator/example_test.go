@@ -0,0 +1,41 @@
+package consolidator_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Aggregate a stream of trades into minute bars, one interval at a
+// time. In a real pipeline the trades come from a PcapScanner or the
+// streaming client.
+func ExampleBarBuilder() {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trades := []*tops.TradeReportMessage{
+		{Symbol: "SNAP", Price: 20.10, Size: 100, Timestamp: t0},
+		{Symbol: "SNAP", Price: 20.25, Size: 200, Timestamp: t0.Add(30 * time.Second)},
+		// This trade starts the next minute, completing the first bar.
+		{Symbol: "SNAP", Price: 20.05, Size: 100, Timestamp: t0.Add(90 * time.Second)},
+	}
+
+	printBars := func(bars []*consolidator.Bar) {
+		for _, bar := range bars {
+			fmt.Printf("%v %v O=%.2f H=%.2f L=%.2f C=%.2f V=%v\n",
+				bar.Symbol, bar.OpenTime.Format("15:04:05"),
+				bar.Open, bar.High, bar.Low, bar.Close, bar.Volume)
+		}
+	}
+
+	builder := consolidator.NewBarBuilder(time.Minute)
+	for _, trade := range trades {
+		printBars(builder.Add(trade))
+	}
+	// Flush once more to emit the final partial interval.
+	printBars(builder.Flush())
+
+	// Output:
+	// SNAP 09:30:00 O=20.10 H=20.25 L=20.10 C=20.25 V=300
+	// SNAP 09:31:30 O=20.05 H=20.05 L=20.05 C=20.05 V=100
+}
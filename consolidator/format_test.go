@@ -0,0 +1,80 @@
+package consolidator
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPriceFormat(t *testing.T) {
+	cases := []struct {
+		format   PriceFormat
+		price    float64
+		expected string
+	}{
+		{DefaultPriceFormat, 99.05, "99.0500"},
+		{PriceFormat{Precision: 2}, 99.056, "99.06"},
+		{PriceFormat{Scaled: true}, 99.05, "990500"},
+		{PriceFormat{Scaled: true}, 0.0001, "1"},
+	}
+
+	for _, tc := range cases {
+		if got := tc.format.Format(tc.price); got != tc.expected {
+			t.Errorf("Format(%v) with %+v = %v, expected %v",
+				tc.price, tc.format, got, tc.expected)
+		}
+	}
+}
+
+func TestPriceFormat_MatchesStrconv(t *testing.T) {
+	// The fixed-point fast path must render exactly what strconv
+	// would, on- and off-grid, positive and negative.
+	prices := []float64{
+		0, 0.0001, -0.0001, 20.05, -20.05, 99.9999, 100,
+		1234.5678, 1e6, 715.00009, -3.14159,
+		float64(200500) / 10000,
+	}
+
+	for _, price := range prices {
+		expected := strconv.FormatFloat(price, 'f', 4, 64)
+		if got := DefaultPriceFormat.Format(price); got != expected {
+			t.Errorf("Format(%v) = %v, expected %v", price, got, expected)
+		}
+	}
+}
+
+func TestPriceFormatter(t *testing.T) {
+	f := NewPriceFormatter(DefaultPriceFormat)
+	for i := 0; i < 3; i++ {
+		if got := f.Format(20.05); got != "20.0500" {
+			t.Errorf("Format(20.05) = %v, expected 20.0500", got)
+		}
+	}
+	if got := f.Format(-1.5); got != "-1.5000" {
+		t.Errorf("Format(-1.5) = %v, expected -1.5000", got)
+	}
+	if len(f.cache) != 2 {
+		t.Errorf("expected 2 cached prices, got: %v", len(f.cache))
+	}
+}
+
+func BenchmarkPriceFormat(b *testing.B) {
+	price := float64(200500) / 10000
+	for i := 0; i < b.N; i++ {
+		DefaultPriceFormat.Format(price)
+	}
+}
+
+func BenchmarkPriceFormat_Strconv(b *testing.B) {
+	price := float64(200500) / 10000
+	for i := 0; i < b.N; i++ {
+		strconv.FormatFloat(price, 'f', 4, 64)
+	}
+}
+
+func BenchmarkPriceFormatter(b *testing.B) {
+	f := NewPriceFormatter(DefaultPriceFormat)
+	price := float64(200500) / 10000
+	for i := 0; i < b.N; i++ {
+		f.Format(price)
+	}
+}
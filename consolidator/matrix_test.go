@@ -0,0 +1,53 @@
+package consolidator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMatrixWriteCSV(t *testing.T) {
+	start := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	matrix := NewMatrix(time.Minute)
+	matrix.Add(&Bar{Symbol: "AAPL", OpenTime: start.Add(5 * time.Second), Close: 141.35, Volume: 100})
+	matrix.Add(&Bar{Symbol: "SPY", OpenTime: start.Add(10 * time.Second), Close: 234.56, Volume: 200})
+	matrix.Add(&Bar{Symbol: "AAPL", OpenTime: start.Add(70 * time.Second), Close: 141.40, Volume: 300})
+
+	var buf bytes.Buffer
+	if err := matrix.WriteCSV(&buf, MatrixClose); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "time,AAPL,SPY\n" +
+		"2017-04-17T09:30:00Z,141.3500,234.5600\n" +
+		"2017-04-17T09:31:00Z,141.4000,\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected close matrix output:\n%v", buf.String())
+	}
+
+	buf.Reset()
+	if err := matrix.WriteCSV(&buf, MatrixVolume); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "time,AAPL,SPY\n" +
+		"2017-04-17T09:30:00Z,100,200\n" +
+		"2017-04-17T09:31:00Z,300,\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected volume matrix output:\n%v", buf.String())
+	}
+}
+
+func TestParseMatrixField(t *testing.T) {
+	if _, err := ParseMatrixField("open"); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+
+	field, err := ParseMatrixField("volume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field != MatrixVolume {
+		t.Fatalf("expected MatrixVolume, got: %v", field)
+	}
+}
@@ -0,0 +1,87 @@
+package consolidator
+
+import (
+	"sort"
+	"time"
+)
+
+// Period selects a calendar-aligned roll-up boundary.
+type Period int
+
+const (
+	// Weekly groups bars by trading week, Monday through Sunday.
+	Weekly Period = iota
+	// Monthly groups bars by calendar month.
+	Monthly
+)
+
+// RollUp aggregates finer-grained bars (minute or daily) into
+// calendar-aligned weekly or monthly bars, one per symbol per period,
+// sorted by open time and then symbol. Boundaries are computed on the
+// bars' own timestamps, so sessions shortened by holidays or early
+// closes simply contribute fewer bars to their period; no separate
+// calendar data is needed.
+func RollUp(bars []*Bar, period Period) []*Bar {
+	ordered := make([]*Bar, len(bars))
+	copy(ordered, bars)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].OpenTime.Before(ordered[j].OpenTime)
+	})
+
+	type key struct {
+		symbol string
+		start  time.Time
+	}
+	merged := make(map[key]*Bar)
+	var result []*Bar
+	for _, bar := range ordered {
+		k := key{bar.Symbol, periodStart(bar.OpenTime, period)}
+		rollup, ok := merged[k]
+		if !ok {
+			copied := *bar
+			merged[k] = &copied
+			result = append(result, &copied)
+			continue
+		}
+
+		mergeBar(rollup, bar)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].OpenTime.Equal(result[j].OpenTime) {
+			return result[i].OpenTime.Before(result[j].OpenTime)
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// periodStart returns the calendar boundary containing ts.
+func periodStart(ts time.Time, period Period) time.Time {
+	year, month, day := ts.Date()
+	if period == Monthly {
+		return time.Date(year, month, 1, 0, 0, 0, 0, ts.Location())
+	}
+
+	// Weeks start on Monday.
+	date := time.Date(year, month, day, 0, 0, 0, 0, ts.Location())
+	back := (int(date.Weekday()) - int(time.Monday) + 7) % 7
+	return date.AddDate(0, 0, -back)
+}
+
+// mergeBar folds src into dst, which opened earlier.
+func mergeBar(dst, src *Bar) {
+	if src.High > dst.High {
+		dst.High = src.High
+	}
+	if dst.Low == 0 || (src.Low != 0 && src.Low < dst.Low) {
+		dst.Low = src.Low
+	}
+	if src.CloseTime.After(dst.CloseTime) {
+		dst.CloseTime = src.CloseTime
+		dst.Close = src.Close
+	}
+	dst.Volume += src.Volume
+	dst.Trades += src.Trades
+	dst.Notional += src.Notional
+}
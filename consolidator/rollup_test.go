@@ -0,0 +1,84 @@
+package consolidator
+
+import (
+	"testing"
+	"time"
+)
+
+// dailyBar builds a daily bar for the given trading date.
+func dailyBar(symbol string, date time.Time, open, close float64, volume int64) *Bar {
+	high, low := open, close
+	if close > open {
+		high, low = close, open
+	}
+	return &Bar{
+		Symbol:    symbol,
+		OpenTime:  date.Add(13*time.Hour + 30*time.Minute),
+		CloseTime: date.Add(20 * time.Hour),
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Trades:    1,
+		Notional:  close * float64(volume),
+	}
+}
+
+func TestRollUpWeekly(t *testing.T) {
+	// 2017-04-14 was Good Friday: the first week has only four
+	// sessions, which simply contribute four bars.
+	monday := time.Date(2017, time.April, 10, 0, 0, 0, 0, time.UTC)
+	var bars []*Bar
+	for day := 0; day < 4; day++ {
+		date := monday.AddDate(0, 0, day)
+		bars = append(bars, dailyBar("SNAP", date, 20+float64(day), 21+float64(day), 100))
+	}
+	// Monday of the next week.
+	bars = append(bars, dailyBar("SNAP", monday.AddDate(0, 0, 7), 30, 31, 50))
+	bars = append(bars, dailyBar("FB", monday, 140, 141, 200))
+
+	weekly := RollUp(bars, Weekly)
+	if len(weekly) != 3 {
+		t.Fatalf("expected 3 weekly bars, got: %v", len(weekly))
+	}
+
+	if weekly[0].Symbol != "FB" || weekly[1].Symbol != "SNAP" {
+		t.Errorf("unexpected order: %v, %v", weekly[0].Symbol, weekly[1].Symbol)
+	}
+
+	snap := weekly[1]
+	if snap.Open != 20 || snap.Close != 24 || snap.High != 24 || snap.Low != 20 {
+		t.Errorf("unexpected first-week SNAP bar: %+v", snap)
+	}
+	if snap.Volume != 400 || snap.Trades != 4 {
+		t.Errorf("unexpected first-week SNAP totals: %+v", snap)
+	}
+
+	next := weekly[2]
+	if next.Open != 30 || next.Volume != 50 {
+		t.Errorf("unexpected second-week SNAP bar: %+v", next)
+	}
+}
+
+func TestRollUpMonthly(t *testing.T) {
+	bars := []*Bar{
+		dailyBar("SNAP", time.Date(2017, time.April, 28, 0, 0, 0, 0, time.UTC), 20, 21, 100),
+		dailyBar("SNAP", time.Date(2017, time.May, 1, 0, 0, 0, 0, time.UTC), 21, 22, 100),
+		dailyBar("SNAP", time.Date(2017, time.April, 3, 0, 0, 0, 0, time.UTC), 19, 20, 100),
+	}
+
+	monthly := RollUp(bars, Monthly)
+	if len(monthly) != 2 {
+		t.Fatalf("expected 2 monthly bars, got: %v", len(monthly))
+	}
+
+	april := monthly[0]
+	if april.Open != 19 || april.Close != 21 || april.Volume != 200 {
+		t.Errorf("unexpected April bar: %+v", april)
+	}
+	may := monthly[1]
+	if may.Open != 21 || may.Close != 22 || may.Volume != 100 {
+		t.Errorf("unexpected May bar: %+v", may)
+	}
+}
@@ -0,0 +1,104 @@
+package consolidator
+
+import (
+	"sort"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// SampleBy selects which measure of trading activity closes a sampled
+// bar. Unlike time bars, information-driven bars close when a fixed
+// amount of activity has accumulated, so they sample more often when
+// the market is busy.
+type SampleBy int
+
+const (
+	// SampleByTrades closes a bar after a fixed number of trades
+	// (tick bars).
+	SampleByTrades SampleBy = iota
+	// SampleByVolume closes a bar after a fixed number of shares
+	// (volume bars).
+	SampleByVolume
+	// SampleByNotional closes a bar after a fixed dollar value traded
+	// (dollar bars).
+	SampleByNotional
+)
+
+// measure returns the bar's accumulated activity under the sampling
+// measure.
+func (by SampleBy) measure(bar *Bar) float64 {
+	switch by {
+	case SampleByVolume:
+		return float64(bar.Volume)
+	case SampleByNotional:
+		return bar.Notional
+	default:
+		return float64(bar.Trades)
+	}
+}
+
+// SampledBarBuilder incrementally aggregates a stream of trades into
+// information-driven bars: each symbol's bar closes once a threshold
+// of activity (trades, shares, or dollars) has accumulated, rather
+// than at fixed time boundaries. Symbols sample independently, so
+// active names produce more bars than quiet ones.
+type SampledBarBuilder struct {
+	by        SampleBy
+	threshold float64
+	bars      map[string]*Bar
+}
+
+// NewSampledBarBuilder creates a SampledBarBuilder closing each bar
+// once the given measure reaches threshold, e.g.
+// NewSampledBarBuilder(SampleByVolume, 10000) for a bar every 10,000
+// shares.
+func NewSampledBarBuilder(by SampleBy, threshold float64) *SampledBarBuilder {
+	return &SampledBarBuilder{
+		by:        by,
+		threshold: threshold,
+		bars:      make(map[string]*Bar),
+	}
+}
+
+// Add folds the given trade into its symbol's in-progress bar. If the
+// trade brings the bar to the sampling threshold, the completed bar is
+// returned. A trade is never split: a large trade crossing the
+// threshold is included in full in the bar it closes.
+func (b *SampledBarBuilder) Add(trade *tops.TradeReportMessage) []*Bar {
+	bar, ok := b.bars[trade.Symbol]
+	if !ok {
+		bar = &Bar{
+			Symbol:   trade.Symbol,
+			OpenTime: trade.Timestamp,
+		}
+		b.bars[trade.Symbol] = bar
+	}
+
+	updateBar(bar, trade)
+	if b.by.measure(bar) >= b.threshold {
+		delete(b.bars, trade.Symbol)
+		return []*Bar{bar}
+	}
+
+	return nil
+}
+
+// Flush returns the in-progress bars of all symbols, sorted by symbol,
+// and resets the builder. It should be called once after the last
+// trade to emit the final partial bars.
+func (b *SampledBarBuilder) Flush() []*Bar {
+	if len(b.bars) == 0 {
+		return nil
+	}
+
+	bars := make([]*Bar, 0, len(b.bars))
+	for _, bar := range b.bars {
+		bars = append(bars, bar)
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Symbol < bars[j].Symbol
+	})
+
+	b.bars = make(map[string]*Bar)
+	return bars
+}
@@ -0,0 +1,87 @@
+package consolidator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampledBarBuilderTrades(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	b := NewSampledBarBuilder(SampleByTrades, 3)
+
+	if completed := b.Add(builderTrade("SNAP", t0, 22.50, 100)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+	if completed := b.Add(builderTrade("SNAP", t0.Add(time.Second), 22.60, 50)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+
+	// The third trade closes the tick bar.
+	completed := b.Add(builderTrade("SNAP", t0.Add(2*time.Second), 22.55, 75))
+	if len(completed) != 1 {
+		t.Fatalf("expected 1 completed bar, got: %v", len(completed))
+	}
+	bar := completed[0]
+	if bar.Open != 22.50 || bar.Close != 22.55 || bar.Trades != 3 || bar.Volume != 225 {
+		t.Errorf("unexpected bar: %+v", bar)
+	}
+	if !bar.OpenTime.Equal(t0) || !bar.CloseTime.Equal(t0.Add(2*time.Second)) {
+		t.Errorf("unexpected bar times: %v, %v", bar.OpenTime, bar.CloseTime)
+	}
+
+	// The next trade starts a fresh bar.
+	b.Add(builderTrade("SNAP", t0.Add(3*time.Second), 22.70, 25))
+	final := b.Flush()
+	if len(final) != 1 || final[0].Trades != 1 || final[0].Open != 22.70 {
+		t.Errorf("unexpected final bars: %+v", final)
+	}
+	if again := b.Flush(); again != nil {
+		t.Errorf("expected empty flush, got: %v", again)
+	}
+}
+
+func TestSampledBarBuilderVolume(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	b := NewSampledBarBuilder(SampleByVolume, 10000)
+
+	if completed := b.Add(builderTrade("FB", t0, 140.0, 6000)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+
+	// The crossing trade is included in full in the bar it closes.
+	completed := b.Add(builderTrade("FB", t0.Add(time.Second), 140.1, 7000))
+	if len(completed) != 1 {
+		t.Fatalf("expected 1 completed bar, got: %v", len(completed))
+	}
+	if completed[0].Volume != 13000 || completed[0].Trades != 2 {
+		t.Errorf("unexpected bar: %+v", completed[0])
+	}
+}
+
+func TestSampledBarBuilderNotional(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	b := NewSampledBarBuilder(SampleByNotional, 1000000)
+
+	// Symbols sample independently: SNAP's activity does not close
+	// FB's bar.
+	if completed := b.Add(builderTrade("SNAP", t0, 22.50, 100)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+	if completed := b.Add(builderTrade("FB", t0, 140.0, 5000)); completed != nil {
+		t.Errorf("unexpected completed bars: %v", completed)
+	}
+
+	// $700,000 + $420,000 crosses the $1M threshold for FB only.
+	completed := b.Add(builderTrade("FB", t0.Add(time.Second), 140.0, 3000))
+	if len(completed) != 1 || completed[0].Symbol != "FB" {
+		t.Fatalf("expected 1 completed FB bar, got: %+v", completed)
+	}
+	if completed[0].Notional != 1120000 {
+		t.Errorf("unexpected notional: %v", completed[0].Notional)
+	}
+
+	final := b.Flush()
+	if len(final) != 1 || final[0].Symbol != "SNAP" {
+		t.Errorf("unexpected final bars: %+v", final)
+	}
+}
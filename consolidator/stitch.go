@@ -0,0 +1,118 @@
+package consolidator
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+)
+
+// SymbolChange records a ticker rename effective on the given date.
+type SymbolChange struct {
+	Date time.Time `json:"date"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// DiffSymbols detects ticker renames between two days of the ref-data
+// symbol directory: a symbol removed from the directory whose company
+// name reappears under a new symbol. The changes are effective on the
+// given date (the date of the current directory).
+func DiffSymbols(previous, current []*iex.Symbol, date time.Time) []SymbolChange {
+	currentBySymbol := make(map[string]bool, len(current))
+	currentByName := make(map[string]string, len(current))
+	for _, s := range current {
+		currentBySymbol[s.Symbol] = true
+		currentByName[s.Name] = s.Symbol
+	}
+
+	previousBySymbol := make(map[string]bool, len(previous))
+	for _, s := range previous {
+		previousBySymbol[s.Symbol] = true
+	}
+
+	var changes []SymbolChange
+	for _, s := range previous {
+		if currentBySymbol[s.Symbol] {
+			continue
+		}
+
+		newSymbol, ok := currentByName[s.Name]
+		if !ok || previousBySymbol[newSymbol] {
+			// Delisted, or the "new" symbol already existed:
+			// not a rename.
+			continue
+		}
+
+		changes = append(changes, SymbolChange{
+			Date: date,
+			From: s.Symbol,
+			To:   newSymbol,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].From < changes[j].From
+	})
+
+	return changes
+}
+
+// SymbolMap maps every historical symbol of a listing to its final
+// symbol.
+type SymbolMap map[string]string
+
+// ResolveSymbolChanges folds a set of renames into a SymbolMap,
+// following chains of renames (A -> B -> C) to the final listing
+// symbol. The changes must be ordered by date.
+func ResolveSymbolChanges(changes []SymbolChange) SymbolMap {
+	m := make(SymbolMap, len(changes))
+	for _, change := range changes {
+		m[change.From] = change.To
+		// Redirect any earlier symbols that pointed at the renamed
+		// symbol.
+		for from, to := range m {
+			if to == change.From {
+				m[from] = change.To
+			}
+		}
+	}
+
+	return m
+}
+
+// WriteJSON writes the mapping to w as JSON, for use as a sidecar
+// mapping file alongside stitched datasets.
+func (m SymbolMap) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// StitchBars groups bars into continuous per-listing series across
+// ticker renames. Bars for historical symbols are relabeled with the
+// final symbol of the listing, and each series is sorted by OpenTime.
+func StitchBars(bars []*Bar, m SymbolMap) map[string][]*Bar {
+	series := make(map[string][]*Bar)
+	for _, bar := range bars {
+		symbol := bar.Symbol
+		if final, ok := m[symbol]; ok {
+			symbol = final
+			relabeled := *bar
+			relabeled.Symbol = final
+			bar = &relabeled
+		}
+
+		series[symbol] = append(series[symbol], bar)
+	}
+
+	for _, bars := range series {
+		sort.Slice(bars, func(i, j int) bool {
+			return bars[i].OpenTime.Before(bars[j].OpenTime)
+		})
+	}
+
+	return series
+}
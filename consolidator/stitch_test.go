@@ -0,0 +1,96 @@
+package consolidator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+)
+
+func TestDiffSymbols(t *testing.T) {
+	date := time.Date(2017, time.April, 18, 0, 0, 0, 0, time.UTC)
+	previous := []*iex.Symbol{
+		{Symbol: "OLD", Name: "Renamed Corp"},
+		{Symbol: "GONE", Name: "Delisted Corp"},
+		{Symbol: "AAPL", Name: "Apple Inc."},
+	}
+	current := []*iex.Symbol{
+		{Symbol: "NEW", Name: "Renamed Corp"},
+		{Symbol: "AAPL", Name: "Apple Inc."},
+		{Symbol: "IPO", Name: "Freshly Listed Inc."},
+	}
+
+	changes := DiffSymbols(previous, current, date)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got: %+v", changes)
+	}
+
+	change := changes[0]
+	if change.From != "OLD" || change.To != "NEW" || !change.Date.Equal(date) {
+		t.Errorf("unexpected change: %+v", change)
+	}
+}
+
+func TestResolveSymbolChanges(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2017, time.April, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	m := ResolveSymbolChanges([]SymbolChange{
+		{Date: day(3), From: "A", To: "B"},
+		{Date: day(10), From: "B", To: "C"},
+		{Date: day(12), From: "X", To: "Y"},
+	})
+
+	expected := SymbolMap{"A": "C", "B": "C", "X": "Y"}
+	if len(m) != len(expected) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	for from, to := range expected {
+		if m[from] != to {
+			t.Errorf("expected %v -> %v, got: %v", from, to, m[from])
+		}
+	}
+}
+
+func TestStitchBars(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2017, time.April, d, 9, 30, 0, 0, time.UTC)
+	}
+
+	bars := []*Bar{
+		{Symbol: "B", OpenTime: day(5), Close: 10},
+		{Symbol: "A", OpenTime: day(1), Close: 9},
+		{Symbol: "C", OpenTime: day(11), Close: 11},
+		{Symbol: "AAPL", OpenTime: day(1), Close: 140},
+	}
+
+	m := SymbolMap{"A": "C", "B": "C"}
+	series := StitchBars(bars, m)
+	if len(series) != 2 {
+		t.Fatalf("expected 2 listings, got: %v", len(series))
+	}
+
+	c := series["C"]
+	if len(c) != 3 {
+		t.Fatalf("expected 3 bars for C, got: %v", len(c))
+	}
+
+	for i, expected := range []float64{9, 10, 11} {
+		if c[i].Close != expected {
+			t.Errorf("bar %v: expected close %v, got: %v", i, expected, c[i].Close)
+		}
+		if c[i].Symbol != "C" {
+			t.Errorf("bar %v: expected relabeled symbol C, got: %v", i, c[i].Symbol)
+		}
+	}
+
+	// The input bars must not be mutated by relabeling.
+	if bars[1].Symbol != "A" {
+		t.Errorf("input bar was mutated: %+v", bars[1])
+	}
+
+	if len(series["AAPL"]) != 1 {
+		t.Errorf("expected AAPL series to pass through unchanged")
+	}
+}
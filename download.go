@@ -0,0 +1,233 @@
+package iex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// DefaultDownloadAttempts is the number of times a HIST download is
+// attempted before giving up.
+const DefaultDownloadAttempts = 3
+
+// SizeMismatchError is returned when a completed download does not
+// match the size advertised by the HIST endpoint.
+type SizeMismatchError struct {
+	Link string
+	Want int64
+	Got  int64
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("downloaded %v bytes of %v, expected %v",
+		e.Got, e.Link, e.Want)
+}
+
+// ChecksumMismatchError is returned when a completed download does
+// not match the SHA256 recorded in the manifest by a prior run.
+type ChecksumMismatchError struct {
+	Link string
+	Want string
+	Got  string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("SHA256 of %v is %v, manifest records %v",
+		e.Link, e.Got, e.Want)
+}
+
+// Manifest records the SHA256 of downloaded files, keyed by their
+// download link, so a re-download can be verified against a prior
+// run.
+type Manifest map[string]string
+
+// ReadManifest loads a manifest previously written with WriteFile.
+func ReadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// WriteFile saves the manifest as JSON.
+func (m Manifest) WriteFile(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// httpDoer is implemented by *http.Client. Downloads use Do rather
+// than the simpler HTTPClient interface because resuming requires a
+// Range request header.
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Downloader downloads HIST data files with resume on failure and
+// verification of the result. HIST files are multiple GB, so a
+// download interrupted partway is resumed from the current file size
+// with an HTTP Range request rather than restarted.
+type Downloader struct {
+	client   httpDoer
+	attempts int
+	manifest Manifest
+}
+
+// NewDownloader creates a Downloader using http.DefaultClient.
+func NewDownloader() *Downloader {
+	return newDownloader(http.DefaultClient)
+}
+
+func newDownloader(client httpDoer) *Downloader {
+	return &Downloader{
+		client:   client,
+		attempts: DefaultDownloadAttempts,
+	}
+}
+
+// SetMaxAttempts changes the number of download attempts made before
+// giving up.
+func (d *Downloader) SetMaxAttempts(attempts int) {
+	d.attempts = attempts
+}
+
+// SetHTTPClient overrides the HTTP client used for downloads.
+// Downloads use Do rather than the simpler HTTPClient interface
+// because resuming requires a Range request header.
+func (d *Downloader) SetHTTPClient(client RequestDoer) {
+	d.client = client
+}
+
+// SetManifest enables checksum verification against the given
+// manifest. Files whose link is present in the manifest are verified
+// against the recorded SHA256; files that are not are recorded after
+// a successful download.
+func (d *Downloader) SetManifest(m Manifest) {
+	d.manifest = m
+}
+
+// DownloadHIST downloads hist.Link to the given path, resuming any
+// partial file already at that path. Transient failures are retried;
+// a *SizeMismatchError or *ChecksumMismatchError is returned without
+// retrying if the completed file fails verification.
+func (d *Downloader) DownloadHIST(hist *HIST, path string) error {
+	var lastErr error
+	for attempt := 0; attempt < d.attempts; attempt++ {
+		if err := d.downloadOnce(hist, path); err != nil {
+			lastErr = err
+			continue
+		}
+
+		return d.verify(hist, path)
+	}
+
+	return lastErr
+}
+
+// downloadOnce makes a single attempt to complete the file at path,
+// requesting only the missing byte range if part of it is already
+// present.
+func (d *Downloader) downloadOnce(hist *HIST, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	if offset >= hist.Size {
+		// Nothing left to fetch; verify will catch an overshoot.
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", hist.Link, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-", offset))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range request; start over.
+		offset = 0
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%v: HTTP %v", hist.Link, resp.StatusCode)
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// verify checks the completed file's size against hist.Size and, if a
+// manifest is set, its SHA256 against the manifest.
+func (d *Downloader) verify(hist *HIST, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != hist.Size {
+		return &SizeMismatchError{
+			Link: hist.Link,
+			Want: hist.Size,
+			Got:  info.Size(),
+		}
+	}
+
+	if d.manifest == nil {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if want, ok := d.manifest[hist.Link]; ok && want != sum {
+		return &ChecksumMismatchError{
+			Link: hist.Link,
+			Want: want,
+			Got:  sum,
+		}
+	}
+
+	d.manifest[hist.Link] = sum
+	return nil
+}
@@ -0,0 +1,167 @@
+package iex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDownloadServer serves a fixed payload, honoring Range requests.
+type fakeDownloadServer struct {
+	data []byte
+	// Number of initial requests to fail with a 500.
+	failures int
+	// Range headers of the requests received.
+	ranges []string
+}
+
+func (s *fakeDownloadServer) Do(req *http.Request) (*http.Response, error) {
+	s.ranges = append(s.ranges, req.Header.Get("Range"))
+
+	if s.failures > 0 {
+		s.failures--
+		return &http.Response{
+			StatusCode: 500,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	}
+
+	body := s.data
+	code := http.StatusOK
+	if r := req.Header.Get("Range"); r != "" {
+		var offset int64
+		fmt.Sscanf(r, "bytes=%d-", &offset)
+		body = body[offset:]
+		code = http.StatusPartialContent
+	}
+
+	return &http.Response{
+		StatusCode: code,
+		Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+	}, nil
+}
+
+func TestDownloadHIST(t *testing.T) {
+	data := []byte("IEXTP historical data payload")
+	server := &fakeDownloadServer{data: data}
+	hist := &HIST{Link: "https://example.com/hist", Size: int64(len(data))}
+	path := filepath.Join(t.TempDir(), "hist.pcap.gz")
+
+	d := newDownloader(server)
+	manifest := Manifest{}
+	d.SetManifest(manifest)
+
+	if err := d.DownloadHIST(hist, path); err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(downloaded) != string(data) {
+		t.Errorf("unexpected file contents: %q", downloaded)
+	}
+
+	sum := sha256.Sum256(data)
+	if manifest[hist.Link] != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected manifest entry: %v", manifest[hist.Link])
+	}
+}
+
+func TestDownloadHIST_Resume(t *testing.T) {
+	data := []byte("IEXTP historical data payload")
+	server := &fakeDownloadServer{data: data}
+	hist := &HIST{Link: "https://example.com/hist", Size: int64(len(data))}
+	path := filepath.Join(t.TempDir(), "hist.pcap.gz")
+
+	// A prior partial download left the first 5 bytes on disk.
+	if err := os.WriteFile(path, data[:5], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDownloader(server)
+	if err := d.DownloadHIST(hist, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.ranges) != 1 || server.ranges[0] != "bytes=5-" {
+		t.Errorf("expected ranged request, got: %v", server.ranges)
+	}
+
+	downloaded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(downloaded) != string(data) {
+		t.Errorf("unexpected file contents: %q", downloaded)
+	}
+}
+
+func TestDownloadHIST_Retry(t *testing.T) {
+	data := []byte("IEXTP historical data payload")
+	server := &fakeDownloadServer{data: data, failures: 2}
+	hist := &HIST{Link: "https://example.com/hist", Size: int64(len(data))}
+	path := filepath.Join(t.TempDir(), "hist.pcap.gz")
+
+	d := newDownloader(server)
+	if err := d.DownloadHIST(hist, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.ranges) != 3 {
+		t.Errorf("expected 3 attempts, got: %v", len(server.ranges))
+	}
+}
+
+func TestDownloadHIST_SizeMismatch(t *testing.T) {
+	data := []byte("IEXTP historical data payload")
+	server := &fakeDownloadServer{data: data}
+	hist := &HIST{Link: "https://example.com/hist", Size: int64(len(data)) + 10}
+	path := filepath.Join(t.TempDir(), "hist.pcap.gz")
+
+	d := newDownloader(server)
+	err := d.DownloadHIST(hist, path)
+	mismatch, ok := err.(*SizeMismatchError)
+	if !ok {
+		t.Fatalf("expected *SizeMismatchError, got: %v", err)
+	}
+	if mismatch.Got != int64(len(data)) || mismatch.Want != hist.Size {
+		t.Errorf("unexpected mismatch: %+v", mismatch)
+	}
+}
+
+func TestDownloadHIST_ChecksumMismatch(t *testing.T) {
+	data := []byte("IEXTP historical data payload")
+	server := &fakeDownloadServer{data: data}
+	hist := &HIST{Link: "https://example.com/hist", Size: int64(len(data))}
+	path := filepath.Join(t.TempDir(), "hist.pcap.gz")
+
+	d := newDownloader(server)
+	d.SetManifest(Manifest{hist.Link: "not-the-right-sha256"})
+
+	if _, ok := d.DownloadHIST(hist, path).(*ChecksumMismatchError); !ok {
+		t.Fatal("expected *ChecksumMismatchError")
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	manifest := Manifest{"https://example.com/hist": "abc123"}
+
+	if err := manifest.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded["https://example.com/hist"] != "abc123" {
+		t.Errorf("unexpected manifest: %v", loaded)
+	}
+}
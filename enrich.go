@@ -0,0 +1,106 @@
+package iex
+
+import (
+	"strings"
+	"sync"
+)
+
+// enrichmentClient is the subset of Client used by Enricher.
+type enrichmentClient interface {
+	GetSymbols() ([]*Symbol, error)
+	GetCompany(symbol string) (*Company, error)
+}
+
+// SecurityInfo is per-symbol reference data used to enrich exported
+// rows, so grouping by sector downstream does not require a separate
+// join step.
+type SecurityInfo struct {
+	Symbol       string `json:"symbol"`
+	SecurityType string `json:"securityType,omitempty"`
+	Sector       string `json:"sector,omitempty"`
+}
+
+// Enricher looks up SecurityInfo for symbols, backed by the
+// GetSymbols() and GetCompany() endpoints. Results are cached, so
+// enriching a dataset row-by-row fetches each symbol at most once.
+// It is safe for concurrent use.
+type Enricher struct {
+	client enrichmentClient
+
+	mu     sync.Mutex
+	info   map[string]SecurityInfo
+	listed map[string]bool
+}
+
+// NewEnricher creates an empty enricher backed by the given client.
+func NewEnricher(client *Client) *Enricher {
+	return newEnricher(client)
+}
+
+func newEnricher(client enrichmentClient) *Enricher {
+	return &Enricher{
+		client: client,
+		info:   make(map[string]SecurityInfo),
+	}
+}
+
+// Info returns the SecurityInfo for the given symbol, fetching it on
+// first use. Symbols not listed in GetSymbols() resolve to an empty
+// SecurityInfo without a company lookup. Errors are not cached.
+func (e *Enricher) Info(symbol string) (SecurityInfo, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	e.mu.Lock()
+	info, ok := e.info[symbol]
+	e.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	listed, err := e.isListed(symbol)
+	if err != nil {
+		return SecurityInfo{}, err
+	}
+
+	info = SecurityInfo{Symbol: symbol}
+	if listed {
+		company, err := e.client.GetCompany(symbol)
+		if err != nil {
+			return SecurityInfo{}, err
+		}
+
+		info.SecurityType = company.IssueType
+		info.Sector = company.Sector
+	}
+
+	e.mu.Lock()
+	e.info[symbol] = info
+	e.mu.Unlock()
+	return info, nil
+}
+
+// isListed returns whether the given symbol appears in GetSymbols(),
+// fetching the symbol directory on first use.
+func (e *Enricher) isListed(symbol string) (bool, error) {
+	e.mu.Lock()
+	listed := e.listed
+	e.mu.Unlock()
+	if listed != nil {
+		return listed[symbol], nil
+	}
+
+	symbols, err := e.client.GetSymbols()
+	if err != nil {
+		return false, err
+	}
+
+	listed = make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		listed[strings.ToUpper(s.Symbol)] = true
+	}
+
+	e.mu.Lock()
+	e.listed = listed
+	e.mu.Unlock()
+	return listed[symbol], nil
+}
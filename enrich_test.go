@@ -0,0 +1,83 @@
+package iex
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeEnrichmentClient struct {
+	symbols      []*Symbol
+	companies    map[string]*Company
+	symbolCalls  int
+	companyCalls int
+}
+
+func (c *fakeEnrichmentClient) GetSymbols() ([]*Symbol, error) {
+	c.symbolCalls++
+	return c.symbols, nil
+}
+
+func (c *fakeEnrichmentClient) GetCompany(symbol string) (*Company, error) {
+	c.companyCalls++
+	company, ok := c.companies[symbol]
+	if !ok {
+		return nil, fmt.Errorf("unknown company: %v", symbol)
+	}
+	return company, nil
+}
+
+func TestEnricher(t *testing.T) {
+	client := &fakeEnrichmentClient{
+		symbols: []*Symbol{
+			{Symbol: "SNAP"},
+			{Symbol: "FB"},
+		},
+		companies: map[string]*Company{
+			"SNAP": {Symbol: "SNAP", Sector: "Technology", IssueType: "cs"},
+		},
+	}
+	enricher := newEnricher(client)
+
+	for i := 0; i < 3; i++ {
+		info, err := enricher.Info("snap")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Sector != "Technology" || info.SecurityType != "cs" {
+			t.Errorf("unexpected info: %+v", info)
+		}
+	}
+
+	if client.symbolCalls != 1 {
+		t.Errorf("expected 1 GetSymbols call, got %v", client.symbolCalls)
+	}
+	if client.companyCalls != 1 {
+		t.Errorf("expected 1 GetCompany call, got %v", client.companyCalls)
+	}
+}
+
+func TestEnricher_UnlistedSymbol(t *testing.T) {
+	client := &fakeEnrichmentClient{
+		symbols: []*Symbol{{Symbol: "SNAP"}},
+	}
+	enricher := newEnricher(client)
+
+	info, err := enricher.Info("ZIEXT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Sector != "" || info.SecurityType != "" {
+		t.Errorf("unexpected info for unlisted symbol: %+v", info)
+	}
+	if client.companyCalls != 0 {
+		t.Errorf("expected no GetCompany calls, got %v", client.companyCalls)
+	}
+
+	// The empty result is cached too.
+	if _, err := enricher.Info("ZIEXT"); err != nil {
+		t.Fatal(err)
+	}
+	if client.symbolCalls != 1 {
+		t.Errorf("expected 1 GetSymbols call, got %v", client.symbolCalls)
+	}
+}
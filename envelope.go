@@ -0,0 +1,221 @@
+package iex
+
+import (
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/deepplus"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// MessageEnvelope pairs a decoded message with the metadata needed to
+// order it deterministically. Equal nanosecond timestamps are common
+// when merging quotes and trades, so ordering is by
+// (Timestamp, SequenceNumber, MessageIndex).
+type MessageEnvelope struct {
+	Message iextp.Message
+	// The event timestamp of the message, or the segment SendTime
+	// for message types that do not carry one.
+	Timestamp time.Time
+	// The IEX-TP sequence number of the message within its session.
+	SequenceNumber int64
+	// The index of the message within its segment.
+	MessageIndex int
+	// The local time at which the segment carrying the message was
+	// received. Zero for sources without a meaningful receipt time,
+	// such as historical dump replays.
+	ReceiptTime time.Time
+	// The send time of the segment carrying the message.
+	SendTime time.Time
+	// The local capture timestamp of the packet carrying the message,
+	// for packet sources that record one (pcap dumps). Zero otherwise.
+	CaptureTime time.Time
+}
+
+// TimeBase selects which clock a derived dataset (bars, stored ticks)
+// is built on. The message timestamp is IEX's matching-engine event
+// time; the segment send time is when IEX put the message on the
+// wire; the capture time is the local host's clock when the packet
+// was captured. They differ by queueing delay, network delay, and
+// clock skew (see CaptureSkew), and different research uses trust
+// different ones.
+type TimeBase int
+
+const (
+	// TimeBaseMessageTimestamp uses the event timestamp carried by
+	// the message itself. This is the default everywhere.
+	TimeBaseMessageTimestamp TimeBase = iota
+	// TimeBaseSegmentSendTime uses the IEX-TP segment send time.
+	TimeBaseSegmentSendTime
+	// TimeBaseCaptureTime uses the local pcap capture timestamp.
+	TimeBaseCaptureTime
+)
+
+// Time returns the envelope's time under the given base. Bases whose
+// time is unknown fall back to the message timestamp, so consumers
+// degrade gracefully on sources that do not stamp capture times.
+func (tb TimeBase) Time(e *MessageEnvelope) time.Time {
+	switch tb {
+	case TimeBaseSegmentSendTime:
+		if !e.SendTime.IsZero() {
+			return e.SendTime
+		}
+	case TimeBaseCaptureTime:
+		if !e.CaptureTime.IsZero() {
+			return e.CaptureTime
+		}
+	}
+
+	return e.Timestamp
+}
+
+// Latency returns the delta between the segment's send time at IEX
+// and the local receipt time, or ok=false if no receipt time is
+// known. Envelopes without a send time fall back to the message
+// timestamp, which additionally includes the engine-to-wire queueing
+// delay. A negative latency indicates local clock skew relative to
+// IEX.
+func (e *MessageEnvelope) Latency() (latency time.Duration, ok bool) {
+	if e.ReceiptTime.IsZero() {
+		return 0, false
+	}
+
+	sent := e.SendTime
+	if sent.IsZero() {
+		sent = e.Timestamp
+	}
+	return e.ReceiptTime.Sub(sent), true
+}
+
+// Before returns true if e should be ordered before other.
+func (e *MessageEnvelope) Before(other *MessageEnvelope) bool {
+	if !e.Timestamp.Equal(other.Timestamp) {
+		return e.Timestamp.Before(other.Timestamp)
+	}
+
+	if e.SequenceNumber != other.SequenceNumber {
+		return e.SequenceNumber < other.SequenceNumber
+	}
+
+	return e.MessageIndex < other.MessageIndex
+}
+
+// SortEnvelopes sorts the given envelopes in place into their
+// deterministic order.
+func SortEnvelopes(envelopes []*MessageEnvelope) {
+	sort.Slice(envelopes, func(i, j int) bool {
+		return envelopes[i].Before(envelopes[j])
+	})
+}
+
+// MergeEnvelopes merges already-ordered streams of envelopes into a
+// single ordered stream.
+func MergeEnvelopes(streams ...[]*MessageEnvelope) []*MessageEnvelope {
+	total := 0
+	for _, stream := range streams {
+		total += len(stream)
+	}
+
+	result := make([]*MessageEnvelope, 0, total)
+	for len(result) < total {
+		var min *MessageEnvelope
+		minStream := -1
+		for i, stream := range streams {
+			if len(stream) == 0 {
+				continue
+			}
+
+			if min == nil || stream[0].Before(min) {
+				min = stream[0]
+				minStream = i
+			}
+		}
+
+		result = append(result, min)
+		streams[minStream] = streams[minStream][1:]
+	}
+
+	return result
+}
+
+// MessageSymbol returns the symbol of the given message, or ok=false
+// for message types that do not carry one.
+func MessageSymbol(msg iextp.Message) (symbol string, ok bool) {
+	switch m := msg.(type) {
+	case *tops.SecurityDirectoryMessage:
+		return m.Symbol, true
+	case *tops.TradingStatusMessage:
+		return m.Symbol, true
+	case *tops.OperationalHaltStatusMessage:
+		return m.Symbol, true
+	case *tops.ShortSalePriceTestStatusMessage:
+		return m.Symbol, true
+	case *tops.QuoteUpdateMessage:
+		return m.Symbol, true
+	case *tops.TradeReportMessage:
+		return m.Symbol, true
+	case *tops.OfficialPriceMessage:
+		return m.Symbol, true
+	case *tops.TradeBreakMessage:
+		return m.Symbol, true
+	case *tops.AuctionInformationMessage:
+		return m.Symbol, true
+	case *deep.SecurityEventMessage:
+		return m.Symbol, true
+	case *deep.PriceLevelUpdateMessage:
+		return m.Symbol, true
+	case *deepplus.AddOrderMessage:
+		return m.Symbol, true
+	case *deepplus.OrderModifyMessage:
+		return m.Symbol, true
+	case *deepplus.OrderDeleteMessage:
+		return m.Symbol, true
+	case *deepplus.OrderExecutedMessage:
+		return m.Symbol, true
+	default:
+		return "", false
+	}
+}
+
+// MessageTimestamp returns the event timestamp of the given message,
+// or ok=false for message types that do not carry one.
+func MessageTimestamp(msg iextp.Message) (ts time.Time, ok bool) {
+	switch m := msg.(type) {
+	case *tops.SystemEventMessage:
+		return m.Timestamp, true
+	case *tops.SecurityDirectoryMessage:
+		return m.Timestamp, true
+	case *tops.TradingStatusMessage:
+		return m.Timestamp, true
+	case *tops.OperationalHaltStatusMessage:
+		return m.Timestamp, true
+	case *tops.ShortSalePriceTestStatusMessage:
+		return m.Timestamp, true
+	case *tops.QuoteUpdateMessage:
+		return m.Timestamp, true
+	case *tops.TradeReportMessage:
+		return m.Timestamp, true
+	case *tops.OfficialPriceMessage:
+		return m.Timestamp, true
+	case *tops.TradeBreakMessage:
+		return m.Timestamp, true
+	case *tops.AuctionInformationMessage:
+		return m.Timestamp, true
+	case *deep.SecurityEventMessage:
+		return m.Timestamp, true
+	case *deep.PriceLevelUpdateMessage:
+		return m.Timestamp, true
+	case *deepplus.AddOrderMessage:
+		return m.Timestamp, true
+	case *deepplus.OrderModifyMessage:
+		return m.Timestamp, true
+	case *deepplus.OrderDeleteMessage:
+		return m.Timestamp, true
+	case *deepplus.OrderExecutedMessage:
+		return m.Timestamp, true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,114 @@
+package iex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestEnvelopeOrdering(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	a := &MessageEnvelope{Timestamp: ts, SequenceNumber: 1, MessageIndex: 0}
+	b := &MessageEnvelope{Timestamp: ts, SequenceNumber: 1, MessageIndex: 1}
+	c := &MessageEnvelope{Timestamp: ts, SequenceNumber: 2, MessageIndex: 0}
+	d := &MessageEnvelope{Timestamp: ts.Add(time.Nanosecond), SequenceNumber: 0}
+
+	if !a.Before(b) || !b.Before(c) || !c.Before(d) {
+		t.Fatal("envelope ordering is not (timestamp, sequence, index)")
+	}
+	if b.Before(a) || d.Before(a) {
+		t.Fatal("envelope ordering is not antisymmetric")
+	}
+}
+
+func TestSortEnvelopes(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	envelopes := []*MessageEnvelope{
+		{Timestamp: ts, SequenceNumber: 3},
+		{Timestamp: ts.Add(-time.Second), SequenceNumber: 5},
+		{Timestamp: ts, SequenceNumber: 1},
+	}
+
+	SortEnvelopes(envelopes)
+	if envelopes[0].SequenceNumber != 5 ||
+		envelopes[1].SequenceNumber != 1 ||
+		envelopes[2].SequenceNumber != 3 {
+		t.Fatalf("unexpected sort order: %v", envelopes)
+	}
+}
+
+func TestMergeEnvelopes(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	quotes := []*MessageEnvelope{
+		{Timestamp: ts, SequenceNumber: 1},
+		{Timestamp: ts, SequenceNumber: 4},
+	}
+	trades := []*MessageEnvelope{
+		{Timestamp: ts, SequenceNumber: 2},
+		{Timestamp: ts.Add(time.Second), SequenceNumber: 3},
+	}
+
+	merged := MergeEnvelopes(quotes, trades)
+	sequences := make([]int64, len(merged))
+	for i, e := range merged {
+		sequences[i] = e.SequenceNumber
+	}
+
+	expected := []int64{1, 2, 4, 3}
+	for i := range expected {
+		if sequences[i] != expected[i] {
+			t.Fatalf("unexpected merge order: %v", sequences)
+		}
+	}
+}
+
+func TestMessageTimestamp(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	msg := &tops.TradeReportMessage{Timestamp: ts}
+	got, ok := MessageTimestamp(msg)
+	if !ok || !got.Equal(ts) {
+		t.Fatalf("expected trade timestamp %v, got: %v (ok=%v)", ts, got, ok)
+	}
+
+	if _, ok := MessageTimestamp(&tops.SecurityDirectoryMessage{}); !ok {
+		t.Fatal("expected security directory message to have a timestamp")
+	}
+}
+
+func TestMessageSymbol(t *testing.T) {
+	msg := &tops.TradeReportMessage{Symbol: "ZIEXT"}
+	got, ok := MessageSymbol(msg)
+	if !ok || got != "ZIEXT" {
+		t.Fatalf("expected symbol ZIEXT, got: %v (ok=%v)", got, ok)
+	}
+
+	if _, ok := MessageSymbol(&tops.SystemEventMessage{}); ok {
+		t.Fatal("expected system event message to have no symbol")
+	}
+}
+
+func TestTimeBase(t *testing.T) {
+	eventTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	e := &MessageEnvelope{
+		Timestamp:   eventTime,
+		SendTime:    eventTime.Add(50 * time.Microsecond),
+		CaptureTime: eventTime.Add(200 * time.Microsecond),
+	}
+
+	if got := TimeBaseMessageTimestamp.Time(e); !got.Equal(eventTime) {
+		t.Errorf("unexpected message time: %v", got)
+	}
+	if got := TimeBaseSegmentSendTime.Time(e); !got.Equal(e.SendTime) {
+		t.Errorf("unexpected send time: %v", got)
+	}
+	if got := TimeBaseCaptureTime.Time(e); !got.Equal(e.CaptureTime) {
+		t.Errorf("unexpected capture time: %v", got)
+	}
+
+	// Sources without capture stamps fall back to the message time.
+	bare := &MessageEnvelope{Timestamp: eventTime}
+	if got := TimeBaseCaptureTime.Time(bare); !got.Equal(eventTime) {
+		t.Errorf("unexpected fallback time: %v", got)
+	}
+}
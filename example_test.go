@@ -0,0 +1,62 @@
+package iex_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/tops"
+	"github.com/timpalpant/go-iex/pcapio"
+)
+
+// Fetch the current TOPS quotes for a few symbols from the REST API.
+func ExampleClient_GetTOPS() {
+	client := iex.NewClient(http.DefaultClient)
+
+	quotes, err := client.GetTOPS([]string{"AAPL", "SPY"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, quote := range quotes {
+		fmt.Printf("%v: bid %v x %v, ask %v x %v\n",
+			quote.Symbol, quote.BidSize, quote.BidPrice,
+			quote.AskSize, quote.AskPrice)
+	}
+}
+
+// Replay the trades in a HIST pcap dump. The dump may be a classic
+// pcap or a pcapng file, and may be gzipped; NewPacketDataSource
+// detects the format.
+func ExamplePcapScanner() {
+	f, err := os.Open("deep.pcap.gz")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	packetSource, err := pcapio.NewPacketDataSource(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scanner := iex.NewPcapScanner(packetSource)
+	for {
+		msg, err := scanner.NextMessage()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			log.Fatal(err)
+		}
+
+		if trade, ok := msg.(*tops.TradeReportMessage); ok {
+			fmt.Printf("%v: %v shares @ %v\n",
+				trade.Symbol, trade.Size, trade.Price)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package iex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// ExportSchemaVersion is the current schema version stamped on
+// exported messages. It is bumped when the envelope layout or the
+// message structs change incompatibly, so long-lived downstream
+// pipelines can detect exports from a different library version.
+const ExportSchemaVersion = 1
+
+// ExportedMessage is the NDJSON envelope emitted by pcap2json in
+// envelope mode. The payload is the JSON serialization of the decoded
+// message, identified by MessageType.
+type ExportedMessage struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Feed          string          `json:"feed,omitempty"`
+	MessageType   string          `json:"messageType"`
+	CaptureTime   time.Time       `json:"captureTime"`
+	Payload       json.RawMessage `json:"payload"`
+
+	// Set in enrichment mode, from the symbol's reference data.
+	SecurityType string `json:"securityType,omitempty"`
+	Sector       string `json:"sector,omitempty"`
+}
+
+// NewExportedMessage wraps the given message in an export envelope.
+func NewExportedMessage(msg iextp.Message, feed string, captureTime time.Time) (*ExportedMessage, error) {
+	name, ok := MessageTypeName(msg)
+	if !ok {
+		return nil, fmt.Errorf("cannot export message of unknown type %T", msg)
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportedMessage{
+		SchemaVersion: ExportSchemaVersion,
+		Feed:          feed,
+		MessageType:   name,
+		CaptureTime:   captureTime,
+		Payload:       payload,
+	}, nil
+}
+
+// Decode parses the envelope's payload back into its typed message.
+func (e *ExportedMessage) Decode() (iextp.Message, error) {
+	if e.SchemaVersion > ExportSchemaVersion {
+		return nil, fmt.Errorf(
+			"cannot decode schema version %v (newer than %v)",
+			e.SchemaVersion, ExportSchemaVersion)
+	}
+
+	factory, ok := messageFactories[e.MessageType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %v", e.MessageType)
+	}
+
+	msg := factory()
+	if err := json.Unmarshal(e.Payload, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// ExportScanner is a streaming reader for the NDJSON produced by
+// pcap2json in envelope mode, decoding each line back into a typed
+// message so analyses can run over previously exported JSON without
+// the original pcaps.
+type ExportScanner struct {
+	dec *json.Decoder
+}
+
+// NewExportScanner creates an ExportScanner reading from r.
+func NewExportScanner(r io.Reader) *ExportScanner {
+	return &ExportScanner{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next envelope in the stream.
+// Returns io.EOF when the stream is exhausted.
+func (s *ExportScanner) Next() (*ExportedMessage, error) {
+	e := &ExportedMessage{}
+	if err := s.dec.Decode(e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// NextMessage returns the next message in the stream, decoded into
+// its concrete type. Returns io.EOF when the stream is exhausted.
+func (s *ExportScanner) NextMessage() (iextp.Message, error) {
+	e, err := s.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Decode()
+}
+
+// messageFactories maps exported message type names back to their
+// concrete types, for decoding previously exported envelopes.
+var messageFactories = map[string]func() iextp.Message{
+	"SystemEventMessage":              func() iextp.Message { return &tops.SystemEventMessage{} },
+	"SecurityDirectoryMessage":        func() iextp.Message { return &tops.SecurityDirectoryMessage{} },
+	"TradingStatusMessage":            func() iextp.Message { return &tops.TradingStatusMessage{} },
+	"OperationalHaltStatusMessage":    func() iextp.Message { return &tops.OperationalHaltStatusMessage{} },
+	"ShortSalePriceTestStatusMessage": func() iextp.Message { return &tops.ShortSalePriceTestStatusMessage{} },
+	"QuoteUpdateMessage":              func() iextp.Message { return &tops.QuoteUpdateMessage{} },
+	"TradeReportMessage":              func() iextp.Message { return &tops.TradeReportMessage{} },
+	"OfficialPriceMessage":            func() iextp.Message { return &tops.OfficialPriceMessage{} },
+	"TradeBreakMessage":               func() iextp.Message { return &tops.TradeBreakMessage{} },
+	"AuctionInformationMessage":       func() iextp.Message { return &tops.AuctionInformationMessage{} },
+	"SecurityEventMessage":            func() iextp.Message { return &deep.SecurityEventMessage{} },
+	"PriceLevelUpdateMessage":         func() iextp.Message { return &deep.PriceLevelUpdateMessage{} },
+}
+
+// MessageTypeName returns the stable exported name of the given
+// message's type, or ok=false for types that cannot be exported.
+func MessageTypeName(msg iextp.Message) (name string, ok bool) {
+	switch msg.(type) {
+	case *tops.SystemEventMessage:
+		return "SystemEventMessage", true
+	case *tops.SecurityDirectoryMessage:
+		return "SecurityDirectoryMessage", true
+	case *tops.TradingStatusMessage:
+		return "TradingStatusMessage", true
+	case *tops.OperationalHaltStatusMessage:
+		return "OperationalHaltStatusMessage", true
+	case *tops.ShortSalePriceTestStatusMessage:
+		return "ShortSalePriceTestStatusMessage", true
+	case *tops.QuoteUpdateMessage:
+		return "QuoteUpdateMessage", true
+	case *tops.TradeReportMessage:
+		return "TradeReportMessage", true
+	case *tops.OfficialPriceMessage:
+		return "OfficialPriceMessage", true
+	case *tops.TradeBreakMessage:
+		return "TradeBreakMessage", true
+	case *tops.AuctionInformationMessage:
+		return "AuctionInformationMessage", true
+	case *deep.SecurityEventMessage:
+		return "SecurityEventMessage", true
+	case *deep.PriceLevelUpdateMessage:
+		return "PriceLevelUpdateMessage", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,113 @@
+package iex
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestNewExportedMessage(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	msg := &tops.TradeReportMessage{Symbol: "ZIEXT", Price: 99.05, Size: 100}
+
+	exported, err := NewExportedMessage(msg, "TOPS", ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exported.SchemaVersion != ExportSchemaVersion {
+		t.Errorf("expected schema version %v, got: %v",
+			ExportSchemaVersion, exported.SchemaVersion)
+	}
+	if exported.Feed != "TOPS" || exported.MessageType != "TradeReportMessage" {
+		t.Errorf("unexpected envelope: %+v", exported)
+	}
+	if !exported.CaptureTime.Equal(ts) {
+		t.Errorf("expected capture time %v, got: %v", ts, exported.CaptureTime)
+	}
+
+	var payload tops.TradeReportMessage
+	if err := json.Unmarshal(exported.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Symbol != "ZIEXT" || payload.Price != 99.05 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestNewExportedMessage_Unsupported(t *testing.T) {
+	msg := &iextp.UnsupportedMessage{MessageType: 0x99}
+	if _, err := NewExportedMessage(msg, "TOPS", time.Time{}); err == nil {
+		t.Fatal("expected error for unsupported message type")
+	}
+}
+
+func TestExportScanner(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	messages := []iextp.Message{
+		&tops.TradeReportMessage{Symbol: "ZIEXT", Price: 99.05, Size: 100},
+		&tops.QuoteUpdateMessage{Symbol: "ZIEXT", BidPrice: 99.00, AskPrice: 99.10},
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, msg := range messages {
+		exported, err := NewExportedMessage(msg, "TOPS", ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode(exported); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanner := NewExportScanner(&buf)
+	trade, err := scanner.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade, ok := trade.(*tops.TradeReportMessage); !ok || trade.Price != 99.05 {
+		t.Errorf("unexpected first message: %+v", trade)
+	}
+
+	quote, err := scanner.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote, ok := quote.(*tops.QuoteUpdateMessage); !ok || quote.AskPrice != 99.10 {
+		t.Errorf("unexpected second message: %+v", quote)
+	}
+
+	if _, err := scanner.NextMessage(); err != io.EOF {
+		t.Errorf("expected io.EOF, got: %v", err)
+	}
+}
+
+func TestExportedMessageDecode_NewerSchema(t *testing.T) {
+	e := &ExportedMessage{
+		SchemaVersion: ExportSchemaVersion + 1,
+		MessageType:   "TradeReportMessage",
+		Payload:       json.RawMessage(`{}`),
+	}
+
+	if _, err := e.Decode(); err == nil {
+		t.Fatal("expected error for newer schema version")
+	}
+}
+
+func TestExportedMessageDecode_UnknownType(t *testing.T) {
+	e := &ExportedMessage{
+		SchemaVersion: ExportSchemaVersion,
+		MessageType:   "FluxCapacitorMessage",
+		Payload:       json.RawMessage(`{}`),
+	}
+
+	if _, err := e.Decode(); err == nil {
+		t.Fatal("expected error for unknown message type")
+	}
+}
@@ -1,11 +1,16 @@
 module github.com/timpalpant/go-iex
 
+go 1.21.6
+
 require (
-	github.com/google/go-cmp v0.2.0 // indirect
 	github.com/google/go-querystring v0.0.0-20170111101155-53e6ce116135
 	github.com/google/gopacket v1.1.16-0.20181023151400-a35e09f9f224
 	github.com/johnmccabe/go-bitbar v0.4.0
+	golang.org/x/net v0.0.0-20181023162649-9b4f9f5ad519
+)
+
+require (
+	github.com/google/go-cmp v0.2.0 // indirect
 	github.com/mdlayher/raw v0.0.0-20181016155347-fa5ef3332ca9 // indirect
-	golang.org/x/net v0.0.0-20181023162649-9b4f9f5ad519 // indirect
 	golang.org/x/sys v0.0.0-20181024145615-5cd93ef61a7c // indirect
 )
@@ -0,0 +1,284 @@
+// Package hist downloads IEX HIST pcap dumps to local disk.
+//
+// Downloader takes the file listings returned by Client.GetHIST or
+// Client.GetAllAvailableHIST and fetches the gzipped pcap files with
+// bounded concurrency. Each transfer is delegated to iex.Downloader,
+// which resumes an interrupted file in place with an HTTP Range
+// request and verifies the completed file against the advertised
+// size — so a failed bulk download picks up where it left off instead
+// of starting over.
+package hist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/timpalpant/go-iex"
+)
+
+// DefaultConcurrency is how many files are downloaded at once when no
+// concurrency is configured.
+const DefaultConcurrency = 4
+
+// Progress reports the state of one file's download. Callbacks may be
+// invoked from multiple downloads but never concurrently.
+type Progress struct {
+	// The file being downloaded.
+	File *iex.HIST
+	// The local path being written.
+	Path string
+	// Bytes received so far, including any resumed partial data.
+	BytesReceived int64
+	// The advertised total size.
+	TotalBytes int64
+}
+
+// Result describes one completed file.
+type Result struct {
+	// The downloaded file.
+	File *iex.HIST
+	// The local path of the completed file.
+	Path string
+	// The size of the completed file.
+	Bytes int64
+	// Hex SHA-256 of the completed file, for out-of-band checksum
+	// verification. Empty when the file was skipped as already
+	// complete.
+	SHA256 string
+	// Whether a partial download was resumed.
+	Resumed bool
+	// Whether the file was already complete and left untouched.
+	Skipped bool
+}
+
+// Downloader fetches HIST files to a local directory.
+type Downloader struct {
+	dir         string
+	client      *http.Client
+	concurrency int
+
+	mu       sync.Mutex
+	progress func(Progress)
+}
+
+// NewDownloader creates a downloader writing into the given
+// directory.
+func NewDownloader(dir string) *Downloader {
+	return &Downloader{
+		dir:         dir,
+		client:      http.DefaultClient,
+		concurrency: DefaultConcurrency,
+	}
+}
+
+// SetHTTPClient overrides the http.Client used for downloads.
+func (d *Downloader) SetHTTPClient(client *http.Client) {
+	d.client = client
+}
+
+// SetConcurrency overrides how many files are downloaded at once.
+func (d *Downloader) SetConcurrency(n int) {
+	d.concurrency = n
+}
+
+// OnProgress registers a callback invoked as download bytes arrive.
+func (d *Downloader) OnProgress(cb func(Progress)) {
+	d.progress = cb
+}
+
+// Download fetches the given files, returning a result per completed
+// file sorted by date and feed. Files that fail leave their partial
+// data in place for the next run; their errors are joined into the
+// returned error.
+func (d *Downloader) Download(files []*iex.HIST) ([]*Result, error) {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+	queue := make(chan *iex.HIST)
+	outcomes := make(chan outcome, len(files))
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				result, err := d.fetch(file)
+				if err != nil {
+					err = fmt.Errorf("%v %v: %w", file.Date, file.Feed, err)
+				}
+				outcomes <- outcome{result: result, err: err}
+			}
+		}()
+	}
+
+	for _, file := range files {
+		queue <- file
+	}
+	close(queue)
+	wg.Wait()
+	close(outcomes)
+
+	var results []*Result
+	var errs []error
+	for o := range outcomes {
+		if o.err != nil {
+			errs = append(errs, o.err)
+			continue
+		}
+		results = append(results, o.result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].File.Date != results[j].File.Date {
+			return results[i].File.Date < results[j].File.Date
+		}
+		return results[i].File.Feed < results[j].File.Feed
+	})
+	return results, errors.Join(errs...)
+}
+
+// fetch downloads one file, delegating the transfer, resume, and
+// verification to iex.Downloader.
+func (d *Downloader) fetch(file *iex.HIST) (*Result, error) {
+	name, err := localName(file)
+	if err != nil {
+		return nil, err
+	}
+	finalPath := filepath.Join(d.dir, name)
+
+	// Already complete and the right size: nothing to do.
+	if info, err := os.Stat(finalPath); err == nil && info.Size() == file.Size {
+		return &Result{
+			File:    file,
+			Path:    finalPath,
+			Bytes:   info.Size(),
+			Skipped: true,
+		}, nil
+	}
+
+	// Anything else on disk is an interrupted download to resume.
+	resumed := false
+	if info, err := os.Stat(finalPath); err == nil && info.Size() > 0 {
+		resumed = true
+	}
+
+	// A throwaway manifest makes the delegate compute the file's
+	// SHA256 during verification, for the Result.
+	manifest := iex.Manifest{}
+	delegate := iex.NewDownloader()
+	delegate.SetManifest(manifest)
+	delegate.SetHTTPClient(&progressClient{
+		client: d.client,
+		report: func(received int64) {
+			d.report(Progress{
+				File:          file,
+				Path:          finalPath,
+				BytesReceived: received,
+				TotalBytes:    file.Size,
+			})
+		},
+	})
+
+	if err := delegate.DownloadHIST(file, finalPath); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		File:    file,
+		Path:    finalPath,
+		Bytes:   file.Size,
+		SHA256:  manifest[file.Link],
+		Resumed: resumed,
+	}, nil
+}
+
+// progressClient wraps the configured http.Client, reporting received
+// bytes as one file's response bodies are read. The resumed offset is
+// recovered from the Range request header so BytesReceived includes
+// partial data already on disk.
+type progressClient struct {
+	client *http.Client
+	report func(received int64)
+}
+
+func (c *progressClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var offset int64
+	if resp.StatusCode == http.StatusPartialContent {
+		fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-", &offset)
+	}
+	resp.Body = &countingBody{
+		body:     resp.Body,
+		received: offset,
+		report:   c.report,
+	}
+	return resp, nil
+}
+
+// countingBody reports the cumulative bytes read from a response
+// body.
+type countingBody struct {
+	body     io.ReadCloser
+	received int64
+	report   func(int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.received += int64(n)
+		b.report(b.received)
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	return b.body.Close()
+}
+
+// report invokes the progress callback, serialized across downloads.
+func (d *Downloader) report(p Progress) {
+	if d.progress == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.progress(p)
+}
+
+// localName derives the local filename from the download link,
+// falling back to the date and feed.
+func localName(file *iex.HIST) (string, error) {
+	u, err := url.Parse(file.Link)
+	if err != nil {
+		return "", err
+	}
+
+	if name := path.Base(u.Path); name != "" && name != "." && name != "/" {
+		return name, nil
+	}
+	if file.Date == "" || file.Feed == "" {
+		return "", fmt.Errorf("cannot derive a filename for %v", file.Link)
+	}
+
+	return fmt.Sprintf("%v_%v.pcap.gz", file.Date, file.Feed), nil
+}
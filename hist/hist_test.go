@@ -0,0 +1,176 @@
+package hist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-iex"
+)
+
+// histServer serves fixed payloads by path, honoring Range requests.
+type histServer struct {
+	*httptest.Server
+	payloads map[string][]byte
+}
+
+func newHistServer(t *testing.T, payloads map[string][]byte) *histServer {
+	t.Helper()
+	s := &histServer{payloads: payloads}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *histServer) handle(w http.ResponseWriter, r *http.Request) {
+	payload, ok := s.payloads[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if rng := r.Header.Get("Range"); rng != "" {
+		offset, err := strconv.ParseInt(strings.TrimSuffix(
+			strings.TrimPrefix(rng, "bytes="), "-"), 10, 64)
+		if err == nil && offset > 0 && offset < int64(len(payload)) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v",
+				offset, len(payload)-1, len(payload)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(payload[offset:])
+			return
+		}
+	}
+
+	w.Write(payload)
+}
+
+func histFile(server *httptest.Server, name string, size int64) *iex.HIST {
+	return &iex.HIST{
+		Link: server.URL + "/" + name,
+		Date: strings.TrimSuffix(name, ".pcap.gz"),
+		Feed: "TOPS",
+		Size: size,
+	}
+}
+
+func TestDownloader(t *testing.T) {
+	payloads := map[string][]byte{
+		"/20170417.pcap.gz": []byte("first day of data"),
+		"/20170418.pcap.gz": []byte("second day of data"),
+	}
+	server := newHistServer(t, payloads)
+
+	dir := t.TempDir()
+	d := NewDownloader(dir)
+	d.SetConcurrency(2)
+
+	var progress int
+	d.OnProgress(func(p Progress) { progress++ })
+
+	files := []*iex.HIST{
+		histFile(server.Server, "20170418.pcap.gz", 18),
+		histFile(server.Server, "20170417.pcap.gz", 17),
+	}
+	results, err := d.Download(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got: %v", len(results))
+	}
+	if progress == 0 {
+		t.Error("expected progress callbacks")
+	}
+
+	// Results are sorted by date.
+	if results[0].File.Date != "20170417" || results[1].File.Date != "20170418" {
+		t.Errorf("unexpected result order: %v, %v",
+			results[0].File.Date, results[1].File.Date)
+	}
+
+	first := results[0]
+	data, err := os.ReadFile(first.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first day of data" {
+		t.Errorf("unexpected file contents: %q", data)
+	}
+	sum := sha256.Sum256(data)
+	if first.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected checksum: %v", first.SHA256)
+	}
+
+	// A second run skips the completed files.
+	results, err = d.Download(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if !result.Skipped {
+			t.Errorf("expected %v to be skipped", result.Path)
+		}
+	}
+}
+
+func TestDownloader_Resume(t *testing.T) {
+	payload := []byte("a full day of market data")
+	server := newHistServer(t, map[string][]byte{"/20170417.pcap.gz": payload})
+
+	// An interrupted download leaves a short file at the final path;
+	// it is resumed in place.
+	dir := t.TempDir()
+	partial := filepath.Join(dir, "20170417.pcap.gz")
+	if err := os.WriteFile(partial, payload[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDownloader(dir)
+	results, err := d.Download([]*iex.HIST{
+		histFile(server.Server, "20170417.pcap.gz", int64(len(payload))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Resumed {
+		t.Fatalf("expected a resumed result, got: %+v", results)
+	}
+
+	data, err := os.ReadFile(results[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(payload) {
+		t.Errorf("unexpected file contents: %q", data)
+	}
+	sum := sha256.Sum256(payload)
+	if results[0].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected checksum of resumed file: %v", results[0].SHA256)
+	}
+}
+
+func TestDownloader_SizeMismatch(t *testing.T) {
+	server := newHistServer(t, map[string][]byte{
+		"/20170417.pcap.gz": []byte("truncated"),
+	})
+
+	d := NewDownloader(t.TempDir())
+	_, err := d.Download([]*iex.HIST{
+		histFile(server.Server, "20170417.pcap.gz", 1000),
+	})
+	var mismatch *iex.SizeMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected a size mismatch error, got: %v", err)
+	}
+	if mismatch.Want != 1000 {
+		t.Errorf("unexpected mismatch error: %v", mismatch)
+	}
+}
@@ -0,0 +1,96 @@
+package iex
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultHISTPollInterval is how often HISTWatcher polls for new HIST
+// files when no interval is configured.
+const DefaultHISTPollInterval = 5 * time.Minute
+
+// ErrWatchStopped is returned by WaitForHIST when the watcher is
+// stopped before HIST data becomes available.
+var ErrWatchStopped = errors.New("HIST watcher stopped")
+
+// histGetter is the subset of Client used by HISTWatcher.
+type histGetter interface {
+	GetHIST(date time.Time) ([]*HIST, error)
+}
+
+// HISTWatcher polls GetHIST for a given date until files appear.
+// HIST data is published on a T+1 basis with no fixed publication
+// time, so pipelines that consume it must poll for availability.
+type HISTWatcher struct {
+	client   histGetter
+	clock    Clock
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// NewHISTWatcher creates a watcher that polls the given client at
+// DefaultHISTPollInterval.
+func NewHISTWatcher(client *Client) *HISTWatcher {
+	return newHISTWatcher(client, SystemClock)
+}
+
+func newHISTWatcher(client histGetter, clock Clock) *HISTWatcher {
+	return &HISTWatcher{
+		client:   client,
+		clock:    clock,
+		interval: DefaultHISTPollInterval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// SetInterval changes the poll interval. It must be called before
+// WaitForHIST.
+func (w *HISTWatcher) SetInterval(interval time.Duration) {
+	w.interval = interval
+}
+
+// WaitForHIST polls until HIST data for the given date is available
+// and returns it. Errors from the underlying client are treated as
+// not-yet-published and retried. WaitForHIST blocks until data is
+// available or the watcher is stopped.
+func (w *HISTWatcher) WaitForHIST(date time.Time) ([]*HIST, error) {
+	if hist, err := w.client.GetHIST(date); err == nil && len(hist) > 0 {
+		return hist, nil
+	}
+
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.Chan():
+			hist, err := w.client.GetHIST(date)
+			if err != nil {
+				continue
+			}
+
+			if len(hist) > 0 {
+				return hist, nil
+			}
+		case <-w.stop:
+			return nil, ErrWatchStopped
+		}
+	}
+}
+
+// Watch waits for HIST data for the given date and invokes callback
+// with it once available.
+func (w *HISTWatcher) Watch(date time.Time, callback func([]*HIST)) error {
+	hist, err := w.WaitForHIST(date)
+	if err != nil {
+		return err
+	}
+
+	callback(hist)
+	return nil
+}
+
+// Stop aborts any in-progress WaitForHIST calls.
+func (w *HISTWatcher) Stop() {
+	close(w.stop)
+}
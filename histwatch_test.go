@@ -0,0 +1,89 @@
+package iex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeHISTClient returns an error until the configured number of
+// polls have happened, then returns its files.
+type fakeHISTClient struct {
+	polls       int
+	availableAt int
+	files       []*HIST
+	polled      chan struct{}
+}
+
+func (c *fakeHISTClient) GetHIST(date time.Time) ([]*HIST, error) {
+	c.polls++
+	if c.polled != nil {
+		c.polled <- struct{}{}
+	}
+
+	if c.polls < c.availableAt {
+		return nil, errors.New("Received error response: 404 Not Found")
+	}
+
+	return c.files, nil
+}
+
+func TestHISTWatcher_WaitForHIST(t *testing.T) {
+	client := &fakeHISTClient{
+		availableAt: 3,
+		files:       []*HIST{{Link: "https://example.com/hist.pcap.gz"}},
+		polled:      make(chan struct{}, 3),
+	}
+
+	clock := NewFakeClock(time.Now())
+	w := newHISTWatcher(client, clock)
+
+	type result struct {
+		hist []*HIST
+		err  error
+	}
+	done := make(chan result)
+	go func() {
+		hist, err := w.WaitForHIST(time.Now())
+		done <- result{hist, err}
+	}()
+
+	// Wait for the watcher's poll ticker, then advance through two
+	// poll intervals: the first poll still errors, the second
+	// returns the published files. Each advance waits for its poll,
+	// since ticks are dropped while the watcher is mid-poll.
+	clock.BlockUntil(1)
+	<-client.polled
+	clock.Advance(DefaultHISTPollInterval)
+	<-client.polled
+	clock.Advance(DefaultHISTPollInterval)
+	<-client.polled
+
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	if len(r.hist) != 1 || r.hist[0].Link != client.files[0].Link {
+		t.Errorf("unexpected HIST result: %+v", r.hist)
+	}
+}
+
+func TestHISTWatcher_Stop(t *testing.T) {
+	client := &fakeHISTClient{availableAt: 100}
+	clock := NewFakeClock(time.Now())
+	w := newHISTWatcher(client, clock)
+
+	done := make(chan error)
+	go func() {
+		_, err := w.WaitForHIST(time.Now())
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	w.Stop()
+
+	if err := <-done; err != ErrWatchStopped {
+		t.Errorf("expected ErrWatchStopped, got: %v", err)
+	}
+}
@@ -101,16 +101,14 @@ type SecurityEventMessage struct {
 }
 
 func (m *SecurityEventMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 18 {
-		return fmt.Errorf(
-			"cannot unmarshal SecurityEventMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 18, "SecurityEventMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.SecurityEvent = uint8(buf[1])
 	m.Timestamp = tops.ParseTimestamp(buf[2:10])
-	m.Symbol = tops.ParseString(buf[10:18])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
 	return nil
 }
 
@@ -158,16 +156,14 @@ func (m *PriceLevelUpdateMessage) EventProcessingComplete() bool {
 }
 
 func (m *PriceLevelUpdateMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 18 {
-		return fmt.Errorf(
-			"cannot unmarshal SecurityEventMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 30, "PriceLevelUpdateMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.EventFlags = uint8(buf[1])
 	m.Timestamp = tops.ParseTimestamp(buf[2:10])
-	m.Symbol = tops.ParseString(buf[10:18])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
 	m.Size = binary.LittleEndian.Uint32(buf[18:22])
 	m.Price = tops.ParseFloat(buf[22:30])
 	return nil
@@ -135,3 +135,155 @@ func TestPriceLevelUpdateMessage_SellSide(t *testing.T) {
 		t.Fatal("message is sell side")
 	}
 }
+
+// A DEEP feed interleaves the administrative and trading message
+// types shared with TOPS; all of them must decode through the DEEP
+// entry point rather than falling back to UnsupportedMessage.
+func TestUnmarshal_SharedMessageTypes(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		expected iextp.Message
+	}{
+		{
+			name: "SecurityDirectory",
+			data: []byte{
+				0x44, 0x80,
+				0x00, 0x20, 0x89, 0x7b, 0x5a, 0x1f, 0xb6, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+				0x64, 0x00, 0x00, 0x00,
+				0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x01,
+			},
+			expected: &SecurityDirectoryMessage{
+				MessageType:      SecurityDirectory,
+				Flags:            0x80,
+				Timestamp:        time.Date(2017, time.April, 17, 7, 40, 0, 0, time.UTC),
+				Symbol:           "ZIEXT",
+				RoundLotSize:     100,
+				AdjustedPOCPrice: 99.05,
+				LULDTier:         1,
+			},
+		},
+		{
+			name: "OperationalHaltStatus",
+			data: []byte{
+				0x4f, 0x4f,
+				0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20,
+			},
+			expected: &OperationalHaltStatusMessage{
+				MessageType:           OperationalHaltStatus,
+				OperationalHaltStatus: 0x4f,
+				Timestamp:             time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+				Symbol:                "ZIEXT",
+			},
+		},
+		{
+			name: "ShortSalePriceTestStatus",
+			data: []byte{
+				0x50, 0x01,
+				0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20,
+				0x41,
+			},
+			expected: &ShortSalePriceTestStatusMessage{
+				MessageType:              ShortSalePriceTestStatus,
+				ShortSalePriceTestStatus: true,
+				Timestamp:                time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+				Symbol:                   "ZIEXT",
+				Detail:                   0x41,
+			},
+		},
+		{
+			name: "OfficialPrice",
+			data: []byte{
+				0x58, 0x51,
+				0x00, 0xf0, 0x30, 0x2a, 0x5b, 0x25, 0xb6, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20,
+				0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &OfficialPriceMessage{
+				MessageType:   OfficialPrice,
+				PriceType:     0x51,
+				Timestamp:     time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC),
+				Symbol:        "ZIEXT",
+				OfficialPrice: 99.05,
+			},
+		},
+		{
+			name: "TradeBreak",
+			data: []byte{
+				0x42, 0x00,
+				0xb2, 0x8f, 0xa5, 0xa0, 0xab, 0x86, 0x6d, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20,
+				0x64, 0x00, 0x00, 0x00,
+				0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &TradeBreakMessage{
+				MessageType: TradeBreak,
+				Timestamp:   time.Date(2016, time.August, 23, 19, 32, 4, 912754610, time.UTC),
+				Symbol:      "ZIEXT",
+				Size:        100,
+				Price:       99.05,
+				TradeID:     429974,
+			},
+		},
+		{
+			name: "AuctionInformation",
+			data: []byte{
+				0x41, 0x43,
+				0xdd, 0xc7, 0xf0, 0x9a, 0x1a, 0x3a, 0xb6, 0x14,
+				0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20,
+				0x18, 0x6a, 0x00, 0x00,
+				0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x18, 0x1f, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x27, 0x10, 0x00, 0x00,
+				0x42, 0x00,
+				0x80, 0xe6, 0xf4, 0x58,
+				0x0c, 0x21, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0xc0, 0x1c, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0xa4, 0x99, 0x0d, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0xdc, 0x9f, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &AuctionInformationMessage{
+				MessageType:              AuctionInformation,
+				AuctionType:              0x43,
+				Timestamp:                time.Date(2017, time.April, 17, 15, 50, 12, 462929885, time.UTC),
+				Symbol:                   "ZIEXT",
+				PairedShares:             27160,
+				ReferencePrice:           99.05,
+				IndicativeClearingPrice:  99.10,
+				ImbalanceShares:          4135,
+				ImbalanceSide:            0x42,
+				ScheduledAuctionTime:     time.Date(2017, time.April, 17, 16, 0, 0, 0, time.UTC),
+				AuctionBookClearingPrice: 99.15,
+				CollarReferencePrice:     99.04,
+				LowerAuctionCollar:       89.13,
+				UpperAuctionCollar:       108.95,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		msg, err := Unmarshal(tc.data)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+
+		if !reflect.DeepEqual(msg, tc.expected) {
+			t.Errorf("%v: parsed %+v, expected %+v", tc.name, msg, tc.expected)
+		}
+	}
+}
+
+func TestPriceLevelUpdateMessage_Short(t *testing.T) {
+	data := make([]byte, 29)
+	data[0] = PriceLevelUpdateBuySide
+
+	msg := &PriceLevelUpdateMessage{}
+	if err := msg.Unmarshal(data); err == nil {
+		t.Error("expected an error for a truncated price level update")
+	}
+}
@@ -0,0 +1,39 @@
+package deep
+
+import (
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Handler receives messages dispatched by Dispatch: the TOPS handler
+// methods for the message types DEEP shares with TOPS, plus one
+// method for each DEEP-only type. Embed NopHandler to implement only
+// the methods of interest.
+type Handler interface {
+	tops.Handler
+	OnSecurityEvent(*SecurityEventMessage)
+	OnPriceLevelUpdate(*PriceLevelUpdateMessage)
+}
+
+// NopHandler implements Handler with a no-op for every message type,
+// for embedding in handlers that care about only a few types.
+type NopHandler struct {
+	tops.NopHandler
+}
+
+func (NopHandler) OnSecurityEvent(*SecurityEventMessage)       {}
+func (NopHandler) OnPriceLevelUpdate(*PriceLevelUpdateMessage) {}
+
+// Dispatch routes the given message to the handler method for its
+// type, replacing hand-written type switches over the DEEP message
+// types.
+func Dispatch(msg iextp.Message, handler Handler) {
+	switch m := msg.(type) {
+	case *SecurityEventMessage:
+		handler.OnSecurityEvent(m)
+	case *PriceLevelUpdateMessage:
+		handler.OnPriceLevelUpdate(m)
+	default:
+		tops.Dispatch(msg, handler)
+	}
+}
@@ -0,0 +1,37 @@
+package deep
+
+import (
+	"testing"
+)
+
+// levelHandler records price level updates, relying on NopHandler for
+// every other message type.
+type levelHandler struct {
+	NopHandler
+	levels []*PriceLevelUpdateMessage
+	trades []*TradeReportMessage
+}
+
+func (h *levelHandler) OnPriceLevelUpdate(m *PriceLevelUpdateMessage) {
+	h.levels = append(h.levels, m)
+}
+
+func (h *levelHandler) OnTradeReport(m *TradeReportMessage) {
+	h.trades = append(h.trades, m)
+}
+
+func TestDispatch(t *testing.T) {
+	h := &levelHandler{}
+
+	Dispatch(&PriceLevelUpdateMessage{Symbol: "ZIEXT"}, h)
+	Dispatch(&SecurityEventMessage{}, h) // handled by the NopHandler
+	// Shared TOPS types fall through to the TOPS dispatcher.
+	Dispatch(&TradeReportMessage{Symbol: "ZIEXT"}, h)
+
+	if len(h.levels) != 1 {
+		t.Errorf("unexpected price level updates: %+v", h.levels)
+	}
+	if len(h.trades) != 1 {
+		t.Errorf("unexpected trades: %+v", h.trades)
+	}
+}
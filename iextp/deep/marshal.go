@@ -0,0 +1,35 @@
+package deep
+
+import (
+	"encoding/binary"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// AppendTo appends the wire form of the message to buf.
+func (m *SecurityEventMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, SecurityEvent, m.SecurityEvent)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	return tops.AppendSymbol(buf, m.Symbol)
+}
+
+// Marshal returns the wire form of the message.
+func (m *SecurityEventMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf. The side is
+// carried by MessageType (PriceLevelUpdateBuySide or
+// PriceLevelUpdateSellSide), which is encoded as set.
+func (m *PriceLevelUpdateMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, m.MessageType, m.EventFlags)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	buf = tops.AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	return tops.AppendFloat(buf, m.Price)
+}
+
+// Marshal returns the wire form of the message.
+func (m *PriceLevelUpdateMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
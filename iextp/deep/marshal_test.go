@@ -0,0 +1,56 @@
+package deep
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// TestMarshal_RoundTrip decodes the spec example for each DEEP-only
+// message type and re-encodes it, verifying the wire form survives
+// byte for byte. The shared message types are covered by the TOPS
+// round-trip tests.
+func TestMarshal_RoundTrip(t *testing.T) {
+	fixtures := map[string][]byte{
+		"SecurityEventMessage": {
+			0x45,                                           // E = Security Event
+			0x4f,                                           // O = Opening Process Complete
+			0x00, 0xf0, 0x30, 0x2a, 0x5b, 0x25, 0xb6, 0x14, // 2017-04-17 09:30:00
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		},
+		"PriceLevelUpdateMessage (buy side)": {
+			0x38,                                           // Price level update on the Buy Side
+			0x01,                                           // Event processing complete
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0xe4, 0x25, 0x00, 0x00, // 9,700 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+		},
+		"PriceLevelUpdateMessage (sell side)": {
+			0x35,                                           // Price level update on the Sell Side
+			0x00,                                           // Event processing incomplete
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0xe8, 0x03, 0x00, 0x00, // 1,000 shares
+			0xec, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.07
+		},
+	}
+
+	for name, data := range fixtures {
+		msg, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		marshaler, ok := msg.(iextp.Marshaler)
+		if !ok {
+			t.Fatalf("%v does not implement iextp.Marshaler", name)
+		}
+
+		if got := marshaler.AppendTo(nil); !bytes.Equal(got, data) {
+			t.Errorf("%v round trip mismatch:\n got: % x\nwant: % x",
+				name, got, data)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package deepplus implements an unmarshaler for the DEEP+ protocol,
+// v1.0: the order-by-order variant of DEEP that disseminates each
+// displayed order individually (add, modify, delete, execute) instead
+// of aggregated price levels.
+package deepplus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+const (
+	ChannelID               uint32 = 1
+	V_1_0_MessageProtocolID uint16 = 0x8005
+	FeedName                       = "DEEP+"
+)
+
+const (
+	SystemEvent              = tops.SystemEvent
+	SecurityDirectory        = tops.SecurityDirectory
+	TradingStatus            = tops.TradingStatus
+	OperationalHaltStatus    = tops.OperationalHaltStatus
+	ShortSalePriceTestStatus = tops.ShortSalePriceTestStatus
+	AuctionInformation       = tops.AuctionInformation
+	TradeReport              = tops.TradeReport
+	OfficialPrice            = tops.OfficialPrice
+	TradeBreak               = tops.TradeBreak
+	SecurityEvent            = deep.SecurityEvent
+
+	AddOrder      = 0x61
+	OrderModify   = 0x6d
+	OrderDelete   = 0x64
+	OrderExecuted = 0x65
+)
+
+// Order side values.
+const (
+	BuySide  uint8 = 0x42
+	SellSide uint8 = 0x53
+)
+
+func init() {
+	iextp.RegisterProtocol(V_1_0_MessageProtocolID, Unmarshal)
+}
+
+// Implements the DEEP+ protocol, v1.0.
+func Unmarshal(buf []byte) (iextp.Message, error) {
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("cannot unmarshal %v-length buffer", len(buf))
+	}
+
+	var msg iextp.Message
+
+	messageType := buf[0]
+	switch messageType {
+	case SystemEvent:
+		msg = &SystemEventMessage{}
+	case SecurityDirectory:
+		msg = &SecurityDirectoryMessage{}
+	case TradingStatus:
+		msg = &TradingStatusMessage{}
+	case OperationalHaltStatus:
+		msg = &OperationalHaltStatusMessage{}
+	case ShortSalePriceTestStatus:
+		msg = &ShortSalePriceTestStatusMessage{}
+	case SecurityEvent:
+		msg = &SecurityEventMessage{}
+	case AddOrder:
+		msg = &AddOrderMessage{}
+	case OrderModify:
+		msg = &OrderModifyMessage{}
+	case OrderDelete:
+		msg = &OrderDeleteMessage{}
+	case OrderExecuted:
+		msg = &OrderExecutedMessage{}
+	case TradeReport:
+		msg = &TradeReportMessage{}
+	case OfficialPrice:
+		msg = &OfficialPriceMessage{}
+	case TradeBreak:
+		msg = &TradeBreakMessage{}
+	case AuctionInformation:
+		msg = &AuctionInformationMessage{}
+	default:
+		msg = &iextp.UnsupportedMessage{}
+	}
+
+	err := msg.Unmarshal(buf)
+	return msg, err
+}
+
+type SystemEventMessage = tops.SystemEventMessage
+type SecurityDirectoryMessage = tops.SecurityDirectoryMessage
+type TradingStatusMessage = tops.TradingStatusMessage
+type OperationalHaltStatusMessage = tops.OperationalHaltStatusMessage
+type ShortSalePriceTestStatusMessage = tops.ShortSalePriceTestStatusMessage
+type TradeReportMessage = tops.TradeReportMessage
+type OfficialPriceMessage = tops.OfficialPriceMessage
+type TradeBreakMessage = tops.TradeBreakMessage
+type AuctionInformationMessage = tops.AuctionInformationMessage
+type SecurityEventMessage = deep.SecurityEventMessage
+
+// The AddOrderMessage indicates a displayed order has been added to
+// the IEX Order Book.
+type AddOrderMessage struct {
+	MessageType uint8
+	// Side of the order: BuySide or SellSide.
+	Side uint8
+	// The time of the update event as set by the IEX Trading System logic.
+	Timestamp time.Time
+	// IEX-listed security represented in Nasdaq Integrated symbology.
+	Symbol string
+	// IEX-assigned order identifier, unique within the trading day.
+	OrderID uint64
+	// Displayed size of the order.
+	Size uint32
+	// Limit price of the order.
+	Price float64
+}
+
+func (m *AddOrderMessage) IsBuySide() bool {
+	return m.Side == BuySide
+}
+
+func (m *AddOrderMessage) IsSellSide() bool {
+	return m.Side == SellSide
+}
+
+func (m *AddOrderMessage) Unmarshal(buf []byte) error {
+	if err := iextp.CheckMessageLength(buf, 38, "AddOrderMessage"); err != nil {
+		return err
+	}
+
+	m.MessageType = uint8(buf[0])
+	m.Side = uint8(buf[1])
+	m.Timestamp = tops.ParseTimestamp(buf[2:10])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
+	m.OrderID = binary.LittleEndian.Uint64(buf[18:26])
+	m.Size = binary.LittleEndian.Uint32(buf[26:30])
+	m.Price = tops.ParseFloat(buf[30:38])
+	return nil
+}
+
+// The OrderModifyMessage indicates the size or price of a resting
+// order has changed. A modification may change the order's priority.
+type OrderModifyMessage struct {
+	MessageType uint8
+	// Modification flags, reserved by the spec.
+	Flags uint8
+	// The time of the update event as set by the IEX Trading System logic.
+	Timestamp time.Time
+	// IEX-listed security represented in Nasdaq Integrated symbology.
+	Symbol string
+	// IEX-assigned order identifier of the modified order.
+	OrderID uint64
+	// New displayed size of the order.
+	Size uint32
+	// New limit price of the order.
+	Price float64
+}
+
+func (m *OrderModifyMessage) Unmarshal(buf []byte) error {
+	if err := iextp.CheckMessageLength(buf, 38, "OrderModifyMessage"); err != nil {
+		return err
+	}
+
+	m.MessageType = uint8(buf[0])
+	m.Flags = uint8(buf[1])
+	m.Timestamp = tops.ParseTimestamp(buf[2:10])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
+	m.OrderID = binary.LittleEndian.Uint64(buf[18:26])
+	m.Size = binary.LittleEndian.Uint32(buf[26:30])
+	m.Price = tops.ParseFloat(buf[30:38])
+	return nil
+}
+
+// The OrderDeleteMessage indicates a resting order has been removed
+// from the IEX Order Book.
+type OrderDeleteMessage struct {
+	MessageType uint8
+	// Reserved by the spec.
+	Reserved uint8
+	// The time of the update event as set by the IEX Trading System logic.
+	Timestamp time.Time
+	// IEX-listed security represented in Nasdaq Integrated symbology.
+	Symbol string
+	// IEX-assigned order identifier of the deleted order.
+	OrderID uint64
+}
+
+func (m *OrderDeleteMessage) Unmarshal(buf []byte) error {
+	if err := iextp.CheckMessageLength(buf, 26, "OrderDeleteMessage"); err != nil {
+		return err
+	}
+
+	m.MessageType = uint8(buf[0])
+	m.Reserved = uint8(buf[1])
+	m.Timestamp = tops.ParseTimestamp(buf[2:10])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
+	m.OrderID = binary.LittleEndian.Uint64(buf[18:26])
+	return nil
+}
+
+// The OrderExecutedMessage indicates a resting order has been
+// executed in full or in part. The remaining size, if any, keeps its
+// priority.
+type OrderExecutedMessage struct {
+	MessageType uint8
+	// Flags describing the sale condition of the execution.
+	SaleConditionFlags uint8
+	// The time of the update event as set by the IEX Trading System logic.
+	Timestamp time.Time
+	// IEX-listed security represented in Nasdaq Integrated symbology.
+	Symbol string
+	// IEX-assigned order identifier of the executed order.
+	OrderID uint64
+	// Executed size.
+	Size uint32
+	// Execution price.
+	Price float64
+	// IEX-assigned trade identifier, matching the TradeID of the
+	// corresponding trade report.
+	TradeID int64
+}
+
+func (m *OrderExecutedMessage) Unmarshal(buf []byte) error {
+	if err := iextp.CheckMessageLength(buf, 46, "OrderExecutedMessage"); err != nil {
+		return err
+	}
+
+	m.MessageType = uint8(buf[0])
+	m.SaleConditionFlags = uint8(buf[1])
+	m.Timestamp = tops.ParseTimestamp(buf[2:10])
+	m.Symbol = tops.ParseSymbol(buf[10:18])
+	m.OrderID = binary.LittleEndian.Uint64(buf[18:26])
+	m.Size = binary.LittleEndian.Uint32(buf[26:30])
+	m.Price = tops.ParseFloat(buf[30:38])
+	m.TradeID = int64(binary.LittleEndian.Uint64(buf[38:46]))
+	return nil
+}
@@ -0,0 +1,174 @@
+package deepplus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+func TestUnmarshal_UnknownMessageType(t *testing.T) {
+	data := []byte{0x02} // Not a known message type.
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unkMsg, ok := msg.(*iextp.UnsupportedMessage)
+	if !ok {
+		t.Fatal("expected to decode UnsupportedMessage")
+	}
+
+	if !reflect.DeepEqual(unkMsg.Message, data) {
+		t.Fatal("message data not equal to input")
+	}
+}
+
+func TestUnmarshal_Empty(t *testing.T) {
+	data := []byte{}
+	_, err := Unmarshal(data)
+	if err.Error() != "cannot unmarshal 0-length buffer" {
+		t.Fatal("expected unmarshal error")
+	}
+}
+
+func TestAddOrderMessage(t *testing.T) {
+	data := []byte{
+		0x61,                                           // a = Add Order
+		0x42,                                           // B = buy side
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+		0x64, 0x00, 0x00, 0x00, // 100 shares
+		0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aoMsg := *msg.(*AddOrderMessage)
+	expected := AddOrderMessage{
+		MessageType: AddOrder,
+		Side:        BuySide,
+		Timestamp:   time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+		Symbol:      "ZIEXT",
+		OrderID:     429974,
+		Size:        100,
+		Price:       99.05,
+	}
+
+	if aoMsg != expected {
+		t.Fatalf("parsed: %v, expected: %v", msg, expected)
+	}
+
+	if !aoMsg.IsBuySide() {
+		t.Error("message should be buy side")
+	}
+	if aoMsg.IsSellSide() {
+		t.Error("message should not be sell side")
+	}
+}
+
+func TestOrderModifyMessage(t *testing.T) {
+	data := []byte{
+		0x6d,                                           // m = Order Modify
+		0x00,                                           // reserved flags
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+		0x32, 0x00, 0x00, 0x00, // 50 shares
+		0x18, 0x1f, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.10
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	omMsg := *msg.(*OrderModifyMessage)
+	expected := OrderModifyMessage{
+		MessageType: OrderModify,
+		Timestamp:   time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+		Symbol:      "ZIEXT",
+		OrderID:     429974,
+		Size:        50,
+		Price:       99.10,
+	}
+
+	if omMsg != expected {
+		t.Fatalf("parsed: %v, expected: %v", msg, expected)
+	}
+}
+
+func TestOrderDeleteMessage(t *testing.T) {
+	data := []byte{
+		0x64,                                           // d = Order Delete
+		0x00,                                           // reserved
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	odMsg := *msg.(*OrderDeleteMessage)
+	expected := OrderDeleteMessage{
+		MessageType: OrderDelete,
+		Timestamp:   time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+		Symbol:      "ZIEXT",
+		OrderID:     429974,
+	}
+
+	if odMsg != expected {
+		t.Fatalf("parsed: %v, expected: %v", msg, expected)
+	}
+}
+
+func TestOrderExecutedMessage(t *testing.T) {
+	data := []byte{
+		0x65,                                           // e = Order Executed
+		0x00,                                           // sale condition flags
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+		0x64, 0x00, 0x00, 0x00, // 100 shares
+		0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+		0x97, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // trade 429975
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oeMsg := *msg.(*OrderExecutedMessage)
+	expected := OrderExecutedMessage{
+		MessageType: OrderExecuted,
+		Timestamp:   time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+		Symbol:      "ZIEXT",
+		OrderID:     429974,
+		Size:        100,
+		Price:       99.05,
+		TradeID:     429975,
+	}
+
+	if oeMsg != expected {
+		t.Fatalf("parsed: %v, expected: %v", msg, expected)
+	}
+}
+
+func TestOrderMessage_Short(t *testing.T) {
+	for _, messageType := range []byte{AddOrder, OrderModify, OrderDelete, OrderExecuted} {
+		data := make([]byte, 25)
+		data[0] = messageType
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("expected an error for truncated message type 0x%x", messageType)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package deepplus
+
+import (
+	"encoding/binary"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// AppendTo appends the wire form of the message to buf.
+func (m *AddOrderMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, AddOrder, m.Side)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	buf = tops.AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint64(buf, m.OrderID)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	return tops.AppendFloat(buf, m.Price)
+}
+
+// Marshal returns the wire form of the message.
+func (m *AddOrderMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *OrderModifyMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, OrderModify, m.Flags)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	buf = tops.AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint64(buf, m.OrderID)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	return tops.AppendFloat(buf, m.Price)
+}
+
+// Marshal returns the wire form of the message.
+func (m *OrderModifyMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *OrderDeleteMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, OrderDelete, m.Reserved)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	buf = tops.AppendSymbol(buf, m.Symbol)
+	return binary.LittleEndian.AppendUint64(buf, m.OrderID)
+}
+
+// Marshal returns the wire form of the message.
+func (m *OrderDeleteMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *OrderExecutedMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, OrderExecuted, m.SaleConditionFlags)
+	buf = tops.AppendTimestamp(buf, m.Timestamp)
+	buf = tops.AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint64(buf, m.OrderID)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	buf = tops.AppendFloat(buf, m.Price)
+	return binary.LittleEndian.AppendUint64(buf, uint64(m.TradeID))
+}
+
+// Marshal returns the wire form of the message.
+func (m *OrderExecutedMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
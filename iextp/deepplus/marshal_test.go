@@ -0,0 +1,69 @@
+package deepplus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// TestMarshal_RoundTrip decodes the example for each DEEP+-only
+// message type and re-encodes it, verifying the wire form survives
+// byte for byte. The shared message types are covered by the TOPS
+// round-trip tests.
+func TestMarshal_RoundTrip(t *testing.T) {
+	fixtures := map[string][]byte{
+		"AddOrderMessage": {
+			0x61,                                           // a = Add Order
+			0x42,                                           // B = buy side
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+			0x64, 0x00, 0x00, 0x00, // 100 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+		},
+		"OrderModifyMessage": {
+			0x6d,                                           // m = Order Modify
+			0x00,                                           // reserved flags
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+			0x32, 0x00, 0x00, 0x00, // 50 shares
+			0x18, 0x1f, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.10
+		},
+		"OrderDeleteMessage": {
+			0x64,                                           // d = Order Delete
+			0x00,                                           // reserved
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+		},
+		"OrderExecutedMessage": {
+			0x65,                                           // e = Order Executed
+			0x00,                                           // sale condition flags
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // order 429974
+			0x64, 0x00, 0x00, 0x00, // 100 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0x97, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // trade 429975
+		},
+	}
+
+	for name, data := range fixtures {
+		msg, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		marshaler, ok := msg.(iextp.Marshaler)
+		if !ok {
+			t.Fatalf("%v does not implement iextp.Marshaler", name)
+		}
+
+		if got := marshaler.AppendTo(nil); !bytes.Equal(got, data) {
+			t.Errorf("%v round trip mismatch:\n got: % x\nwant: % x",
+				name, got, data)
+		}
+	}
+}
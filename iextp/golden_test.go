@@ -0,0 +1,148 @@
+package iextp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with current decoder output")
+
+// Protocols covered by the golden-file harness. To add coverage for
+// a new message type, drop a .bin fixture in
+// testdata/golden/<protocol>/ and run go test -update.
+//
+// NOTE: the protocol unmarshalers are invoked directly rather than
+// through the registry, because TestMain in this package registers a
+// stub protocol over the DEEP protocol ID.
+var goldenProtocols = map[string]iextp.Protocol{
+	"tops": tops.Unmarshal,
+	"deep": deep.Unmarshal,
+}
+
+// TestGolden decodes every binary fixture under testdata/golden with
+// its protocol's unmarshaler and compares the JSON serialization of
+// the result against the checked-in golden file.
+func TestGolden(t *testing.T) {
+	for name, protocol := range goldenProtocols {
+		fixtures, err := filepath.Glob(
+			filepath.Join("testdata", "golden", name, "*.bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fixtures) == 0 {
+			t.Fatalf("no golden fixtures for protocol %v", name)
+		}
+
+		for _, fixture := range fixtures {
+			testName := name + "/" + strings.TrimSuffix(
+				filepath.Base(fixture), ".bin")
+			t.Run(testName, func(t *testing.T) {
+				testGoldenFixture(t, protocol, fixture)
+			})
+		}
+	}
+}
+
+// TestGolden_Padded decodes every binary fixture with trailing bytes
+// appended. The IEXTP spec allows messages to grow by appending new
+// fields, so a longer-than-expected buffer must decode identically to
+// the unpadded message.
+func TestGolden_Padded(t *testing.T) {
+	padding := make([]byte, 8)
+
+	for name, protocol := range goldenProtocols {
+		fixtures, err := filepath.Glob(
+			filepath.Join("testdata", "golden", name, "*.bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, fixture := range fixtures {
+			testName := name + "/" + strings.TrimSuffix(
+				filepath.Base(fixture), ".bin")
+			t.Run(testName, func(t *testing.T) {
+				data, err := ioutil.ReadFile(fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want, err := protocol(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := protocol(append(data, padding...))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				wantJSON, err := json.Marshal(want)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotJSON, err := json.Marshal(got)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(gotJSON, wantJSON) {
+					t.Errorf("padded message decoded differently:\ngot:\n%s\nwant:\n%s",
+						gotJSON, wantJSON)
+				}
+			})
+		}
+	}
+}
+
+func testGoldenFixture(t *testing.T, protocol iextp.Protocol, fixture string) {
+	data, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := protocol(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := msg.(*iextp.UnsupportedMessage); ok {
+		t.Fatalf("fixture %v decoded as UnsupportedMessage", fixture)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	goldenFile := strings.TrimSuffix(fixture, ".bin") + ".json"
+	if *update {
+		if err := ioutil.WriteFile(goldenFile, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		t.Fatalf("missing golden file %v; run go test -update", goldenFile)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Errorf("decoded output does not match %v:\ngot:\n%s\nwant:\n%s",
+			goldenFile, buf.Bytes(), golden)
+	}
+}
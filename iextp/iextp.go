@@ -2,6 +2,8 @@ package iextp
 
 import (
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +21,33 @@ type Protocol func(buf []byte) (Message, error)
 
 var protocolRegistry = map[uint16]Protocol{}
 
+// Strict controls whether message decoders reject buffers longer than
+// the specified length of the message. The IEXTP spec allows messages
+// to grow by appending new fields, so by default longer buffers are
+// accepted and the extra bytes are ignored. Validation tooling may set
+// Strict to surface messages with unexpected lengths.
+var Strict = false
+
+// CheckMessageLength validates the length of a message buffer against
+// the length specified for the message type. Buffers that are too
+// short are always rejected; buffers that are too long are rejected
+// only when Strict is set.
+func CheckMessageLength(buf []byte, expected int, messageType string) error {
+	if len(buf) < expected {
+		return fmt.Errorf(
+			"cannot unmarshal %v from %v-length buffer",
+			messageType, len(buf))
+	}
+
+	if Strict && len(buf) != expected {
+		return fmt.Errorf(
+			"strict: %v-length buffer for %v-byte %v",
+			len(buf), expected, messageType)
+	}
+
+	return nil
+}
+
 // Register an IEXTP protocol to use for decoding Segment Messages.
 // RegisterProtocol should be called at init time by packages that implement
 // IEXTP protocols, such as TOPS and DEEP.
@@ -26,13 +55,43 @@ func RegisterProtocol(messageProtocolID uint16, p Protocol) {
 	protocolRegistry[messageProtocolID] = p
 }
 
+// PeekMessageType returns the type byte of a raw message buffer
+// without decoding it. Across IEXTP protocols the type is the first
+// byte of every message.
+func PeekMessageType(buf []byte) (uint8, bool) {
+	if len(buf) == 0 {
+		return 0, false
+	}
+
+	return buf[0], true
+}
+
 // Segment represents an IEXTP Segment.
 type Segment struct {
 	Header   SegmentHeader
 	Messages []Message
+	// Indices holds the original position within the segment of each
+	// entry of Messages. The two are equal after Unmarshal; after
+	// UnmarshalFiltered, Indices maps the surviving messages back to
+	// their wire positions so sequence numbers remain correct.
+	Indices []uint16
 }
 
+// Unmarshal decodes the given buffer into the segment. The Messages
+// slice is reused across calls on the same Segment, so long replay
+// jobs decode in constant memory; callers that retain it across calls
+// should copy it first.
 func (s *Segment) Unmarshal(buf []byte) error {
+	return s.UnmarshalFiltered(buf, nil)
+}
+
+// UnmarshalFiltered decodes the given buffer into the segment,
+// skipping the body decode of any message for which keep returns
+// false. The filter sees the raw message bytes (type byte and, where
+// present, the fixed-offset symbol field), so single-symbol
+// extractions can discard most of the feed without paying for a full
+// decode. A nil keep decodes everything.
+func (s *Segment) UnmarshalFiltered(buf []byte, keep func(raw []byte) bool) error {
 	// Unmarshal segment header.
 	if err := s.Header.Unmarshal(buf); err != nil {
 		return err
@@ -49,7 +108,12 @@ func (s *Segment) Unmarshal(buf []byte) error {
 	}
 
 	cur := segmentHeaderSize // Current position in buf.
-	s.Messages = make([]Message, s.Header.MessageCount)
+	if cap(s.Messages) < int(s.Header.MessageCount) {
+		s.Messages = make([]Message, 0, s.Header.MessageCount)
+		s.Indices = make([]uint16, 0, s.Header.MessageCount)
+	}
+	s.Messages = s.Messages[:0]
+	s.Indices = s.Indices[:0]
 	for i := uint16(0); i < s.Header.MessageCount; i++ {
 		if int(cur+2) > len(buf) {
 			return errors.New(
@@ -66,15 +130,20 @@ func (s *Segment) Unmarshal(buf []byte) error {
 				"invalid segment: message exceeds payload length")
 		}
 
-		// Unmarshal the message.
 		msgBuf := buf[cur : cur+messageLength]
 		cur += messageLength
+		if keep != nil && !keep(msgBuf) {
+			continue
+		}
+
+		// Unmarshal the message.
 		msg, err := protocol(msgBuf)
 		if err != nil {
 			return err
 		}
 
-		s.Messages[i] = msg
+		s.Messages = append(s.Messages, msg)
+		s.Indices = append(s.Indices, i)
 	}
 
 	return nil
@@ -97,15 +166,45 @@ type Message interface {
 // message types it does not know how to decode.
 type UnsupportedMessage struct {
 	MessageType uint8
-	Message     []byte
+	// The event timestamp inferred from bytes 2-10 of the message.
+	// All currently specified message types carry their timestamp
+	// there, so this is usually meaningful even for unknown types.
+	// The zero time if the message is too short to contain one.
+	Timestamp time.Time
+	Message   []byte
 }
 
 func (m *UnsupportedMessage) Unmarshal(buf []byte) error {
 	m.MessageType = uint8(buf[0])
-	m.Message = buf
+	// Copy the content: buf aliases the packet source's receive
+	// buffer, which is reused for subsequent packets.
+	m.Message = append([]byte(nil), buf...)
+	if len(buf) >= 10 {
+		timestampNs := int64(binary.LittleEndian.Uint64(buf[2:10]))
+		m.Timestamp = time.Unix(0, timestampNs).In(time.UTC)
+	}
 	return nil
 }
 
+// MarshalJSON serializes the message with its raw content hex-encoded,
+// so unknown messages can be bucketed by type and inspected when IEX
+// adds new message types.
+func (m *UnsupportedMessage) MarshalJSON() ([]byte, error) {
+	msg := struct {
+		MessageType uint8
+		Timestamp   *time.Time `json:",omitempty"`
+		MessageHex  string
+	}{
+		MessageType: m.MessageType,
+		MessageHex:  hex.EncodeToString(m.Message),
+	}
+	if !m.Timestamp.IsZero() {
+		msg.Timestamp = &m.Timestamp
+	}
+
+	return json.Marshal(&msg)
+}
+
 type SegmentHeader struct {
 	// Version of the IEX-TP protocol.
 	Version uint8
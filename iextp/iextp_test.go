@@ -1,6 +1,7 @@
 package iextp
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
@@ -92,6 +93,46 @@ func TestUnmarshalSegment(t *testing.T) {
 	}
 }
 
+func TestUnmarshalSegment_ReusesMessageSlice(t *testing.T) {
+	var data []byte
+	data = append(data, header...)
+	data = append(data, payload...)
+
+	var segment Segment
+	if err := segment.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	before := cap(segment.Messages)
+	if err := segment.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if cap(segment.Messages) != before {
+		t.Errorf("expected message slice to be reused, cap %v != %v",
+			cap(segment.Messages), before)
+	}
+	if len(segment.Messages) != 2 {
+		t.Fatalf("should have unmarshaled 2 messages, got %v", len(segment.Messages))
+	}
+}
+
+func TestUnsupportedMessage_CopiesBuffer(t *testing.T) {
+	buf := []byte{0x99, 0x00, 0x01, 0x02, 0x03}
+
+	msg := &UnsupportedMessage{}
+	if err := msg.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The packet source reuses its receive buffer; the retained
+	// content must not be affected.
+	buf[4] = 0xff
+	if msg.Message[4] != 0x03 {
+		t.Errorf("expected message content to be copied, got: %x", msg.Message)
+	}
+}
+
 func TestUnmarshalSegment_UnknownProtocol(t *testing.T) {
 	data := []byte{
 		0x01,       // Version: 1
@@ -163,3 +204,92 @@ func TestUnmarshalSegment_NoMessages(t *testing.T) {
 		t.Fatal("should have unmarshaled 0 messages")
 	}
 }
+
+func TestCheckMessageLength(t *testing.T) {
+	buf := make([]byte, 38)
+
+	if err := CheckMessageLength(buf, 38, "TradeReportMessage"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Longer buffers are accepted by default: the spec allows
+	// messages to grow by appending new fields.
+	if err := CheckMessageLength(buf, 30, "PriceLevelUpdateMessage"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckMessageLength(buf, 42, "AuctionInformationMessage")
+	if err == nil {
+		t.Fatal("expected error for short buffer")
+	} else if err.Error() != "cannot unmarshal AuctionInformationMessage from 38-length buffer" {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckMessageLength_Strict(t *testing.T) {
+	Strict = true
+	defer func() { Strict = false }()
+
+	buf := make([]byte, 38)
+
+	if err := CheckMessageLength(buf, 38, "TradeReportMessage"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckMessageLength(buf, 30, "PriceLevelUpdateMessage")
+	if err == nil {
+		t.Fatal("expected error for overlong buffer in strict mode")
+	} else if err.Error() != "strict: 38-length buffer for 30-byte PriceLevelUpdateMessage" {
+		t.Fatal(err)
+	}
+}
+
+func TestUnsupportedMessage_InferredTimestamp(t *testing.T) {
+	data := []byte{
+		0x99, // Unknown message type
+		0x00,
+		0x00, 0xa0, 0x99, 0x97, 0xe9, 0x3d, 0xb6, 0x14, // 2017-04-17 17:00:00
+	}
+
+	msg := &UnsupportedMessage{}
+	if err := msg.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := time.Date(2017, time.April, 17, 17, 0, 0, 0, time.UTC)
+	if !msg.Timestamp.Equal(expected) {
+		t.Errorf("expected inferred timestamp %v, got: %v", expected, msg.Timestamp)
+	}
+
+	out, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedJSON := `{"MessageType":153,"Timestamp":"2017-04-17T17:00:00Z",` +
+		`"MessageHex":"990000a09997e93db614"}`
+	if string(out) != expectedJSON {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
+
+func TestUnsupportedMessage_TooShortForTimestamp(t *testing.T) {
+	msg := &UnsupportedMessage{}
+	if err := msg.Unmarshal([]byte{0x99}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !msg.Timestamp.IsZero() {
+		t.Errorf("expected zero timestamp, got: %v", msg.Timestamp)
+	}
+
+	out, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedJSON := `{"MessageType":153,"MessageHex":"99"}`
+	if string(out) != expectedJSON {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
@@ -0,0 +1,69 @@
+package iextp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Marshaler is implemented by messages that can encode themselves back
+// to their wire form, symmetric with Message.Unmarshal. It enables
+// synthesizing test fixtures, building simulators, and re-emitting
+// filtered dumps.
+type Marshaler interface {
+	// AppendTo appends the wire form of the message to buf and
+	// returns the extended slice.
+	AppendTo(buf []byte) []byte
+}
+
+// AppendTo appends the 40-byte wire form of the header to buf.
+func (sh *SegmentHeader) AppendTo(buf []byte) []byte {
+	var b [40]byte
+	b[0] = sh.Version
+	binary.LittleEndian.PutUint16(b[2:4], sh.MessageProtocolID)
+	binary.LittleEndian.PutUint32(b[4:8], sh.ChannelID)
+	binary.LittleEndian.PutUint32(b[8:12], sh.SessionID)
+	binary.LittleEndian.PutUint16(b[12:14], sh.PayloadLength)
+	binary.LittleEndian.PutUint16(b[14:16], sh.MessageCount)
+	binary.LittleEndian.PutUint64(b[16:24], uint64(sh.StreamOffset))
+	binary.LittleEndian.PutUint64(b[24:32], uint64(sh.FirstMessageSequenceNumber))
+	binary.LittleEndian.PutUint64(b[32:40], uint64(sh.SendTime.UnixNano()))
+	return append(buf, b[:]...)
+}
+
+// Marshal encodes the segment to its wire form: the header followed by
+// one length-prefixed block per message. PayloadLength and
+// MessageCount are computed from Messages, overriding the values in
+// the header; the remaining header fields are encoded as set. An error
+// is returned if any message does not implement Marshaler.
+func (s *Segment) Marshal() ([]byte, error) {
+	payload := make([]byte, 0, 2*len(s.Messages))
+	for _, msg := range s.Messages {
+		m, ok := msg.(Marshaler)
+		if !ok {
+			return nil, fmt.Errorf("%T does not support marshalling", msg)
+		}
+
+		lengthAt := len(payload)
+		payload = append(payload, 0, 0)
+		payload = m.AppendTo(payload)
+		binary.LittleEndian.PutUint16(payload[lengthAt:lengthAt+2],
+			uint16(len(payload)-lengthAt-2))
+	}
+
+	header := s.Header
+	header.PayloadLength = uint16(len(payload))
+	header.MessageCount = uint16(len(s.Messages))
+
+	buf := header.AppendTo(make([]byte, 0, int(segmentHeaderSize)+len(payload)))
+	return append(buf, payload...), nil
+}
+
+// AppendTo appends the message's original wire content to buf.
+func (m *UnsupportedMessage) AppendTo(buf []byte) []byte {
+	return append(buf, m.Message...)
+}
+
+// Marshal returns the message's original wire content.
+func (m *UnsupportedMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
@@ -0,0 +1,50 @@
+package iextp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSegmentHeaderMarshal_RoundTrip(t *testing.T) {
+	h := SegmentHeader{}
+	if err := h.Unmarshal(header); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := h.AppendTo(nil); !bytes.Equal(got, header) {
+		t.Errorf("round trip mismatch:\n got: % x\nwant: % x", got, header)
+	}
+}
+
+func TestSegmentMarshal_RoundTrip(t *testing.T) {
+	data := append(append([]byte{}, header...), payload...)
+
+	segment := Segment{}
+	if err := segment.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := segment.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Errorf("round trip mismatch:\n got: % x\nwant: % x", got, data)
+	}
+}
+
+// unmarshalOnlyMessage does not implement Marshaler.
+type unmarshalOnlyMessage struct{}
+
+func (m *unmarshalOnlyMessage) Unmarshal(buf []byte) error { return nil }
+
+func TestSegmentMarshal_Unsupported(t *testing.T) {
+	segment := Segment{
+		Messages: []Message{&unmarshalOnlyMessage{}},
+	}
+
+	if _, err := segment.Marshal(); err == nil {
+		t.Error("expected an error for a message without marshal support")
+	}
+}
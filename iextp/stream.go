@@ -0,0 +1,59 @@
+package iextp
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// SegmentReader decodes contiguous IEXTP segments from a byte stream,
+// for transports that carry segments back to back (such as TCP
+// retransmission connections) rather than one segment per datagram.
+type SegmentReader struct {
+	r io.Reader
+	// Scratch buffers, reused across segments.
+	hdr     [segmentHeaderSize]byte
+	buf     []byte
+	segment Segment
+}
+
+// NewSegmentReader creates a SegmentReader decoding segments from r.
+func NewSegmentReader(r io.Reader) *SegmentReader {
+	return &SegmentReader{r: r}
+}
+
+// ReadSegment reads and decodes the next segment. The returned
+// Segment is reused across calls, so callers that retain it should
+// copy it first. At a clean segment boundary the underlying reader's
+// io.EOF is returned; a stream ending mid-segment returns
+// io.ErrUnexpectedEOF.
+func (s *SegmentReader) ReadSegment() (*Segment, error) {
+	if _, err := io.ReadFull(s.r, s.hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	payloadLength := int(binary.LittleEndian.Uint16(s.hdr[12:14]))
+	total := int(segmentHeaderSize) + payloadLength
+	if cap(s.buf) < total {
+		s.buf = make([]byte, total)
+	}
+	s.buf = s.buf[:total]
+	copy(s.buf, s.hdr[:])
+
+	if _, err := io.ReadFull(s.r, s.buf[segmentHeaderSize:]); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	if err := s.segment.Unmarshal(s.buf); err != nil {
+		return nil, err
+	}
+
+	return &s.segment, nil
+}
@@ -0,0 +1,70 @@
+package tops
+
+import (
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// Handler receives messages dispatched by Dispatch, with one method
+// per TOPS message type. Embed NopHandler to implement only the
+// methods of interest.
+type Handler interface {
+	OnSystemEvent(*SystemEventMessage)
+	OnSecurityDirectory(*SecurityDirectoryMessage)
+	OnTradingStatus(*TradingStatusMessage)
+	OnOperationalHaltStatus(*OperationalHaltStatusMessage)
+	OnShortSalePriceTestStatus(*ShortSalePriceTestStatusMessage)
+	OnQuoteUpdate(*QuoteUpdateMessage)
+	OnTradeReport(*TradeReportMessage)
+	OnOfficialPrice(*OfficialPriceMessage)
+	OnTradeBreak(*TradeBreakMessage)
+	OnAuctionInformation(*AuctionInformationMessage)
+	// OnUnknown is invoked for any message type not covered above.
+	OnUnknown(iextp.Message)
+}
+
+// NopHandler implements Handler with a no-op for every message type,
+// for embedding in handlers that care about only a few types.
+type NopHandler struct{}
+
+func (NopHandler) OnSystemEvent(*SystemEventMessage)                     {}
+func (NopHandler) OnSecurityDirectory(*SecurityDirectoryMessage)         {}
+func (NopHandler) OnTradingStatus(*TradingStatusMessage)                 {}
+func (NopHandler) OnOperationalHaltStatus(*OperationalHaltStatusMessage) {}
+func (NopHandler) OnShortSalePriceTestStatus(*ShortSalePriceTestStatusMessage) {
+}
+func (NopHandler) OnQuoteUpdate(*QuoteUpdateMessage)               {}
+func (NopHandler) OnTradeReport(*TradeReportMessage)               {}
+func (NopHandler) OnOfficialPrice(*OfficialPriceMessage)           {}
+func (NopHandler) OnTradeBreak(*TradeBreakMessage)                 {}
+func (NopHandler) OnAuctionInformation(*AuctionInformationMessage) {}
+func (NopHandler) OnUnknown(iextp.Message)                         {}
+
+// Dispatch routes the given message to the handler method for its
+// type, replacing hand-written type switches over the TOPS message
+// types.
+func Dispatch(msg iextp.Message, handler Handler) {
+	switch m := msg.(type) {
+	case *SystemEventMessage:
+		handler.OnSystemEvent(m)
+	case *SecurityDirectoryMessage:
+		handler.OnSecurityDirectory(m)
+	case *TradingStatusMessage:
+		handler.OnTradingStatus(m)
+	case *OperationalHaltStatusMessage:
+		handler.OnOperationalHaltStatus(m)
+	case *ShortSalePriceTestStatusMessage:
+		handler.OnShortSalePriceTestStatus(m)
+	case *QuoteUpdateMessage:
+		handler.OnQuoteUpdate(m)
+	case *TradeReportMessage:
+		handler.OnTradeReport(m)
+	case *OfficialPriceMessage:
+		handler.OnOfficialPrice(m)
+	case *TradeBreakMessage:
+		handler.OnTradeBreak(m)
+	case *AuctionInformationMessage:
+		handler.OnAuctionInformation(m)
+	default:
+		handler.OnUnknown(msg)
+	}
+}
@@ -0,0 +1,52 @@
+package tops
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// recordingHandler counts dispatches for the types it cares about,
+// relying on NopHandler for the rest.
+type recordingHandler struct {
+	NopHandler
+	trades  []*TradeReportMessage
+	quotes  []*QuoteUpdateMessage
+	unknown []iextp.Message
+}
+
+func (h *recordingHandler) OnTradeReport(m *TradeReportMessage) {
+	h.trades = append(h.trades, m)
+}
+
+func (h *recordingHandler) OnQuoteUpdate(m *QuoteUpdateMessage) {
+	h.quotes = append(h.quotes, m)
+}
+
+func (h *recordingHandler) OnUnknown(m iextp.Message) {
+	h.unknown = append(h.unknown, m)
+}
+
+type unknownMessage struct{}
+
+func (unknownMessage) Unmarshal(buf []byte) error { return nil }
+
+func TestDispatch(t *testing.T) {
+	h := &recordingHandler{}
+
+	trade := &TradeReportMessage{Symbol: "ZIEXT", Price: 99.05}
+	Dispatch(trade, h)
+	Dispatch(&QuoteUpdateMessage{Symbol: "ZIEXT"}, h)
+	Dispatch(&SystemEventMessage{}, h) // handled by the NopHandler
+	Dispatch(unknownMessage{}, h)
+
+	if len(h.trades) != 1 || h.trades[0] != trade {
+		t.Errorf("unexpected trades: %+v", h.trades)
+	}
+	if len(h.quotes) != 1 {
+		t.Errorf("unexpected quotes: %+v", h.quotes)
+	}
+	if len(h.unknown) != 1 {
+		t.Errorf("unexpected unknown messages: %+v", h.unknown)
+	}
+}
@@ -0,0 +1,79 @@
+package tops
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SymbolFilter matches the fixed 8-byte symbol field of raw TOPS
+// messages against a precomputed set, without decoding the message.
+// Symbols on the wire are space-padded to 8 bytes, so each candidate
+// is padded once up front and the per-message check is a single
+// 8-byte comparison — useful on hot paths that discard most of the
+// feed before full decode.
+type SymbolFilter struct {
+	symbols map[uint64]struct{}
+}
+
+// NewSymbolFilter creates a filter matching the given symbols. It is
+// an error for a symbol to exceed the wire field's 8 bytes.
+func NewSymbolFilter(symbols ...string) (*SymbolFilter, error) {
+	f := &SymbolFilter{
+		symbols: make(map[uint64]struct{}, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		if len(symbol) > 8 {
+			return nil, fmt.Errorf("symbol longer than 8 bytes: %q", symbol)
+		}
+
+		var padded [8]byte
+		copy(padded[:], symbol)
+		for i := len(symbol); i < 8; i++ {
+			padded[i] = ' '
+		}
+		f.symbols[binary.LittleEndian.Uint64(padded[:])] = struct{}{}
+	}
+
+	return f, nil
+}
+
+// Match reports whether the raw TOPS message carries one of the
+// filter's symbols. Messages without a symbol field (system events,
+// unknown types, short buffers) always match, so administrative
+// messages survive filtering just as they do in decoded-path filters.
+func (f *SymbolFilter) Match(buf []byte) bool {
+	if !hasRawSymbol(buf) {
+		return true
+	}
+
+	_, ok := f.symbols[binary.LittleEndian.Uint64(buf[10:18])]
+	return ok
+}
+
+// PeekSymbol returns the symbol of a raw TOPS message without
+// decoding it, or false for messages that carry no symbol field.
+func PeekSymbol(buf []byte) (string, bool) {
+	if !hasRawSymbol(buf) {
+		return "", false
+	}
+
+	return ParseString(buf[10:18]), true
+}
+
+// hasRawSymbol reports whether the raw message's type carries a
+// symbol field. All symbol-bearing TOPS messages place it at bytes
+// 10:18.
+func hasRawSymbol(buf []byte) bool {
+	if len(buf) < 18 {
+		return false
+	}
+
+	switch buf[0] {
+	case SecurityDirectory, TradingStatus, OperationalHaltStatus,
+		ShortSalePriceTestStatus, QuoteUpdate, TradeReport, TradeBreak,
+		OfficialPrice, AuctionInformation:
+		return true
+	}
+
+	return false
+}
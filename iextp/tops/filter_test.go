@@ -0,0 +1,92 @@
+package tops
+
+import (
+	"testing"
+)
+
+// rawQuoteUpdate builds a raw QuoteUpdateMessage buffer carrying the
+// given symbol, space-padded to the wire's 8-byte field.
+func rawQuoteUpdate(symbol string) []byte {
+	data := []byte{
+		0x51,                                           // Q = Quote Update
+		0x00,                                           // Active and regular market session
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, // symbol placeholder
+		0xe4, 0x25, 0x00, 0x00, // 9,700 shares
+		0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+		0xec, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.07
+		0xe8, 0x03, 0x00, 0x00, // 1,000 shares
+	}
+	copy(data[10:18], symbol)
+	return data
+}
+
+func TestSymbolFilter(t *testing.T) {
+	filter, err := NewSymbolFilter("ZIEXT", "SNAP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !filter.Match(rawQuoteUpdate("ZIEXT")) {
+		t.Error("expected ZIEXT to match")
+	}
+	if !filter.Match(rawQuoteUpdate("SNAP")) {
+		t.Error("expected SNAP to match")
+	}
+	if filter.Match(rawQuoteUpdate("FB")) {
+		t.Error("expected FB not to match")
+	}
+	// A prefix of a configured symbol is a different 8-byte field.
+	if filter.Match(rawQuoteUpdate("SNA")) {
+		t.Error("expected SNA not to match")
+	}
+
+	// Messages without a symbol field always match.
+	systemEvent := []byte{
+		0x53,                                           // S = System Event
+		0x45,                                           // End of System Hours
+		0x00, 0xf0, 0x30, 0x2a, 0x5b, 0x25, 0xb6, 0x14, // 2017-04-17 17:00:00
+	}
+	if !filter.Match(systemEvent) {
+		t.Error("expected system event to match")
+	}
+}
+
+func TestSymbolFilter_LongSymbol(t *testing.T) {
+	if _, err := NewSymbolFilter("TOOLONGSYM"); err == nil {
+		t.Error("expected error for symbol longer than 8 bytes")
+	}
+}
+
+func BenchmarkSymbolFilter(b *testing.B) {
+	filter, err := NewSymbolFilter("SNAP", "FB", "AAPL")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	data := rawQuoteUpdate("ZIEXT")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if filter.Match(data) {
+			b.Fatal("unexpected match")
+		}
+	}
+}
+
+// BenchmarkSymbolFilter_Decoded is the baseline it replaces: fully
+// decode the message, then look its symbol string up in a set.
+func BenchmarkSymbolFilter_Decoded(b *testing.B) {
+	universe := map[string]bool{"SNAP": true, "FB": true, "AAPL": true}
+
+	data := rawQuoteUpdate("ZIEXT")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg, err := Unmarshal(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if universe[msg.(*QuoteUpdateMessage).Symbol] {
+			b.Fatal("unexpected match")
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package tops
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// symbolTable interns decoded symbols process-wide, keyed by the raw
+// 8-byte wire field. The tradable universe is small (thousands of
+// symbols) and stable within a session, so the table is unbounded.
+var symbolTable sync.Map // uint64 -> string
+
+// ParseSymbol decodes the fixed 8-byte symbol field into a canonical
+// interned string: decoding the same symbol again returns the same
+// string value, so a full-day replay allocates each symbol once
+// instead of once per message, and equality comparisons short-circuit
+// on the shared string data. It is shared by the TOPS and DEEP
+// decoders.
+func ParseSymbol(buf []byte) string {
+	key := binary.LittleEndian.Uint64(buf)
+	if symbol, ok := symbolTable.Load(key); ok {
+		return symbol.(string)
+	}
+
+	symbol, _ := symbolTable.LoadOrStore(key, ParseString(buf))
+	return symbol.(string)
+}
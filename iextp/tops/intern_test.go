@@ -0,0 +1,43 @@
+package tops
+
+import (
+	"testing"
+)
+
+func TestParseSymbol(t *testing.T) {
+	buf := []byte("ZIEXT   ")
+	if got := ParseSymbol(buf); got != "ZIEXT" {
+		t.Errorf("unexpected symbol: %q", got)
+	}
+
+	// Decoding the same symbol again allocates nothing: the interned
+	// string is returned.
+	allocs := testing.AllocsPerRun(100, func() {
+		if got := ParseSymbol(buf); got != "ZIEXT" {
+			t.Errorf("unexpected symbol: %q", got)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocs per interned decode, got: %v", allocs)
+	}
+}
+
+func BenchmarkParseSymbol(b *testing.B) {
+	buf := []byte("ZIEXT   ")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ParseSymbol(buf) != "ZIEXT" {
+			b.Fatal("unexpected symbol")
+		}
+	}
+}
+
+func BenchmarkParseSymbol_Uninterned(b *testing.B) {
+	buf := []byte("ZIEXT   ")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ParseString(buf) != "ZIEXT" {
+			b.Fatal("unexpected symbol")
+		}
+	}
+}
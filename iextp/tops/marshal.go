@@ -0,0 +1,205 @@
+package tops
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// AppendTimestamp appends the TOPS timestamp wire form of t: a counter
+// of nanoseconds since POSIX (Epoch) time UTC as a little-endian
+// signed 8-byte integer.
+func AppendTimestamp(buf []byte, t time.Time) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(t.UnixNano()))
+	return append(buf, b[:]...)
+}
+
+// AppendEventTime appends the TOPS event time wire form of t: a
+// counter of seconds since POSIX (Epoch) time UTC as a little-endian
+// unsigned 4-byte integer.
+func AppendEventTime(buf []byte, t time.Time) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(t.Unix()))
+	return append(buf, b[:]...)
+}
+
+// AppendFloat appends the TOPS price wire form of f: a little-endian
+// signed 8-byte fixed-point number with 4 digits to the right of an
+// implied decimal point.
+func AppendFloat(buf []byte, f float64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(int64(math.Round(f*10000))))
+	return append(buf, b[:]...)
+}
+
+// AppendString appends s as a fixed-length ASCII field, left justified
+// and space filled on the right. Longer strings are truncated.
+func AppendString(buf []byte, s string, length int) []byte {
+	if len(s) > length {
+		s = s[:length]
+	}
+
+	buf = append(buf, s...)
+	for i := len(s); i < length; i++ {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
+
+// AppendSymbol appends the 8-byte symbol field form of symbol.
+func AppendSymbol(buf []byte, symbol string) []byte {
+	return AppendString(buf, symbol, 8)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *SystemEventMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, SystemEvent, m.SystemEvent)
+	return AppendTimestamp(buf, m.Timestamp)
+}
+
+// Marshal returns the wire form of the message.
+func (m *SystemEventMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *SecurityDirectoryMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, SecurityDirectory, m.Flags)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.RoundLotSize)
+	buf = AppendFloat(buf, m.AdjustedPOCPrice)
+	return append(buf, m.LULDTier)
+}
+
+// Marshal returns the wire form of the message.
+func (m *SecurityDirectoryMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *TradingStatusMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, TradingStatus, m.TradingStatus)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	return AppendString(buf, m.Reason, 4)
+}
+
+// Marshal returns the wire form of the message.
+func (m *TradingStatusMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *OperationalHaltStatusMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, OperationalHaltStatus, m.OperationalHaltStatus)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	return AppendSymbol(buf, m.Symbol)
+}
+
+// Marshal returns the wire form of the message.
+func (m *OperationalHaltStatusMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *ShortSalePriceTestStatusMessage) AppendTo(buf []byte) []byte {
+	status := byte(0)
+	if m.ShortSalePriceTestStatus {
+		status = 1
+	}
+
+	buf = append(buf, ShortSalePriceTestStatus, status)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	return append(buf, m.Detail)
+}
+
+// Marshal returns the wire form of the message.
+func (m *ShortSalePriceTestStatusMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf. The derived
+// MarketSession and SymbolAvailability fields are not encoded; the
+// wire carries only Flags.
+func (m *QuoteUpdateMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, QuoteUpdate, m.Flags)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.BidSize)
+	buf = AppendFloat(buf, m.BidPrice)
+	buf = AppendFloat(buf, m.AskPrice)
+	return binary.LittleEndian.AppendUint32(buf, m.AskSize)
+}
+
+// Marshal returns the wire form of the message.
+func (m *QuoteUpdateMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *TradeReportMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, TradeReport, m.SaleConditionFlags)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	buf = AppendFloat(buf, m.Price)
+	return binary.LittleEndian.AppendUint64(buf, uint64(m.TradeID))
+}
+
+// Marshal returns the wire form of the message.
+func (m *TradeReportMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *OfficialPriceMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, OfficialPrice, m.PriceType)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	return AppendFloat(buf, m.OfficialPrice)
+}
+
+// Marshal returns the wire form of the message.
+func (m *OfficialPriceMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *TradeBreakMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, TradeBreak, m.SaleConditionFlags)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.Size)
+	buf = AppendFloat(buf, m.Price)
+	return binary.LittleEndian.AppendUint64(buf, uint64(m.TradeID))
+}
+
+// Marshal returns the wire form of the message.
+func (m *TradeBreakMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
+
+// AppendTo appends the wire form of the message to buf.
+func (m *AuctionInformationMessage) AppendTo(buf []byte) []byte {
+	buf = append(buf, AuctionInformation, m.AuctionType)
+	buf = AppendTimestamp(buf, m.Timestamp)
+	buf = AppendSymbol(buf, m.Symbol)
+	buf = binary.LittleEndian.AppendUint32(buf, m.PairedShares)
+	buf = AppendFloat(buf, m.ReferencePrice)
+	buf = AppendFloat(buf, m.IndicativeClearingPrice)
+	buf = binary.LittleEndian.AppendUint32(buf, m.ImbalanceShares)
+	buf = append(buf, m.ImbalanceSide, m.ExtensionNumber)
+	buf = AppendEventTime(buf, m.ScheduledAuctionTime)
+	buf = AppendFloat(buf, m.AuctionBookClearingPrice)
+	buf = AppendFloat(buf, m.CollarReferencePrice)
+	buf = AppendFloat(buf, m.LowerAuctionCollar)
+	return AppendFloat(buf, m.UpperAuctionCollar)
+}
+
+// Marshal returns the wire form of the message.
+func (m *AuctionInformationMessage) Marshal() []byte {
+	return m.AppendTo(nil)
+}
@@ -0,0 +1,145 @@
+package tops
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// TestMarshal_RoundTrip decodes the spec example for each message type
+// and re-encodes it, verifying the wire form survives byte for byte.
+func TestMarshal_RoundTrip(t *testing.T) {
+	fixtures := map[string][]byte{
+		"SystemEventMessage": {
+			0x53,                                           // S = System Event
+			0x45,                                           // End of System Hours
+			0x00, 0xa0, 0x99, 0x97, 0xe9, 0x3d, 0xb6, 0x14, // 2017-04-17 17:00:00
+		},
+		"SecurityDirectoryMessage": {
+			0x44,                                           // D = Security Directory
+			0x80,                                           // Test security
+			0x00, 0x20, 0x89, 0x7b, 0x5a, 0x1f, 0xb6, 0x14, // 2017-04-17 07:40:00
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x64, 0x00, 0x00, 0x00, // 100 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0x01, // Tier 1 NMS Stock
+		},
+		"TradingStatusMessage": {
+			0x48,                                           // H = Trading Status
+			0x48,                                           // H = Trading Halted
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x54, 0x31, 0x20, 0x20, // T1 = Halt News Pending
+		},
+		"OperationalHaltStatusMessage": {
+			0x4f,                                           // O = Operational Halt Status
+			0x4f,                                           // O = Operationally halted on IEX
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		},
+		"ShortSalePriceTestStatusMessage": {
+			0x50,                                           // P = Short Sale Price Test Status
+			0x01,                                           // Short Sale Price Test in effect
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x41, // Activated
+		},
+		"QuoteUpdateMessage": {
+			0x51,                                           // Q = Quote Update
+			0x00,                                           // Active and regular market session
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0xe4, 0x25, 0x00, 0x00, // 9,700 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0xec, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.07
+			0xe8, 0x03, 0x00, 0x00, // 1,000 shares
+		},
+		"TradeReportMessage": {
+			0x54,                                           // T = Trade Report
+			0x00,                                           // No sale condition flags
+			0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 19:30:32.572715948
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x64, 0x00, 0x00, 0x00, // 100 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // 429974
+		},
+		"OfficialPriceMessage": {
+			0x58,                                           // X = Official Price
+			0x51,                                           // Q = IEX Official Opening Price
+			0x00, 0xf0, 0x30, 0x2a, 0x5b, 0x25, 0xb6, 0x14, // 2017-04-17 09:30:00
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+		},
+		"TradeBreakMessage": {
+			0x42,                                           // B = Trade Break
+			0x00,                                           // No sale condition flags
+			0xb2, 0x8f, 0xa5, 0xa0, 0xab, 0x86, 0x6d, 0x14, // 2016-08-23 19:32:04.912754610
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x64, 0x00, 0x00, 0x00, // 100 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0x96, 0x8f, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, // 429974
+		},
+		"AuctionInformationMessage": {
+			0x41,                                           // A = Auction Information
+			0x43,                                           // C = Closing Auction
+			0xdd, 0xc7, 0xf0, 0x9a, 0x1a, 0x3a, 0xb6, 0x14, // 2017-04-17 15:50:12.462929885
+			0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+			0x18, 0x6a, 0x00, 0x00, // 27,160 shares
+			0x24, 0x1d, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.05
+			0x18, 0x1f, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.10
+			0x27, 0x10, 0x00, 0x00, // 4,135 shares
+			0x42,                   // B = buy-side imbalance
+			0x00,                   // 0 extensions
+			0x80, 0xe6, 0xf4, 0x58, // 2017-04-17 16:00:00
+			0x0c, 0x21, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.15
+			0xc0, 0x1c, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, // $99.04
+			0xa4, 0x99, 0x0d, 0x00, 0x00, 0x00, 0x00, 0x00, // $89.13
+			0xdc, 0x9f, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, // $108.95
+		},
+	}
+
+	for name, data := range fixtures {
+		msg, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		marshaler, ok := msg.(iextp.Marshaler)
+		if !ok {
+			t.Fatalf("%v does not implement iextp.Marshaler", name)
+		}
+
+		if got := marshaler.AppendTo(nil); !bytes.Equal(got, data) {
+			t.Errorf("%v round trip mismatch:\n got: % x\nwant: % x",
+				name, got, data)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	if got := string(AppendString(nil, "AB", 4)); got != "AB  " {
+		t.Errorf("expected space padding, got: %q", got)
+	}
+	if got := string(AppendString(nil, "TOOLONG", 4)); got != "TOOL" {
+		t.Errorf("expected truncation, got: %q", got)
+	}
+}
+
+func TestAppendFloat(t *testing.T) {
+	// 99.05 is not exactly representable; the fixed-point encoding
+	// must round rather than truncate.
+	buf := AppendFloat(nil, 99.05)
+	if got := ParseFloat(buf); got != 99.05 {
+		t.Errorf("expected 99.05, got: %v", got)
+	}
+}
+
+func TestAppendTimestamp(t *testing.T) {
+	ts := time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC)
+	buf := AppendTimestamp(nil, ts)
+	if got := ParseTimestamp(buf); !got.Equal(ts) {
+		t.Errorf("expected %v, got: %v", ts, got)
+	}
+}
@@ -0,0 +1,67 @@
+package tops
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// RawMessage is a zero-copy view of one raw TOPS message that
+// materializes fields lazily. The hot header fields — type byte,
+// timestamp, symbol — live at fixed offsets in every TOPS message,
+// so scan loops that only need those can avoid the full decode and
+// its time.Time conversions entirely, materializing a time.Time only
+// for the messages they keep. It complements SymbolFilter and
+// Segment.UnmarshalFiltered for bulk extractions from full-market
+// files.
+type RawMessage struct {
+	buf []byte
+}
+
+// Raw wraps a raw message buffer in a lazy view. The buffer is
+// aliased, not copied, so the view is only valid until the underlying
+// packet buffer is reused.
+func Raw(buf []byte) RawMessage {
+	return RawMessage{buf: buf}
+}
+
+// Type returns the message type byte, or zero for an empty buffer.
+func (m RawMessage) Type() uint8 {
+	if len(m.buf) == 0 {
+		return 0
+	}
+
+	return m.buf[0]
+}
+
+// TimestampNs returns the event timestamp as nanoseconds since the
+// POSIX epoch, without constructing a time.Time. Every TOPS message
+// carries its timestamp at bytes 2:10.
+func (m RawMessage) TimestampNs() (int64, bool) {
+	if len(m.buf) < 10 {
+		return 0, false
+	}
+
+	return int64(binary.LittleEndian.Uint64(m.buf[2:10])), true
+}
+
+// Time materializes the event timestamp as a time.Time in UTC.
+func (m RawMessage) Time() (time.Time, bool) {
+	if len(m.buf) < 10 {
+		return time.Time{}, false
+	}
+
+	return ParseTimestamp(m.buf[2:10]), true
+}
+
+// Symbol returns the message's symbol, or false for message types
+// that carry no symbol field.
+func (m RawMessage) Symbol() (string, bool) {
+	return PeekSymbol(m.buf)
+}
+
+// Unmarshal fully decodes the viewed message.
+func (m RawMessage) Unmarshal() (iextp.Message, error) {
+	return Unmarshal(m.buf)
+}
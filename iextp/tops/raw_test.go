@@ -0,0 +1,84 @@
+package tops
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRawMessage(t *testing.T) {
+	data := rawQuoteUpdate("ZIEXT")
+	raw := Raw(data)
+
+	if raw.Type() != QuoteUpdate {
+		t.Errorf("unexpected type: %v", raw.Type())
+	}
+
+	symbol, ok := raw.Symbol()
+	if !ok || symbol != "ZIEXT" {
+		t.Errorf("unexpected symbol: %v (%v)", symbol, ok)
+	}
+
+	expected := time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC)
+	ns, ok := raw.TimestampNs()
+	if !ok || ns != expected.UnixNano() {
+		t.Errorf("unexpected timestamp nanos: %v (%v)", ns, ok)
+	}
+	ts, ok := raw.Time()
+	if !ok || !ts.Equal(expected) {
+		t.Errorf("unexpected timestamp: %v (%v)", ts, ok)
+	}
+
+	msg, err := raw.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote := msg.(*QuoteUpdateMessage); quote.Symbol != "ZIEXT" {
+		t.Errorf("unexpected decoded message: %v", quote)
+	}
+}
+
+func TestRawMessage_Empty(t *testing.T) {
+	raw := Raw(nil)
+	if raw.Type() != 0 {
+		t.Errorf("unexpected type: %v", raw.Type())
+	}
+	if _, ok := raw.TimestampNs(); ok {
+		t.Error("expected no timestamp for empty buffer")
+	}
+	if _, ok := raw.Symbol(); ok {
+		t.Error("expected no symbol for empty buffer")
+	}
+}
+
+// BenchmarkRawMessage measures the lazy scan path: type, symbol, and
+// nanosecond timestamp from the raw buffer, with no time.Time
+// materialization.
+func BenchmarkRawMessage(b *testing.B) {
+	data := rawQuoteUpdate("ZIEXT")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		raw := Raw(data)
+		if _, ok := raw.TimestampNs(); !ok {
+			b.Fatal("missing timestamp")
+		}
+		if _, ok := raw.Symbol(); !ok {
+			b.Fatal("missing symbol")
+		}
+	}
+}
+
+// BenchmarkRawMessage_Decoded is the baseline: full message decode,
+// including time.Time conversion.
+func BenchmarkRawMessage_Decoded(b *testing.B) {
+	data := rawQuoteUpdate("ZIEXT")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg, err := Unmarshal(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if msg.(*QuoteUpdateMessage).Timestamp.IsZero() {
+			b.Fatal("missing timestamp")
+		}
+	}
+}
@@ -123,10 +123,8 @@ type SystemEventMessage struct {
 }
 
 func (m *SystemEventMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 10 {
-		return fmt.Errorf(
-			"cannot unmarshal SystemEventMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 10, "SystemEventMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
@@ -182,16 +180,14 @@ type SecurityDirectoryMessage struct {
 }
 
 func (m *SecurityDirectoryMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 31 {
-		return fmt.Errorf(
-			"cannot unmarshal SecurityDirectoryMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 31, "SecurityDirectoryMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.Flags = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.RoundLotSize = binary.LittleEndian.Uint32(buf[18:22])
 	m.AdjustedPOCPrice = ParseFloat(buf[22:30])
 	m.LULDTier = uint8(buf[30])
@@ -266,16 +262,14 @@ type TradingStatusMessage struct {
 }
 
 func (m *TradingStatusMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 22 {
-		return fmt.Errorf(
-			"cannot unmarshal TradingStatusMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 22, "TradingStatusMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.TradingStatus = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.Reason = ParseString(buf[18:22])
 	return nil
 }
@@ -335,16 +329,14 @@ type OperationalHaltStatusMessage struct {
 }
 
 func (m *OperationalHaltStatusMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 18 {
-		return fmt.Errorf(
-			"cannot unmarshal OperationalHaltStatusMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 18, "OperationalHaltStatusMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.OperationalHaltStatus = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	return nil
 }
 
@@ -379,16 +371,14 @@ type ShortSalePriceTestStatusMessage struct {
 }
 
 func (m *ShortSalePriceTestStatusMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 19 {
-		return fmt.Errorf(
-			"cannot unmarshal ShortSalePriceTestStatusMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 19, "ShortSalePriceTestStatusMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.ShortSalePriceTestStatus = (uint8(buf[1]) != 0)
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.Detail = uint8(buf[18])
 	return nil
 }
@@ -427,23 +417,51 @@ type QuoteUpdateMessage struct {
 	AskPrice float64
 	// Size of the quote at the ask, in number of shares.
 	AskSize uint32
+	// The market session decoded from Flags: RegularSession or
+	// PrePostSession. Derived during Unmarshal so serialized output
+	// carries the decoded semantics, not just the raw flag byte.
+	MarketSession string `json:"marketSession"`
+	// The symbol availability decoded from Flags: SymbolActive or
+	// SymbolHaltedPaused.
+	SymbolAvailability string `json:"symbolAvailability"`
 }
 
+// Values of the derived QuoteUpdateMessage fields decoded from Flags.
+const (
+	// MarketSession values.
+	RegularSession = "REGULAR"
+	PrePostSession = "PRE_POST"
+
+	// SymbolAvailability values.
+	SymbolActive       = "ACTIVE"
+	SymbolHaltedPaused = "HALTED_PAUSED"
+)
+
 func (m *QuoteUpdateMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 42 {
-		return fmt.Errorf(
-			"cannot unmarshal QuoteUpdateMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 42, "QuoteUpdateMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.Flags = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.BidSize = binary.LittleEndian.Uint32(buf[18:22])
 	m.BidPrice = ParseFloat(buf[22:30])
 	m.AskPrice = ParseFloat(buf[30:38])
 	m.AskSize = binary.LittleEndian.Uint32(buf[38:42])
+
+	if m.IsRegularMarketSession() {
+		m.MarketSession = RegularSession
+	} else {
+		m.MarketSession = PrePostSession
+	}
+	if m.IsActive() {
+		m.SymbolAvailability = SymbolActive
+	} else {
+		m.SymbolAvailability = SymbolHaltedPaused
+	}
+
 	return nil
 }
 
@@ -476,16 +494,14 @@ type TradeReportMessage struct {
 }
 
 func (m *TradeReportMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 38 {
-		return fmt.Errorf(
-			"cannot unmarshal TradeReportMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 38, "TradeReportMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.SaleConditionFlags = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.Size = binary.LittleEndian.Uint32(buf[18:22])
 	m.Price = ParseFloat(buf[22:30])
 	m.TradeID = int64(binary.LittleEndian.Uint64(buf[30:38]))
@@ -550,16 +566,14 @@ type OfficialPriceMessage struct {
 }
 
 func (m *OfficialPriceMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 26 {
-		return fmt.Errorf(
-			"cannot unmarshal OfficialMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 26, "OfficialMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.PriceType = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.OfficialPrice = ParseFloat(buf[18:26])
 	return nil
 }
@@ -585,16 +599,14 @@ type TradeBreakMessage struct {
 }
 
 func (m *TradeBreakMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 38 {
-		return fmt.Errorf(
-			"cannot unmarshal TradeBreakMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 38, "TradeBreakMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.SaleConditionFlags = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.Size = binary.LittleEndian.Uint32(buf[18:22])
 	m.Price = ParseFloat(buf[22:30])
 	m.TradeID = int64(binary.LittleEndian.Uint64(buf[30:38]))
@@ -641,16 +653,14 @@ type AuctionInformationMessage struct {
 }
 
 func (m *AuctionInformationMessage) Unmarshal(buf []byte) error {
-	if len(buf) < 80 {
-		return fmt.Errorf(
-			"cannot unmarshal AuctionInformationMessage from %v-length buffer",
-			len(buf))
+	if err := iextp.CheckMessageLength(buf, 80, "AuctionInformationMessage"); err != nil {
+		return err
 	}
 
 	m.MessageType = uint8(buf[0])
 	m.AuctionType = uint8(buf[1])
 	m.Timestamp = ParseTimestamp(buf[2:10])
-	m.Symbol = ParseString(buf[10:18])
+	m.Symbol = ParseSymbol(buf[10:18])
 	m.PairedShares = binary.LittleEndian.Uint32(buf[18:22])
 	m.ReferencePrice = ParseFloat(buf[22:30])
 	m.IndicativeClearingPrice = ParseFloat(buf[30:38])
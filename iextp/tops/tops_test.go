@@ -201,14 +201,16 @@ func TestQuoteUpdateMessage(t *testing.T) {
 
 	quMsg := *msg.(*QuoteUpdateMessage)
 	expected := QuoteUpdateMessage{
-		MessageType: QuoteUpdate,
-		Flags:       0,
-		Timestamp:   time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
-		Symbol:      "ZIEXT",
-		BidSize:     9700,
-		BidPrice:    99.05,
-		AskPrice:    99.07,
-		AskSize:     1000,
+		MessageType:        QuoteUpdate,
+		Flags:              0,
+		Timestamp:          time.Date(2016, time.August, 23, 19, 30, 32, 572715948, time.UTC),
+		Symbol:             "ZIEXT",
+		BidSize:            9700,
+		BidPrice:           99.05,
+		AskPrice:           99.07,
+		AskSize:            1000,
+		MarketSession:      RegularSession,
+		SymbolAvailability: SymbolActive,
 	}
 
 	if quMsg != expected {
@@ -224,6 +226,32 @@ func TestQuoteUpdateMessage(t *testing.T) {
 	}
 }
 
+func TestQuoteUpdateMessage_Flags(t *testing.T) {
+	data := []byte{
+		0x51,                                           // Q = Quote Update
+		0xc0,                                           // Halted/paused, outside regular session
+		0xac, 0x63, 0xc0, 0x20, 0x96, 0x86, 0x6d, 0x14, // 2016-08-23 15:30:32.572715948
+		0x5a, 0x49, 0x45, 0x58, 0x54, 0x20, 0x20, 0x20, // ZIEXT
+		0x00, 0x00, 0x00, 0x00, // zero quote
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+
+	msg, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quMsg := msg.(*QuoteUpdateMessage)
+	if quMsg.MarketSession != PrePostSession {
+		t.Errorf("unexpected market session: %v", quMsg.MarketSession)
+	}
+	if quMsg.SymbolAvailability != SymbolHaltedPaused {
+		t.Errorf("unexpected symbol availability: %v", quMsg.SymbolAvailability)
+	}
+}
+
 func TestTradeReportMessage(t *testing.T) {
 	data := []byte{
 		0x54,
@@ -0,0 +1,174 @@
+// Package indicators provides streaming technical indicators computed
+// over bar sequences, with values aligned to bar close times.
+//
+// Each indicator consumes bars one at a time and reports whether it
+// has processed enough data to be meaningful (the warm-up period).
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+	"github.com/timpalpant/go-iex/stats"
+)
+
+// Value is a single indicator observation, aligned to the close time
+// of the bar that produced it.
+type Value struct {
+	Time  time.Time
+	Value float64
+}
+
+// Indicator computes a streaming value over a sequence of bars.
+type Indicator interface {
+	// Push incorporates the given bar and returns the indicator
+	// value at the bar's close. ok is false until the indicator has
+	// warmed up, in which case the Value should be ignored.
+	Push(bar *consolidator.Bar) (v Value, ok bool)
+}
+
+// SMA is a simple moving average of bar closes.
+type SMA struct {
+	rolling *stats.Rolling
+}
+
+// NewSMA creates a simple moving average over n bars.
+func NewSMA(n int) *SMA {
+	return &SMA{rolling: stats.NewRolling(n)}
+}
+
+// Push implements Indicator. ok is false until n bars have been seen.
+func (s *SMA) Push(bar *consolidator.Bar) (Value, bool) {
+	s.rolling.Push(bar.Close)
+	return Value{bar.CloseTime, s.rolling.Mean()}, s.rolling.Full()
+}
+
+// EMA is an exponential moving average of bar closes with
+// alpha = 2 / (span + 1).
+type EMA struct {
+	ewma *stats.EWMA
+	span int
+	n    int
+}
+
+// NewEMA creates an exponential moving average with the given span.
+func NewEMA(span int) *EMA {
+	return &EMA{
+		ewma: stats.NewEWMASpan(float64(span)),
+		span: span,
+	}
+}
+
+// Push implements Indicator. ok is false until span bars have been
+// seen.
+func (e *EMA) Push(bar *consolidator.Bar) (Value, bool) {
+	e.ewma.Push(bar.Close)
+	e.n++
+	return Value{bar.CloseTime, e.ewma.Value()}, e.n >= e.span
+}
+
+// RSI is Wilder's relative strength index over bar closes.
+type RSI struct {
+	period  int
+	n       int
+	last    float64
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI creates an RSI with the given period (traditionally 14).
+func NewRSI(period int) *RSI {
+	return &RSI{period: period}
+}
+
+// Push implements Indicator. ok is false until period+1 bars have
+// been seen.
+func (r *RSI) Push(bar *consolidator.Bar) (Value, bool) {
+	price := bar.Close
+	if r.n == 0 {
+		r.last = price
+		r.n++
+		return Value{bar.CloseTime, 0}, false
+	}
+
+	gain, loss := 0.0, 0.0
+	if change := price - r.last; change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+	r.last = price
+	r.n++
+
+	// Wilder's smoothing: a simple average over the first period,
+	// then an EWMA with alpha = 1/period.
+	if r.n <= r.period+1 {
+		k := float64(r.n - 1)
+		r.avgGain = (r.avgGain*(k-1) + gain) / k
+		r.avgLoss = (r.avgLoss*(k-1) + loss) / k
+	} else {
+		p := float64(r.period)
+		r.avgGain = (r.avgGain*(p-1) + gain) / p
+		r.avgLoss = (r.avgLoss*(p-1) + loss) / p
+	}
+
+	rsi := 100.0
+	if r.avgLoss > 0 {
+		rs := r.avgGain / r.avgLoss
+		rsi = 100 - 100/(1+rs)
+	}
+
+	return Value{bar.CloseTime, rsi}, r.n > r.period
+}
+
+// VWAPBands computes the volume-weighted average price of all bars
+// seen so far, with bands at +/- k volume-weighted standard
+// deviations around it.
+type VWAPBands struct {
+	k     float64
+	sumW  float64
+	sumWX float64
+	sumX2 float64
+}
+
+// Bands is a VWAP observation with its upper and lower bands.
+type Bands struct {
+	Time         time.Time
+	VWAP         float64
+	Upper, Lower float64
+}
+
+// NewVWAPBands creates VWAP bands at k standard deviations.
+func NewVWAPBands(k float64) *VWAPBands {
+	return &VWAPBands{k: k}
+}
+
+// Push incorporates the given bar, weighting its typical price
+// (H+L+C)/3 by volume, and returns the bands at the bar's close.
+// ok is false until a bar with nonzero volume has been seen.
+func (v *VWAPBands) Push(bar *consolidator.Bar) (Bands, bool) {
+	w := float64(bar.Volume)
+	price := (bar.High + bar.Low + bar.Close) / 3
+	v.sumW += w
+	v.sumWX += w * price
+	v.sumX2 += w * price * price
+
+	if v.sumW == 0 {
+		return Bands{Time: bar.CloseTime}, false
+	}
+
+	vwap := v.sumWX / v.sumW
+	variance := v.sumX2/v.sumW - vwap*vwap
+	if variance < 0 { // Guard against rounding error.
+		variance = 0
+	}
+	band := v.k * math.Sqrt(variance)
+
+	return Bands{
+		Time:  bar.CloseTime,
+		VWAP:  vwap,
+		Upper: vwap + band,
+		Lower: vwap - band,
+	}, true
+}
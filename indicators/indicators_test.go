@@ -0,0 +1,130 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+)
+
+func makeBars(closes ...float64) []*consolidator.Bar {
+	start := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	bars := make([]*consolidator.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = &consolidator.Bar{
+			Symbol:    "AAPL",
+			OpenTime:  start.Add(time.Duration(i) * time.Minute),
+			CloseTime: start.Add(time.Duration(i+1) * time.Minute),
+			High:      c,
+			Low:       c,
+			Close:     c,
+			Volume:    100,
+		}
+	}
+
+	return bars
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSMA_Warmup(t *testing.T) {
+	sma := NewSMA(3)
+	bars := makeBars(1, 2, 3, 4)
+
+	for i, bar := range bars[:2] {
+		if _, ok := sma.Push(bar); ok {
+			t.Fatalf("expected SMA to be warming up at bar %v", i)
+		}
+	}
+
+	v, ok := sma.Push(bars[2])
+	if !ok {
+		t.Fatal("expected SMA to be warm after 3 bars")
+	}
+	if !almostEqual(v.Value, 2) {
+		t.Errorf("expected SMA 2, got: %v", v.Value)
+	}
+	if !v.Time.Equal(bars[2].CloseTime) {
+		t.Errorf("expected value aligned to bar close, got: %v", v.Time)
+	}
+
+	v, _ = sma.Push(bars[3])
+	if !almostEqual(v.Value, 3) {
+		t.Errorf("expected SMA 3, got: %v", v.Value)
+	}
+}
+
+func TestEMA_Warmup(t *testing.T) {
+	ema := NewEMA(3)
+	bars := makeBars(2, 2, 2)
+
+	if _, ok := ema.Push(bars[0]); ok {
+		t.Fatal("expected EMA to be warming up after 1 bar")
+	}
+	ema.Push(bars[1])
+	v, ok := ema.Push(bars[2])
+	if !ok {
+		t.Fatal("expected EMA to be warm after span bars")
+	}
+	if !almostEqual(v.Value, 2) {
+		t.Errorf("expected EMA 2, got: %v", v.Value)
+	}
+}
+
+func TestRSI(t *testing.T) {
+	rsi := NewRSI(2)
+	bars := makeBars(1, 2, 3, 4)
+
+	var v Value
+	var ok bool
+	for i, bar := range bars {
+		v, ok = rsi.Push(bar)
+		if warm := i >= 2; ok != warm {
+			t.Fatalf("bar %v: expected warm=%v, got: %v", i, warm, ok)
+		}
+	}
+
+	// All gains and no losses: RSI should saturate at 100.
+	if !almostEqual(v.Value, 100) {
+		t.Errorf("expected RSI 100 on monotonic gains, got: %v", v.Value)
+	}
+
+	rsi = NewRSI(2)
+	for _, bar := range makeBars(4, 3, 2, 1) {
+		v, _ = rsi.Push(bar)
+	}
+	if !almostEqual(v.Value, 0) {
+		t.Errorf("expected RSI 0 on monotonic losses, got: %v", v.Value)
+	}
+}
+
+func TestVWAPBands(t *testing.T) {
+	vwap := NewVWAPBands(2)
+	bars := makeBars(10, 20)
+	bars[1].Volume = 300
+
+	if _, ok := vwap.Push(&consolidator.Bar{Volume: 0}); ok {
+		t.Fatal("expected VWAP not to be ready with zero volume")
+	}
+
+	vwap = NewVWAPBands(2)
+	vwap.Push(bars[0])
+	bands, ok := vwap.Push(bars[1])
+	if !ok {
+		t.Fatal("expected VWAP to be ready")
+	}
+
+	// VWAP = (100*10 + 300*20) / 400 = 17.5
+	if !almostEqual(bands.VWAP, 17.5) {
+		t.Errorf("expected VWAP 17.5, got: %v", bands.VWAP)
+	}
+	if !(bands.Upper > bands.VWAP && bands.Lower < bands.VWAP) {
+		t.Errorf("expected bands around VWAP, got: %+v", bands)
+	}
+	if !almostEqual(bands.Upper-bands.VWAP, bands.VWAP-bands.Lower) {
+		t.Errorf("expected symmetric bands, got: %+v", bands)
+	}
+}
@@ -155,7 +155,9 @@ type SecurityEventMessage struct {
 	Timestamp     Time
 }
 
-type Trade struct {
+// TradeBase holds the fields common to Trade and TradeBreak,
+// which are identical in the DEEP HTTP API.
+type TradeBase struct {
 	Price                 float64
 	Size                  int
 	TradeID               int64
@@ -163,20 +165,43 @@ type Trade struct {
 	IsOddLot              bool
 	IsOutsideRegularHours bool
 	IsSinglePriceCross    bool
-	IsTradeThroughExcempt bool
-	Timestamp             Time
+	// Whether the trade is subject to Rule 611 (Trade Through)
+	// of SEC Regulation NMS. The json tag preserves the misspelled
+	// key used by the API.
+	IsTradeThroughExempt bool `json:"isTradeThroughExcempt"`
+	Timestamp            Time
+}
+
+// DEPRECATED: Use IsTradeThroughExempt.
+// Kept for compatibility with the previous, misspelled field name.
+func (t *TradeBase) IsTradeThroughExcempt() bool {
+	return t.IsTradeThroughExempt
+}
+
+// Trade is eligible for last sale price dissemination.
+// Mirrors tops.TradeReportMessage.IsLastSaleEligible.
+func (t *TradeBase) IsLastSaleEligible() bool {
+	return !t.IsOutsideRegularHours && !t.IsOddLot
+}
+
+// Trade is eligible to update the official high/low prices.
+// Mirrors tops.TradeReportMessage.IsHighLowPriceEligible.
+func (t *TradeBase) IsHighLowPriceEligible() bool {
+	return !t.IsOutsideRegularHours && !t.IsOddLot
+}
+
+// Trade is eligible to update the consolidated volume.
+// Mirrors tops.TradeReportMessage.IsVolumeEligible.
+func (t *TradeBase) IsVolumeEligible() bool {
+	return true
+}
+
+type Trade struct {
+	TradeBase
 }
 
 type TradeBreak struct {
-	Price                 float64
-	Size                  int
-	TradeID               int64
-	IsISO                 bool
-	IsOddLot              bool
-	IsOutsideRegularHours bool
-	IsSinglePriceCross    bool
-	IsTradeThroughExcempt bool
-	Timestamp             Time
+	TradeBase
 }
 
 type Book struct {
@@ -216,6 +241,21 @@ type Symbol struct {
 	IsEnabled bool
 }
 
+// SymbolMapping associates an IEX symbol with other vendors'
+// security identifiers.
+type SymbolMapping struct {
+	// Refers to the symbol represented in Nasdaq Integrated symbology (INET).
+	Symbol string
+	// The exchange on which the mapped listing trades.
+	Exchange string
+	// The OpenFIGI identifier of the listing.
+	FIGI string
+	// The ISIN of the security, when mapped by ISIN.
+	ISIN string
+	// The region of the mapped listing, e.g. "US".
+	Region string
+}
+
 type IntradayStats struct {
 	// Refers to single counted shares matched from executions on IEX.
 	Volume struct {
@@ -88,3 +88,34 @@ func TestStatsUnmarshal_BoolTrue(t *testing.T) {
 			stats.IsHalfDay, true)
 	}
 }
+
+func TestTradeUnmarshal(t *testing.T) {
+	data := []byte(`{
+		"price": 156.10,
+		"size": 100,
+		"tradeId": 919837,
+		"isISO": false,
+		"isOddLot": true,
+		"isOutsideRegularHours": false,
+		"isSinglePriceCross": false,
+		"isTradeThroughExcempt": true,
+		"timestamp": 1494591020567
+	}`)
+
+	var trade *Trade
+	if err := json.Unmarshal(data, &trade); err != nil {
+		t.Fatal(err)
+	}
+
+	if !trade.IsTradeThroughExempt {
+		t.Fatal("did not unmarshal isTradeThroughExcempt correctly")
+	}
+
+	if !trade.IsTradeThroughExcempt() {
+		t.Fatal("deprecated accessor does not match field")
+	}
+
+	if trade.IsLastSaleEligible() {
+		t.Fatal("odd lot trade should not be last sale eligible")
+	}
+}
@@ -0,0 +1,141 @@
+package iex
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the LatencyHistogram
+// buckets, chosen to cover typical feed latencies from sub-millisecond
+// colocation up to badly delayed consumer links. Observations above
+// the last bound are counted in an overflow bucket.
+var DefaultLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// LatencyBucket is one bucket of a LatencyHistogram snapshot.
+type LatencyBucket struct {
+	// The bucket counts observations <= UpperBound. The overflow
+	// bucket has UpperBound zero.
+	UpperBound time.Duration
+	Count      int64
+}
+
+// LatencyHistogram accumulates feed latency observations: the delta
+// between a message's send time at IEX and its local receipt time.
+// Negative observations are possible when the local clock runs behind
+// IEX's, and are counted in the first bucket, so a histogram skewed
+// heavily into it usually indicates clock skew rather than a fast
+// link. It is safe for concurrent use.
+type LatencyHistogram struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	// counts has one entry per bucket plus a final overflow entry.
+	counts []int64
+	count  int64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// NewLatencyHistogram creates a histogram with DefaultLatencyBuckets.
+func NewLatencyHistogram() *LatencyHistogram {
+	return NewLatencyHistogramBuckets(DefaultLatencyBuckets)
+}
+
+// NewLatencyHistogramBuckets creates a histogram with the given
+// bucket upper bounds, which must be sorted ascending.
+func NewLatencyHistogramBuckets(buckets []time.Duration) *LatencyHistogram {
+	return &LatencyHistogram{
+		buckets: buckets,
+		counts:  make([]int64, len(buckets)+1),
+	}
+}
+
+// Observe records one latency observation.
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(h.buckets) && d > h.buckets[i] {
+		i++
+	}
+	h.counts[i]++
+
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if h.count == 0 || d > h.max {
+		h.max = d
+	}
+	h.count++
+	h.sum += d
+}
+
+// ObserveEnvelope records the latency of the given envelope, if it
+// carries a receipt time.
+func (h *LatencyHistogram) ObserveEnvelope(e *MessageEnvelope) {
+	if d, ok := e.Latency(); ok {
+		h.Observe(d)
+	}
+}
+
+// Count returns the number of observations recorded.
+func (h *LatencyHistogram) Count() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Mean returns the mean of the observations recorded, or zero if
+// there are none.
+func (h *LatencyHistogram) Mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Min returns the smallest observation recorded, or zero if there are
+// none.
+func (h *LatencyHistogram) Min() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.min
+}
+
+// Max returns the largest observation recorded, or zero if there are
+// none.
+func (h *LatencyHistogram) Max() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.max
+}
+
+// Snapshot returns the bucket counts, with the overflow bucket last.
+func (h *LatencyHistogram) Snapshot() []LatencyBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := make([]LatencyBucket, len(h.counts))
+	for i, bound := range h.buckets {
+		snapshot[i] = LatencyBucket{UpperBound: bound, Count: h.counts[i]}
+	}
+	snapshot[len(h.buckets)] = LatencyBucket{Count: h.counts[len(h.buckets)]}
+	return snapshot
+}
@@ -0,0 +1,78 @@
+package iex
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogramBuckets([]time.Duration{
+		time.Millisecond,
+		10 * time.Millisecond,
+	})
+
+	h.Observe(500 * time.Microsecond)
+	h.Observe(2 * time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(time.Second)
+
+	if h.Count() != 4 {
+		t.Errorf("unexpected count: %v", h.Count())
+	}
+	if h.Min() != 500*time.Microsecond || h.Max() != time.Second {
+		t.Errorf("unexpected min/max: %v, %v", h.Min(), h.Max())
+	}
+
+	snapshot := h.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	counts := []int64{1, 2, 1}
+	for i, bucket := range snapshot {
+		if bucket.Count != counts[i] {
+			t.Errorf("bucket %v: expected count %v, got: %v",
+				i, counts[i], bucket.Count)
+		}
+	}
+}
+
+func TestEnvelopeLatency(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	// The event timestamp predates the send time by the queueing
+	// delay; latency is measured from the send time.
+	e := &MessageEnvelope{
+		Timestamp: sendTime.Add(-time.Millisecond),
+		SendTime:  sendTime,
+	}
+	if _, ok := e.Latency(); ok {
+		t.Error("expected no latency without a receipt time")
+	}
+
+	e.ReceiptTime = sendTime.Add(3 * time.Millisecond)
+	latency, ok := e.Latency()
+	if !ok || latency != 3*time.Millisecond {
+		t.Errorf("unexpected latency: %v (ok=%v)", latency, ok)
+	}
+
+	h := NewLatencyHistogram()
+	h.ObserveEnvelope(e)
+	if h.Count() != 1 {
+		t.Errorf("unexpected count: %v", h.Count())
+	}
+}
+
+func TestEnvelopeLatency_NoSendTime(t *testing.T) {
+	eventTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	// Without a send time, the message timestamp is the best
+	// available baseline.
+	e := &MessageEnvelope{
+		Timestamp:   eventTime,
+		ReceiptTime: eventTime.Add(2 * time.Millisecond),
+	}
+	latency, ok := e.Latency()
+	if !ok || latency != 2*time.Millisecond {
+		t.Errorf("unexpected latency: %v (ok=%v)", latency, ok)
+	}
+}
@@ -0,0 +1,164 @@
+// Package liquidity contains building blocks for liquidity studies
+// derived from DEEP price level updates.
+package liquidity
+
+import (
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+// EventType classifies a liquidity event at a price level.
+type EventType string
+
+const (
+	// The best bid/ask level was fully depleted.
+	Depleted EventType = "DEPLETED"
+	// A previously depleted best level regained size within the
+	// detection window.
+	Replenished EventType = "REPLENISHED"
+)
+
+// Event is a depletion or replenishment of a best bid/ask level.
+type Event struct {
+	Symbol string    `json:"symbol"`
+	Side   string    `json:"side"` // "bid" or "ask"
+	Price  float64   `json:"price"`
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	// For replenishments, the time elapsed since the depletion.
+	After time.Duration `json:"after,omitempty"`
+}
+
+// DefaultReplenishWindow is the window within which a depleted level
+// regaining size counts as a replenishment.
+const DefaultReplenishWindow = 5 * time.Second
+
+// levelKey identifies one price level of one side of one book.
+type levelKey struct {
+	symbol string
+	buy    bool
+	price  float64
+}
+
+// Detector emits an event when the best bid or ask level of a symbol
+// is fully depleted, and when a depleted level regains size within
+// the configured window. Feed it the full DEEP stream; messages other
+// than price level updates are ignored.
+type Detector struct {
+	window    time.Duration
+	levels    map[levelKey]uint32
+	best      map[string]map[bool]float64
+	depleted  map[levelKey]time.Time
+	callbacks []func(Event)
+}
+
+// NewDetector creates a detector with the given replenishment window.
+func NewDetector(window time.Duration) *Detector {
+	return &Detector{
+		window:   window,
+		levels:   make(map[levelKey]uint32),
+		best:     make(map[string]map[bool]float64),
+		depleted: make(map[levelKey]time.Time),
+	}
+}
+
+// AddCallback registers a callback invoked for each event.
+func (d *Detector) AddCallback(cb func(Event)) {
+	d.callbacks = append(d.callbacks, cb)
+}
+
+// OnMessage folds one decoded message into the detector.
+func (d *Detector) OnMessage(msg iextp.Message) {
+	update, ok := msg.(*deep.PriceLevelUpdateMessage)
+	if !ok {
+		return
+	}
+
+	buy := update.IsBuySide()
+	key := levelKey{update.Symbol, buy, update.Price}
+	wasBest := d.isBest(update.Symbol, buy, update.Price)
+
+	if update.Size == 0 {
+		delete(d.levels, key)
+		if wasBest {
+			d.depleted[key] = update.Timestamp
+			d.emit(Event{
+				Symbol: update.Symbol,
+				Side:   sideName(buy),
+				Price:  update.Price,
+				Type:   Depleted,
+				Time:   update.Timestamp,
+			})
+		}
+	} else {
+		_, hadSize := d.levels[key]
+		d.levels[key] = update.Size
+		if depletedAt, ok := d.depleted[key]; ok && !hadSize {
+			delete(d.depleted, key)
+			if after := update.Timestamp.Sub(depletedAt); after <= d.window {
+				d.emit(Event{
+					Symbol: update.Symbol,
+					Side:   sideName(buy),
+					Price:  update.Price,
+					Type:   Replenished,
+					Time:   update.Timestamp,
+					After:  after,
+				})
+			}
+		}
+	}
+
+	d.updateBest(update.Symbol, buy)
+}
+
+// isBest returns whether the given price is currently the best level
+// on its side of the book.
+func (d *Detector) isBest(symbol string, buy bool, price float64) bool {
+	sides, ok := d.best[symbol]
+	if !ok {
+		return false
+	}
+	best, ok := sides[buy]
+	return ok && best == price
+}
+
+// updateBest recomputes the best level on one side of one book.
+func (d *Detector) updateBest(symbol string, buy bool) {
+	var best float64
+	found := false
+	for key, size := range d.levels {
+		if key.symbol != symbol || key.buy != buy || size == 0 {
+			continue
+		}
+		if !found || (buy && key.price > best) || (!buy && key.price < best) {
+			best = key.price
+			found = true
+		}
+	}
+
+	sides, ok := d.best[symbol]
+	if !ok {
+		sides = make(map[bool]float64)
+		d.best[symbol] = sides
+	}
+	if found {
+		sides[buy] = best
+	} else {
+		delete(sides, buy)
+	}
+}
+
+func (d *Detector) emit(event Event) {
+	for _, cb := range d.callbacks {
+		cb(event)
+	}
+}
+
+func sideName(buy bool) string {
+	if buy {
+		return "bid"
+	}
+	return "ask"
+}
@@ -0,0 +1,79 @@
+package liquidity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+func priceLevelUpdate(symbol string, buy bool, price float64, size uint32, ts time.Time) *deep.PriceLevelUpdateMessage {
+	messageType := uint8(deep.PriceLevelUpdateSellSide)
+	if buy {
+		messageType = deep.PriceLevelUpdateBuySide
+	}
+
+	return &deep.PriceLevelUpdateMessage{
+		MessageType: messageType,
+		Symbol:      symbol,
+		Price:       price,
+		Size:        size,
+		Timestamp:   ts,
+	}
+}
+
+func TestDetector(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	d := NewDetector(DefaultReplenishWindow)
+	var events []Event
+	d.AddCallback(func(e Event) { events = append(events, e) })
+
+	// Build a two-level bid book.
+	d.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 100, ts))
+	d.OnMessage(priceLevelUpdate("ZIEXT", true, 98.95, 200, ts))
+	if len(events) != 0 {
+		t.Fatalf("unexpected events while building book: %+v", events)
+	}
+
+	// Depleting the non-best level is not an event.
+	d.OnMessage(priceLevelUpdate("ZIEXT", true, 98.95, 0, ts.Add(time.Second)))
+	if len(events) != 0 {
+		t.Fatalf("unexpected events for non-best depletion: %+v", events)
+	}
+
+	// Depleting the best level is.
+	d.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 0, ts.Add(2*time.Second)))
+	if len(events) != 1 {
+		t.Fatalf("expected depletion event, got: %+v", events)
+	}
+	if events[0].Type != Depleted || events[0].Side != "bid" || events[0].Price != 99.00 {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+
+	// Size returning within the window is a replenishment.
+	d.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 50, ts.Add(3*time.Second)))
+	if len(events) != 2 {
+		t.Fatalf("expected replenishment event, got: %+v", events)
+	}
+	if events[1].Type != Replenished || events[1].After != time.Second {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+}
+
+func TestDetector_WindowExpired(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	d := NewDetector(time.Second)
+	var events []Event
+	d.AddCallback(func(e Event) { events = append(events, e) })
+
+	d.OnMessage(priceLevelUpdate("ZIEXT", false, 99.10, 100, ts))
+	d.OnMessage(priceLevelUpdate("ZIEXT", false, 99.10, 0, ts.Add(time.Second)))
+	// Size returns, but too late to count as a replenishment.
+	d.OnMessage(priceLevelUpdate("ZIEXT", false, 99.10, 100, ts.Add(10*time.Second)))
+
+	if len(events) != 1 || events[0].Type != Depleted || events[0].Side != "ask" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
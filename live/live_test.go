@@ -0,0 +1,128 @@
+package live
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// makeSegment builds a raw IEX-TP segment with the given send time
+// and message blocks.
+func makeSegment(sendTime time.Time, messages ...[]byte) []byte {
+	payloadLength := 0
+	for _, msg := range messages {
+		payloadLength += 2 + len(msg)
+	}
+
+	segment := make([]byte, 40, 40+payloadLength)
+	segment[0] = 1 // Version
+	binary.LittleEndian.PutUint16(segment[2:4], 0x8003)
+	binary.LittleEndian.PutUint32(segment[8:12], 42) // SessionID
+	binary.LittleEndian.PutUint16(segment[12:14], uint16(payloadLength))
+	binary.LittleEndian.PutUint16(segment[14:16], uint16(len(messages)))
+	binary.LittleEndian.PutUint64(segment[32:40], uint64(sendTime.UnixNano()))
+
+	for _, msg := range messages {
+		var length [2]byte
+		binary.LittleEndian.PutUint16(length[:], uint16(len(msg)))
+		segment = append(segment, length[:]...)
+		segment = append(segment, msg...)
+	}
+
+	return segment
+}
+
+// listenLoopback joins a test group on the loopback interface, or
+// skips on hosts where loopback multicast is unavailable.
+func listenLoopback(t *testing.T, addr string) (*Listener, *net.UDPConn) {
+	t.Helper()
+
+	listener, err := Listen(Config{Addr: addr, Interface: "lo"})
+	if err != nil {
+		t.Skipf("loopback multicast unavailable: %v", err)
+	}
+
+	gaddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := net.DialUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, gaddr)
+	if err != nil {
+		listener.Close()
+		t.Skipf("loopback multicast unavailable: %v", err)
+	}
+
+	return listener, sender
+}
+
+func TestListener(t *testing.T) {
+	listener, sender := listenLoopback(t, "239.255.21.40:18400")
+	defer listener.Close()
+	defer sender.Close()
+
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{0x53, 0x45, 0, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := sender.Write(makeSegment(sendTime, systemEvent)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := listener.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := msg.(*tops.SystemEventMessage); !ok {
+		t.Fatalf("expected a SystemEventMessage, got: %T", msg)
+	}
+
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listener.NextMessage(); err != iex.ErrScanStopped {
+		t.Errorf("expected ErrScanStopped after Close, got: %v", err)
+	}
+}
+
+func TestListenerStream(t *testing.T) {
+	listener, sender := listenLoopback(t, "239.255.21.41:18401")
+	defer listener.Close()
+	defer sender.Close()
+
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{0x53, 0x45, 0, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := sender.Write(makeSegment(sendTime, systemEvent)); err != nil {
+		t.Fatal(err)
+	}
+
+	envelopes := listener.Stream(16)
+	envelope := <-envelopes
+	if _, ok := envelope.Message.(*tops.SystemEventMessage); !ok {
+		t.Fatalf("expected a SystemEventMessage, got: %T", envelope.Message)
+	}
+	if envelope.ReceiptTime.IsZero() {
+		t.Error("expected a receipt time on live envelopes")
+	}
+
+	listener.Close()
+	if _, ok := <-envelopes; ok {
+		t.Error("expected channel closed after Close")
+	}
+	if listener.Err() != iex.ErrScanStopped {
+		t.Errorf("expected ErrScanStopped, got: %v", listener.Err())
+	}
+}
+
+func TestListen_BadConfig(t *testing.T) {
+	if _, err := Listen(Config{Addr: "not an address"}); err == nil {
+		t.Error("expected an error for a malformed address")
+	}
+	if _, err := Listen(Config{
+		Addr:      "239.255.21.42:18402",
+		Interface: "no-such-interface",
+	}); err == nil {
+		t.Error("expected an error for an unknown interface")
+	}
+}
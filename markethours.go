@@ -0,0 +1,94 @@
+package iex
+
+import (
+	"sync"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Regular market hours for IEX, in the exchange's local time zone.
+const (
+	marketOpenHour    = 9
+	marketOpenMinute  = 30
+	marketCloseHour   = 16
+	marketCloseMinute = 0
+)
+
+// marketLocation is the exchange's local time zone. It falls back to
+// a fixed Eastern offset if the system has no time zone database.
+var marketLocation = func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}()
+
+// IsMarketOpen returns whether the given time falls within regular
+// market hours: 9:30-16:00 Eastern, Monday through Friday. It does
+// not account for exchange holidays; for a live feed, MarketHours
+// additionally tracks system-event messages, which do.
+func IsMarketOpen(now time.Time) bool {
+	now = now.In(marketLocation)
+	switch now.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+
+	open := time.Date(now.Year(), now.Month(), now.Day(),
+		marketOpenHour, marketOpenMinute, 0, 0, marketLocation)
+	close := time.Date(now.Year(), now.Month(), now.Day(),
+		marketCloseHour, marketCloseMinute, 0, 0, marketLocation)
+	return !now.Before(open) && now.Before(close)
+}
+
+// MarketHours tracks whether the market is open, combining the
+// regular trading calendar with system-event messages from the feed.
+// When a system event has been seen for the current trading day it
+// takes precedence over the calendar, so feed-driven consumers handle
+// holidays and irregular sessions correctly.
+type MarketHours struct {
+	mu sync.Mutex
+	// Most recent system event, and the day it was observed.
+	lastEvent     uint8
+	lastEventTime time.Time
+	haveEvent     bool
+}
+
+// NewMarketHours creates a MarketHours with no system events observed.
+func NewMarketHours() *MarketHours {
+	return &MarketHours{}
+}
+
+// OnSystemEvent records a system event message from the feed.
+func (h *MarketHours) OnSystemEvent(msg *tops.SystemEventMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastEvent = msg.SystemEvent
+	h.lastEventTime = msg.Timestamp
+	h.haveEvent = true
+}
+
+// IsOpen returns whether the market is open at the given time. If a
+// system event has been observed for the same trading day, it is
+// authoritative; otherwise the regular calendar is used.
+func (h *MarketHours) IsOpen(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.haveEvent && sameMarketDay(h.lastEventTime, now) {
+		return h.lastEvent == tops.StartOfRegularMarketHours
+	}
+
+	return IsMarketOpen(now)
+}
+
+// sameMarketDay returns whether a and b fall on the same calendar day
+// in the exchange's local time zone.
+func sameMarketDay(a, b time.Time) bool {
+	a, b = a.In(marketLocation), b.In(marketLocation)
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,70 @@
+package iex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestIsMarketOpen(t *testing.T) {
+	cases := []struct {
+		now  time.Time
+		open bool
+	}{
+		// Wednesday, mid-session.
+		{time.Date(2017, time.April, 19, 12, 0, 0, 0, marketLocation), true},
+		// Wednesday, moment of the open.
+		{time.Date(2017, time.April, 19, 9, 30, 0, 0, marketLocation), true},
+		// Wednesday, before the open.
+		{time.Date(2017, time.April, 19, 9, 29, 59, 0, marketLocation), false},
+		// Wednesday, moment of the close.
+		{time.Date(2017, time.April, 19, 16, 0, 0, 0, marketLocation), false},
+		// Saturday.
+		{time.Date(2017, time.April, 22, 12, 0, 0, 0, marketLocation), false},
+	}
+
+	for _, tc := range cases {
+		if open := IsMarketOpen(tc.now); open != tc.open {
+			t.Errorf("IsMarketOpen(%v): expected %v, got: %v",
+				tc.now, tc.open, open)
+		}
+	}
+}
+
+func TestMarketHours_SystemEvents(t *testing.T) {
+	noon := time.Date(2017, time.April, 19, 12, 0, 0, 0, marketLocation)
+
+	h := NewMarketHours()
+	// No events yet: fall back to the calendar.
+	if !h.IsOpen(noon) {
+		t.Error("expected open from calendar")
+	}
+
+	// An early close reported by the feed overrides the calendar.
+	h.OnSystemEvent(&tops.SystemEventMessage{
+		SystemEvent: tops.EndOfRegularMarketHours,
+		Timestamp:   noon.Add(-time.Hour),
+	})
+	if h.IsOpen(noon) {
+		t.Error("expected closed after end-of-market-hours event")
+	}
+
+	h.OnSystemEvent(&tops.SystemEventMessage{
+		SystemEvent: tops.StartOfRegularMarketHours,
+		Timestamp:   noon.Add(-time.Minute),
+	})
+	if !h.IsOpen(noon) {
+		t.Error("expected open after start-of-market-hours event")
+	}
+
+	// Events from a previous day are stale: back to the calendar,
+	// which says Thursday noon is open.
+	h.OnSystemEvent(&tops.SystemEventMessage{
+		SystemEvent: tops.EndOfRegularMarketHours,
+		Timestamp:   noon,
+	})
+	if !h.IsOpen(noon.Add(24 * time.Hour)) {
+		t.Error("expected stale event to be ignored on the next day")
+	}
+}
@@ -0,0 +1,57 @@
+package iex
+
+import (
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// FeedMetadata summarizes the IEX-TP segment headers observed while
+// processing a feed, so that datasets extracted from pcap dumps can be
+// made self-describing.
+type FeedMetadata struct {
+	// The name of the feed, e.g. "TOPS" or "DEEP", derived from the
+	// message protocol ID.
+	Feed string `json:"feed"`
+	// The IEX-TP message protocol ID of the feed.
+	MessageProtocolID uint16 `json:"messageProtocolId"`
+	// The version of the IEX-TP protocol.
+	ProtocolVersion uint8 `json:"protocolVersion"`
+	// The session ID of the feed.
+	SessionID uint32 `json:"sessionId"`
+	// The number of segments observed, including heartbeats.
+	Segments int64 `json:"segments"`
+	// The total number of messages observed.
+	Messages int64 `json:"messages"`
+	// The send times of the first and last segments observed.
+	FirstSendTime time.Time `json:"firstSendTime"`
+	LastSendTime  time.Time `json:"lastSendTime"`
+}
+
+// Update folds the given segment header into the metadata.
+func (m *FeedMetadata) Update(header iextp.SegmentHeader) {
+	if m.Segments == 0 {
+		m.Feed = feedName(header.MessageProtocolID)
+		m.MessageProtocolID = header.MessageProtocolID
+		m.ProtocolVersion = header.Version
+		m.SessionID = header.SessionID
+		m.FirstSendTime = header.SendTime
+	}
+
+	m.Segments++
+	m.Messages += int64(header.MessageCount)
+	m.LastSendTime = header.SendTime
+}
+
+func feedName(messageProtocolID uint16) string {
+	switch messageProtocolID {
+	case tops.V_1_5_MessageProtocolID, tops.V_1_6_MessageProtocolID:
+		return tops.FeedName
+	case deep.V_1_0_MessageProtocolID:
+		return deep.FeedName
+	default:
+		return ""
+	}
+}
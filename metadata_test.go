@@ -0,0 +1,53 @@
+package iex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestFeedMetadataUpdate(t *testing.T) {
+	t0 := time.Date(2016, time.August, 23, 19, 30, 32, 0, time.UTC)
+	header := iextp.SegmentHeader{
+		Version:           1,
+		MessageProtocolID: tops.V_1_6_MessageProtocolID,
+		ChannelID:         1,
+		SessionID:         1116143616,
+		MessageCount:      2,
+		SendTime:          t0,
+	}
+
+	var metadata FeedMetadata
+	metadata.Update(header)
+
+	// A heartbeat segment: no messages, but a later send time.
+	heartbeat := header
+	heartbeat.MessageCount = 0
+	heartbeat.SendTime = t0.Add(time.Second)
+	metadata.Update(heartbeat)
+
+	if metadata.Feed != tops.FeedName {
+		t.Errorf("expected feed %v, got: %v", tops.FeedName, metadata.Feed)
+	}
+	if metadata.ProtocolVersion != 1 {
+		t.Errorf("expected protocol version 1, got: %v", metadata.ProtocolVersion)
+	}
+	if metadata.SessionID != 1116143616 {
+		t.Errorf("expected session ID 1116143616, got: %v", metadata.SessionID)
+	}
+	if metadata.Segments != 2 {
+		t.Errorf("expected 2 segments, got: %v", metadata.Segments)
+	}
+	if metadata.Messages != 2 {
+		t.Errorf("expected 2 messages, got: %v", metadata.Messages)
+	}
+	if !metadata.FirstSendTime.Equal(t0) {
+		t.Errorf("expected first send time %v, got: %v", t0, metadata.FirstSendTime)
+	}
+	if !metadata.LastSendTime.Equal(t0.Add(time.Second)) {
+		t.Errorf("expected last send time %v, got: %v",
+			t0.Add(time.Second), metadata.LastSendTime)
+	}
+}
@@ -0,0 +1,229 @@
+// Package orderbook reconstructs per-symbol limit order books from
+// DEEP price level updates.
+//
+// Feed the decoded messages of a PcapScanner (or a live feed) to
+// Books.OnMessage and query the per-symbol Book for the best bid and
+// offer, aggregated depth, or a serializable snapshot — the
+// state-tracking boilerplate of applying add/update/remove events in
+// the right order is handled here.
+package orderbook
+
+import (
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+// Level is one aggregated price level of the book.
+type Level struct {
+	Price float64 `json:"price"`
+	Size  uint32  `json:"size"`
+}
+
+// Snapshot is a serializable point-in-time view of one symbol's book.
+// Bids are ordered best (highest) first, asks best (lowest) first.
+type Snapshot struct {
+	Symbol string `json:"symbol"`
+	// The event time of the last update applied to the book.
+	Timestamp time.Time `json:"timestamp"`
+	Bids      []Level   `json:"bids"`
+	Asks      []Level   `json:"asks"`
+}
+
+// Book is the limit order book for a single symbol. Updates arrive
+// through Books.OnMessage; queries are valid at any point, but see
+// Consistent for event-boundary semantics.
+type Book struct {
+	symbol string
+	// Both sides are stored ascending by price: the best bid is the
+	// last entry, the best ask the first.
+	bids []Level
+	asks []Level
+
+	updated time.Time
+	// Whether the last update applied had its event-processing-
+	// complete flag set, i.e. the book is not mid-event.
+	consistent bool
+	// Monotonic use counter, for least-recently-used spilling.
+	lastUsed int64
+}
+
+// Symbol returns the symbol the book tracks.
+func (b *Book) Symbol() string {
+	return b.symbol
+}
+
+// Updated returns the event time of the last update applied.
+func (b *Book) Updated() time.Time {
+	return b.updated
+}
+
+// Consistent reports whether the book is at an event boundary. DEEP
+// groups related updates into events; mid-event, one side of the book
+// may have been updated and not yet the other (e.g. a crossing order
+// removing levels from both sides). Consumers that require
+// event-atomic views should wait for Consistent before reading.
+func (b *Book) Consistent() bool {
+	return b.consistent
+}
+
+// BestBid returns the highest bid level, if the bid side is nonempty.
+func (b *Book) BestBid() (Level, bool) {
+	if len(b.bids) == 0 {
+		return Level{}, false
+	}
+
+	return b.bids[len(b.bids)-1], true
+}
+
+// BestAsk returns the lowest ask level, if the ask side is nonempty.
+func (b *Book) BestAsk() (Level, bool) {
+	if len(b.asks) == 0 {
+		return Level{}, false
+	}
+
+	return b.asks[0], true
+}
+
+// Depth returns up to n levels of each side, bids best (highest)
+// first and asks best (lowest) first. A non-positive n returns full
+// depth.
+func (b *Book) Depth(n int) (bids, asks []Level) {
+	nBids, nAsks := len(b.bids), len(b.asks)
+	if n > 0 {
+		nBids = min(nBids, n)
+		nAsks = min(nAsks, n)
+	}
+
+	bids = make([]Level, nBids)
+	for i := 0; i < nBids; i++ {
+		bids[i] = b.bids[len(b.bids)-1-i]
+	}
+	asks = append(asks, b.asks[:nAsks]...)
+	return bids, asks
+}
+
+// Snapshot returns a serializable full-depth view of the book.
+func (b *Book) Snapshot() Snapshot {
+	bids, asks := b.Depth(0)
+	return Snapshot{
+		Symbol:    b.symbol,
+		Timestamp: b.updated,
+		Bids:      bids,
+		Asks:      asks,
+	}
+}
+
+// apply sets or removes one price level.
+func (b *Book) apply(msg *deep.PriceLevelUpdateMessage) {
+	side := &b.asks
+	if msg.IsBuySide() {
+		side = &b.bids
+	}
+
+	levels := *side
+	i := sort.Search(len(levels), func(i int) bool {
+		return levels[i].Price >= msg.Price
+	})
+
+	switch {
+	case i < len(levels) && levels[i].Price == msg.Price:
+		if msg.Size == 0 {
+			*side = append(levels[:i], levels[i+1:]...)
+		} else {
+			levels[i].Size = msg.Size
+		}
+	case msg.Size != 0:
+		levels = append(levels, Level{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = Level{Price: msg.Price, Size: msg.Size}
+		*side = levels
+	}
+
+	b.updated = msg.Timestamp
+	b.consistent = msg.EventProcessingComplete()
+}
+
+// Books consumes DEEP messages and maintains one Book per symbol.
+// Other message types are ignored, so a full feed can be piped
+// through unfiltered.
+type Books struct {
+	books map[string]*Book
+	// Spill-to-disk state; see SetSpill.
+	spillDir    string
+	maxResident int
+	spilled     map[string]bool
+	seq         int64
+	err         error
+}
+
+// NewBooks creates an empty book set.
+func NewBooks() *Books {
+	return &Books{books: make(map[string]*Book)}
+}
+
+// OnMessage applies the given message to its symbol's book.
+func (b *Books) OnMessage(msg iextp.Message) {
+	update, ok := msg.(*deep.PriceLevelUpdateMessage)
+	if !ok {
+		return
+	}
+
+	book := b.lookup(update.Symbol)
+	if book == nil {
+		book = &Book{symbol: update.Symbol, consistent: true}
+		b.books[update.Symbol] = book
+	}
+
+	book.apply(update)
+	b.touch(book)
+}
+
+// Book returns the book for the given symbol, if any updates for it
+// have been seen. A spilled book is reloaded and made resident.
+func (b *Books) Book(symbol string) (*Book, bool) {
+	book := b.lookup(symbol)
+	if book == nil {
+		return nil, false
+	}
+
+	b.touch(book)
+	return book, true
+}
+
+// Symbols returns the symbols with books, resident or spilled,
+// sorted.
+func (b *Books) Symbols() []string {
+	symbols := make([]string, 0, len(b.books)+len(b.spilled))
+	for symbol := range b.books {
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range b.spilled {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Snapshots returns full-depth snapshots of every book, sorted by
+// symbol. Spilled books are read from disk without making them
+// resident.
+func (b *Books) Snapshots() []Snapshot {
+	symbols := b.Symbols()
+	snapshots := make([]Snapshot, 0, len(symbols))
+	for _, symbol := range symbols {
+		book, ok := b.books[symbol]
+		if !ok {
+			loaded, err := b.loadBook(symbol)
+			if err != nil {
+				b.setErr(err)
+				continue
+			}
+			book = loaded
+		}
+		snapshots = append(snapshots, book.Snapshot())
+	}
+	return snapshots
+}
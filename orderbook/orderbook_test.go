@@ -0,0 +1,119 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+var t0 = time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+func levelUpdate(symbol string, buy bool, price float64, size uint32) *deep.PriceLevelUpdateMessage {
+	messageType := uint8(deep.PriceLevelUpdateSellSide)
+	if buy {
+		messageType = deep.PriceLevelUpdateBuySide
+	}
+
+	return &deep.PriceLevelUpdateMessage{
+		MessageType: messageType,
+		EventFlags:  0x1, // Event processing complete.
+		Timestamp:   t0,
+		Symbol:      symbol,
+		Size:        size,
+		Price:       price,
+	}
+}
+
+func TestBooks(t *testing.T) {
+	books := NewBooks()
+	books.OnMessage(levelUpdate("SNAP", true, 20.05, 100))
+	books.OnMessage(levelUpdate("SNAP", true, 20.10, 200))
+	books.OnMessage(levelUpdate("SNAP", true, 20.00, 300))
+	books.OnMessage(levelUpdate("SNAP", false, 20.15, 400))
+	books.OnMessage(levelUpdate("SNAP", false, 20.20, 500))
+	books.OnMessage(levelUpdate("FB", true, 140.00, 50))
+	// Non-DEEP messages are ignored.
+	books.OnMessage(&deep.TradeReportMessage{Symbol: "SNAP"})
+
+	book, ok := books.Book("SNAP")
+	if !ok {
+		t.Fatal("expected a SNAP book")
+	}
+
+	bid, ok := book.BestBid()
+	if !ok || bid.Price != 20.10 || bid.Size != 200 {
+		t.Errorf("unexpected best bid: %+v", bid)
+	}
+	ask, ok := book.BestAsk()
+	if !ok || ask.Price != 20.15 || ask.Size != 400 {
+		t.Errorf("unexpected best ask: %+v", ask)
+	}
+
+	bids, asks := book.Depth(2)
+	if len(bids) != 2 || bids[0].Price != 20.10 || bids[1].Price != 20.05 {
+		t.Errorf("unexpected bid depth: %+v", bids)
+	}
+	if len(asks) != 2 || asks[0].Price != 20.15 || asks[1].Price != 20.20 {
+		t.Errorf("unexpected ask depth: %+v", asks)
+	}
+
+	// A size-zero update removes the level.
+	books.OnMessage(levelUpdate("SNAP", true, 20.10, 0))
+	bid, _ = book.BestBid()
+	if bid.Price != 20.05 {
+		t.Errorf("unexpected best bid after removal: %+v", bid)
+	}
+
+	// An update to an existing level replaces the size.
+	books.OnMessage(levelUpdate("SNAP", false, 20.15, 150))
+	ask, _ = book.BestAsk()
+	if ask.Size != 150 {
+		t.Errorf("unexpected best ask after update: %+v", ask)
+	}
+
+	if got := books.Symbols(); len(got) != 2 || got[0] != "FB" || got[1] != "SNAP" {
+		t.Errorf("unexpected symbols: %v", got)
+	}
+}
+
+func TestBook_Consistent(t *testing.T) {
+	books := NewBooks()
+
+	midEvent := levelUpdate("SNAP", true, 20.05, 100)
+	midEvent.EventFlags = 0
+	books.OnMessage(midEvent)
+
+	book, _ := books.Book("SNAP")
+	if book.Consistent() {
+		t.Error("expected the book to be mid-event")
+	}
+
+	books.OnMessage(levelUpdate("SNAP", false, 20.15, 100))
+	if !book.Consistent() {
+		t.Error("expected the book to be consistent at the event boundary")
+	}
+}
+
+func TestBook_Snapshot(t *testing.T) {
+	books := NewBooks()
+	books.OnMessage(levelUpdate("SNAP", true, 20.05, 100))
+	books.OnMessage(levelUpdate("SNAP", false, 20.15, 400))
+
+	snapshots := books.Snapshots()
+	if len(snapshots) != 1 {
+		t.Fatalf("expected 1 snapshot, got: %v", len(snapshots))
+	}
+
+	data, err := json.Marshal(snapshots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"symbol":"SNAP","timestamp":"2017-04-17T09:30:00Z",` +
+		`"bids":[{"price":20.05,"size":100}],` +
+		`"asks":[{"price":20.15,"size":400}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected snapshot: %s", data)
+	}
+}
@@ -0,0 +1,144 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SetSpill bounds how many books are resident in memory at once.
+// When the bound is exceeded, the least-recently-updated book's state
+// is written to <SYMBOL>.book.json in the given directory and dropped
+// from memory; it is reloaded transparently when its symbol next
+// appears. Full-market replays (8000+ symbols) can thereby run with
+// only the actively-trading books resident. Spill I/O errors are
+// sticky and reported by Err.
+func (b *Books) SetSpill(dir string, maxResident int) {
+	b.spillDir = dir
+	b.maxResident = maxResident
+	if b.spilled == nil {
+		b.spilled = make(map[string]bool)
+	}
+}
+
+// Err returns the first error encountered spilling or reloading book
+// state, if any. Once an error has occurred, affected books may be
+// incomplete.
+func (b *Books) Err() error {
+	return b.err
+}
+
+// bookState is the serialized at-rest form of one book. Both sides
+// are stored ascending by price, as in memory.
+type bookState struct {
+	Symbol     string    `json:"symbol"`
+	Updated    time.Time `json:"updated"`
+	Consistent bool      `json:"consistent"`
+	Bids       []Level   `json:"bids,omitempty"`
+	Asks       []Level   `json:"asks,omitempty"`
+}
+
+// lookup returns the resident book for the symbol, reloading it from
+// disk if it was spilled, or nil if the symbol has not been seen.
+func (b *Books) lookup(symbol string) *Book {
+	if book, ok := b.books[symbol]; ok {
+		return book
+	}
+
+	if !b.spilled[symbol] {
+		return nil
+	}
+
+	book, err := b.loadBook(symbol)
+	if err != nil {
+		b.setErr(err)
+		return nil
+	}
+
+	delete(b.spilled, symbol)
+	os.Remove(b.spillPath(symbol))
+	b.books[symbol] = book
+	return book
+}
+
+// touch marks the book as most recently used and spills the
+// least-recently-used books if the resident bound is exceeded.
+func (b *Books) touch(book *Book) {
+	b.seq++
+	book.lastUsed = b.seq
+
+	if b.maxResident <= 0 {
+		return
+	}
+	for len(b.books) > b.maxResident {
+		if !b.evict() {
+			return
+		}
+	}
+}
+
+// evict spills the least-recently-used resident book to disk,
+// reporting whether one was evicted.
+func (b *Books) evict() bool {
+	var victim *Book
+	for _, book := range b.books {
+		if victim == nil || book.lastUsed < victim.lastUsed {
+			victim = book
+		}
+	}
+	if victim == nil {
+		return false
+	}
+
+	state := bookState{
+		Symbol:     victim.symbol,
+		Updated:    victim.updated,
+		Consistent: victim.consistent,
+		Bids:       victim.bids,
+		Asks:       victim.asks,
+	}
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = os.WriteFile(b.spillPath(victim.symbol), data, 0644)
+	}
+	if err != nil {
+		b.setErr(err)
+		return false
+	}
+
+	delete(b.books, victim.symbol)
+	b.spilled[victim.symbol] = true
+	return true
+}
+
+// loadBook reads a spilled book's state back from disk.
+func (b *Books) loadBook(symbol string) (*Book, error) {
+	data, err := os.ReadFile(b.spillPath(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	var state bookState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &Book{
+		symbol:     state.Symbol,
+		bids:       state.Bids,
+		asks:       state.Asks,
+		updated:    state.Updated,
+		consistent: state.Consistent,
+	}, nil
+}
+
+func (b *Books) spillPath(symbol string) string {
+	return filepath.Join(b.spillDir, symbol+".book.json")
+}
+
+func (b *Books) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
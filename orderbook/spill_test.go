@@ -0,0 +1,68 @@
+package orderbook
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBooks_Spill(t *testing.T) {
+	dir := t.TempDir()
+	books := NewBooks()
+	books.SetSpill(dir, 2)
+
+	symbols := []string{"SNAP", "FB", "AAPL", "AMD"}
+	for i, symbol := range symbols {
+		books.OnMessage(levelUpdate(symbol, true, 20+float64(i), 100))
+		books.OnMessage(levelUpdate(symbol, false, 21+float64(i), 200))
+	}
+	if err := books.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(books.books) != 2 {
+		t.Errorf("expected 2 resident books, got: %v", len(books.books))
+	}
+	// The least-recently-updated books were spilled to disk.
+	for _, symbol := range symbols[:2] {
+		if _, err := os.Stat(dir + "/" + symbol + ".book.json"); err != nil {
+			t.Errorf("expected a spill file for %v: %v", symbol, err)
+		}
+	}
+
+	// All symbols are still visible, and snapshots read spilled books
+	// without making them resident.
+	if got := books.Symbols(); len(got) != len(symbols) {
+		t.Errorf("unexpected symbols: %v", got)
+	}
+	snapshots := books.Snapshots()
+	if len(snapshots) != len(symbols) {
+		t.Fatalf("expected %v snapshots, got: %v", len(symbols), len(snapshots))
+	}
+	if len(books.books) != 2 {
+		t.Errorf("snapshots should not change residency, resident: %v",
+			len(books.books))
+	}
+
+	// Reading a spilled book reloads it transparently.
+	book, ok := books.Book("SNAP")
+	if !ok {
+		t.Fatal("expected the spilled SNAP book to reload")
+	}
+	bid, ok := book.BestBid()
+	if !ok || bid.Price != 20 || bid.Size != 100 {
+		t.Errorf("unexpected best bid after reload: %+v", bid)
+	}
+	if !book.Consistent() {
+		t.Error("expected the reloaded book to be consistent")
+	}
+
+	// Updates to a reloaded book keep applying.
+	books.OnMessage(levelUpdate("SNAP", true, 20.50, 300))
+	bid, _ = book.BestBid()
+	if bid.Price != 20.50 {
+		t.Errorf("unexpected best bid after update: %+v", bid)
+	}
+	if err := books.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
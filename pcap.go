@@ -1,30 +1,26 @@
 package iex
 
 import (
-	"bufio"
-	"compress/gzip"
-	"encoding/binary"
-	"io"
+	"errors"
 	"net"
-
-	"github.com/google/gopacket"
-	"github.com/google/gopacket/pcapgo"
+	"time"
 
 	"github.com/timpalpant/go-iex/iextp"
 	_ "github.com/timpalpant/go-iex/iextp/deep"
+	_ "github.com/timpalpant/go-iex/iextp/deepplus"
 	_ "github.com/timpalpant/go-iex/iextp/tops"
 )
 
-const (
-	magicGzip1         = 0x1f
-	magicGzip2         = 0x8b
-	pcapNGMagic uint32 = 0x0A0D0D0A
+const maxDatagramSize = 65536
 
-	maxDatagramSize = 65536
-)
+// ErrScanStopped is returned by NextMessage and NextMessageEnvelope
+// after the scanner is stopped.
+var ErrScanStopped = errors.New("pcap scan stopped")
 
 // PacketDataSource represents a source of decoded network packets
-// from a pcap dump or live network connection.
+// from a pcap dump or live network connection. Implementations backed
+// by pcap/pcap-ng dumps live in the pcapio package, keeping the
+// packet-capture dependencies out of this one.
 type PacketDataSource interface {
 	// NextPayload returns the next decoded packet payload.
 	//
@@ -33,15 +29,13 @@ type PacketDataSource interface {
 	NextPayload() ([]byte, error)
 }
 
-// DEPRECATED: Use NewPacketConnDataSource or NewPcapDataSource.
-func NewPacketDataSource(r io.Reader) (PacketDataSource, error) {
-	// Check for live-streaming packet connection.
-	if conn, ok := r.(net.PacketConn); ok {
-		return NewPacketConnDataSource(conn), nil
-	}
-
-	// Otherwise it must be data from a pcap or pcap-ng dump.
-	return NewPcapDataSource(r)
+// CaptureTimeSource is implemented by packet sources that know each
+// payload's local capture timestamp, such as pcap dump readers. The
+// scanner uses it to stamp envelopes with a CaptureTime.
+type CaptureTimeSource interface {
+	// CaptureTime returns the capture timestamp of the payload most
+	// recently returned by NextPayload.
+	CaptureTime() time.Time
 }
 
 // PacketConnDataSource implements PacketDataSource for live UDP
@@ -66,88 +60,162 @@ func (pcds *PacketConnDataSource) NextPayload() ([]byte, error) {
 	return pcds.buf[:n], err
 }
 
-// GopacketDataSource implements PacketDataSource for gopacket.PacketSource.
-// It can be used to source the packet payload data from a pcap or pcap-ng file.
-type GopacketDataSource struct {
-	packetSource *gopacket.PacketSource
+// PcapScanner is a high-level reader for iterating through messages from
+// from IEX pcap dumps or streaming UDP connections. Its memory use is
+// bounded by the size of a single segment: the decoded segment is
+// reused from packet to packet, so arbitrarily large dumps replay in
+// constant memory.
+type PcapScanner struct {
+	packetSource    PacketDataSource
+	segment         iextp.Segment
+	currentHeader   iextp.SegmentHeader
+	currentSegment  []iextp.Message
+	currentMsgIndex int
+	metadata        FeedMetadata
+	// When non-nil, segments are stamped with a receipt time as they
+	// arrive, enabling latency measurement on envelopes.
+	clock       Clock
+	receiptTime time.Time
+	captureTime time.Time
+	// Callbacks invoked for zero-message (heartbeat) segments.
+	heartbeatCallbacks []func(Heartbeat)
+	// Session rollover tracking.
+	sessionID        uint32
+	haveSession      bool
+	sessionCallbacks []func(SessionChange)
+	// Sequence gap tracking.
+	nextSeq      int64
+	haveNextSeq  bool
+	gaps         []Gap
+	gapCallbacks []func(Gap)
+	// When non-nil, messages for which it returns false are skipped
+	// before decode.
+	rawFilter func(raw []byte) bool
+	// Original wire positions of the current segment's messages,
+	// parallel to currentSegment.
+	currentIndices []uint16
+	// Closed by Stop to abort long scans cleanly.
+	stop chan struct{}
 }
 
-func NewGopacketDataSource(packetSource *gopacket.PacketSource) *GopacketDataSource {
-	return &GopacketDataSource{packetSource}
+// SessionChange describes an IEX-TP session rollover: a new trading
+// day or a system restart. Sequence numbers reset with the session,
+// so stateful consumers (book builders, gap detectors, consolidators)
+// should reset their per-session state when one occurs.
+type SessionChange struct {
+	// The previous and new session identifiers.
+	PreviousSessionID uint32
+	SessionID         uint32
+	// The send time of the first segment of the new session.
+	SendTime time.Time
 }
 
-// Create a new GopacketDataSource from the given pcap or pcap-ng file data.
-func NewPcapDataSource(r io.Reader) (*GopacketDataSource, error) {
-	input := bufio.NewReader(r)
-	gzipMagic, err := input.Peek(2)
-	if err != nil {
-		return nil, err
-	}
+// Gap describes a hole in the IEX-TP sequence: a segment arrived with
+// a first sequence number beyond the one expected, so the messages in
+// between were dropped somewhere upstream. Session rollovers reset the
+// sequence and are reported via OnSessionChange, not as gaps.
+type Gap struct {
+	// The session the gap occurred in.
+	SessionID uint32
+	// The sequence number that was expected next.
+	Expected int64
+	// The first sequence number actually received.
+	Received int64
+	// The number of messages missed: Received - Expected.
+	Missing int64
+	// The send time of the segment following the gap.
+	SendTime time.Time
+}
 
-	if gzipMagic[0] == magicGzip1 && gzipMagic[1] == magicGzip2 {
-		if gzf, err := gzip.NewReader(input); err != nil {
-			return nil, err
-		} else {
-			input = bufio.NewReader(gzf)
-		}
+// Heartbeat describes a zero-message IEX-TP segment. IEX sends them
+// periodically when no messages are flowing, so they mark feed
+// liveness and session boundaries.
+type Heartbeat struct {
+	// The send time of the heartbeat segment.
+	SendTime time.Time
+	// The session the heartbeat belongs to.
+	SessionID uint32
+	// The message protocol of the feed, e.g. TOPS or DEEP.
+	MessageProtocolID uint16
+	// The local time at which the heartbeat was received, if receipt
+	// stamping is enabled with SetClock.
+	ReceiptTime time.Time
+}
+
+// Create a new PcapScanner with the given source of network packets.
+func NewPcapScanner(packetDataSource PacketDataSource) *PcapScanner {
+	return &PcapScanner{
+		packetSource: packetDataSource,
+		stop:         make(chan struct{}),
 	}
+}
 
-	magicBuf, err := input.Peek(4)
-	if err != nil {
-		return nil, err
+// Stop aborts the scan: subsequent NextMessage and NextMessageEnvelope
+// calls return ErrScanStopped, letting long replay loops exit cleanly
+// and flush partial downstream state. Stop does not interrupt a read
+// already blocked in the packet source; live receivers should close
+// the underlying net.PacketConn to unblock it.
+func (p *PcapScanner) Stop() {
+	close(p.stop)
+}
+
+// stopped reports whether Stop has been called.
+func (p *PcapScanner) stopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
 	}
-	magic := binary.LittleEndian.Uint32(magicBuf)
+}
 
-	var packetSource *gopacket.PacketSource
-	if magic == pcapNGMagic {
-		packetReader, err := pcapgo.NewNgReader(input, pcapgo.DefaultNgReaderOptions)
-		if err != nil {
-			return nil, err
-		}
-		packetSource = gopacket.NewPacketSource(packetReader, packetReader.LinkType())
-	} else {
-		packetReader, err := pcapgo.NewReader(input)
-		if err != nil {
-			return nil, err
-		}
-		packetSource = gopacket.NewPacketSource(packetReader, packetReader.LinkType())
+// Get the next Message in the pcap dump.
+// Returns io.EOF if the underlying packet source has no more data.
+func (p *PcapScanner) NextMessage() (iextp.Message, error) {
+	var one [1]iextp.Message
+	n, err := p.NextMessages(one[:])
+	if n == 0 {
+		return nil, err
 	}
 
-	return NewGopacketDataSource(packetSource), nil
+	return one[0], nil
 }
 
-// NextPayload implements PacketDataSource.
-func (gds *GopacketDataSource) NextPayload() ([]byte, error) {
-	for {
-		packet, err := gds.packetSource.NextPacket()
-		if err != nil {
-			return nil, err
-		}
+// NextMessages fills dst with the next messages from the dump,
+// crossing segment boundaries as needed, and returns the number
+// filled. Batch reads amortize the per-message call overhead of tight
+// replay loops. Like io.Reader, it may return messages alongside an
+// error; callers should process the n messages before considering the
+// error, which is io.EOF once the packet source has no more data.
+func (p *PcapScanner) NextMessages(dst []iextp.Message) (int, error) {
+	if p.stopped() {
+		return 0, ErrScanStopped
+	}
 
-		if app := packet.ApplicationLayer(); app != nil {
-			return app.Payload(), nil
+	n := 0
+	for n < len(dst) {
+		if p.currentMsgIndex >= len(p.currentSegment) {
+			if err := p.nextSegment(); err != nil {
+				return n, err
+			}
 		}
+
+		copied := copy(dst[n:], p.currentSegment[p.currentMsgIndex:])
+		n += copied
+		p.currentMsgIndex += copied
 	}
-}
 
-// PcapScanner is a high-level reader for iterating through messages from
-// from IEX pcap dumps or streaming UDP connections.
-type PcapScanner struct {
-	packetSource    PacketDataSource
-	currentSegment  []iextp.Message
-	currentMsgIndex int
+	return n, nil
 }
 
-// Create a new PcapScanner with the given source of network packets.
-func NewPcapScanner(packetDataSource PacketDataSource) *PcapScanner {
-	return &PcapScanner{
-		packetSource: packetDataSource,
+// NextMessageEnvelope returns the next Message wrapped in a
+// MessageEnvelope carrying its deterministic ordering key.
+// Returns io.EOF if the underlying packet source has no more data.
+func (p *PcapScanner) NextMessageEnvelope() (*MessageEnvelope, error) {
+	if p.stopped() {
+		return nil, ErrScanStopped
 	}
-}
 
-// Get the next Message in the pcap dump.
-// Returns io.EOF if the underlying packet source has no more data.
-func (p *PcapScanner) NextMessage() (iextp.Message, error) {
 	for p.currentMsgIndex >= len(p.currentSegment) {
 		if err := p.nextSegment(); err != nil {
 			return nil, err
@@ -155,8 +223,79 @@ func (p *PcapScanner) NextMessage() (iextp.Message, error) {
 	}
 
 	msg := p.currentSegment[p.currentMsgIndex]
+	wireIndex := int(p.currentIndices[p.currentMsgIndex])
+	envelope := &MessageEnvelope{
+		Message:        msg,
+		SequenceNumber: p.currentHeader.FirstMessageSequenceNumber + int64(wireIndex),
+		MessageIndex:   wireIndex,
+	}
+	if ts, ok := MessageTimestamp(msg); ok {
+		envelope.Timestamp = ts
+	} else {
+		envelope.Timestamp = p.currentHeader.SendTime
+	}
+	envelope.ReceiptTime = p.receiptTime
+	envelope.SendTime = p.currentHeader.SendTime
+	envelope.CaptureTime = p.captureTime
+
 	p.currentMsgIndex++
-	return msg, nil
+	return envelope, nil
+}
+
+// SetClock enables receipt-time stamping: each segment is stamped
+// with the clock's current time as it arrives, and the envelopes
+// returned by NextMessageEnvelope carry it as their ReceiptTime. It
+// is intended for live streaming sources; for historical dump
+// replays the arrival time is not meaningful and stamping should be
+// left disabled.
+func (p *PcapScanner) SetClock(clock Clock) {
+	p.clock = clock
+}
+
+// SetRawFilter installs a pre-decode message filter: messages for
+// which keep returns false are skipped before their body is decoded.
+// The filter sees the raw message bytes, so it can only inspect
+// fixed-offset fields like the type byte and symbol (see
+// iextp.PeekMessageType and tops.SymbolFilter); it is a fast path
+// for extractions that discard most of a full-market file.
+func (p *PcapScanner) SetRawFilter(keep func(raw []byte) bool) {
+	p.rawFilter = keep
+}
+
+// OnHeartbeat registers a callback invoked for each heartbeat
+// segment encountered, which message iteration otherwise skips
+// silently. Callbacks run synchronously during NextMessage /
+// NextMessageEnvelope.
+func (p *PcapScanner) OnHeartbeat(cb func(Heartbeat)) {
+	p.heartbeatCallbacks = append(p.heartbeatCallbacks, cb)
+}
+
+// OnSessionChange registers a callback invoked when the feed rolls
+// over to a new session, before any messages of the new session are
+// returned. Long-running live receivers use it to reset per-session
+// state across day boundaries without a manual restart.
+func (p *PcapScanner) OnSessionChange(cb func(SessionChange)) {
+	p.sessionCallbacks = append(p.sessionCallbacks, cb)
+}
+
+// OnGap registers a callback invoked when a sequence gap is detected,
+// before any messages of the post-gap segment are returned. Callbacks
+// run synchronously during NextMessage / NextMessageEnvelope.
+func (p *PcapScanner) OnGap(cb func(Gap)) {
+	p.gapCallbacks = append(p.gapCallbacks, cb)
+}
+
+// Gaps returns the sequence gaps detected so far, in the order they
+// occurred. A HIST replay that finishes with no gaps saw every message
+// IEX sent; it is complete once NextMessage has returned io.EOF.
+func (p *PcapScanner) Gaps() []Gap {
+	return p.gaps
+}
+
+// Metadata returns feed metadata accumulated from the segment headers
+// processed so far. It is complete once NextMessage has returned io.EOF.
+func (p *PcapScanner) Metadata() FeedMetadata {
+	return p.metadata
 }
 
 // Read packets until we find the next one with > 0 messages.
@@ -164,19 +303,85 @@ func (p *PcapScanner) NextMessage() (iextp.Message, error) {
 // or if the payload cannot be decoded as an IEX-TP segment.
 func (p *PcapScanner) nextSegment() error {
 	for {
+		if p.stopped() {
+			return ErrScanStopped
+		}
+
 		payload, err := p.packetSource.NextPayload()
 		if err != nil {
 			return err
 		}
 
-		segment := iextp.Segment{}
-		if err := segment.Unmarshal(payload); err != nil {
+		segment := &p.segment
+		if err := segment.UnmarshalFiltered(payload, p.rawFilter); err != nil {
 			return err
 		}
 
+		// Heartbeat segments carry no messages but still contribute
+		// send times and counts to the feed metadata.
+		p.metadata.Update(segment.Header)
+
+		if p.haveSession && segment.Header.SessionID != p.sessionID {
+			change := SessionChange{
+				PreviousSessionID: p.sessionID,
+				SessionID:         segment.Header.SessionID,
+				SendTime:          segment.Header.SendTime,
+			}
+			for _, cb := range p.sessionCallbacks {
+				cb(change)
+			}
+		}
+		if p.haveSession && segment.Header.SessionID != p.sessionID {
+			// Sequence numbers reset with the session.
+			p.haveNextSeq = false
+		}
+		p.sessionID = segment.Header.SessionID
+		p.haveSession = true
+
+		// Heartbeats carry the next expected sequence number too, so
+		// they participate in gap detection even with no messages.
+		first := segment.Header.FirstMessageSequenceNumber
+		if p.haveNextSeq && first > p.nextSeq {
+			gap := Gap{
+				SessionID: segment.Header.SessionID,
+				Expected:  p.nextSeq,
+				Received:  first,
+				Missing:   first - p.nextSeq,
+				SendTime:  segment.Header.SendTime,
+			}
+			p.gaps = append(p.gaps, gap)
+			for _, cb := range p.gapCallbacks {
+				cb(gap)
+			}
+		}
+		p.nextSeq = first + int64(segment.Header.MessageCount)
+		p.haveNextSeq = true
+
+		if segment.Header.MessageCount == 0 && len(p.heartbeatCallbacks) > 0 {
+			heartbeat := Heartbeat{
+				SendTime:          segment.Header.SendTime,
+				SessionID:         segment.Header.SessionID,
+				MessageProtocolID: segment.Header.MessageProtocolID,
+			}
+			if p.clock != nil {
+				heartbeat.ReceiptTime = p.clock.Now()
+			}
+			for _, cb := range p.heartbeatCallbacks {
+				cb(heartbeat)
+			}
+		}
+
 		if len(segment.Messages) != 0 {
+			p.currentHeader = segment.Header
 			p.currentSegment = segment.Messages
+			p.currentIndices = segment.Indices
 			p.currentMsgIndex = 0
+			if p.clock != nil {
+				p.receiptTime = p.clock.Now()
+			}
+			if source, ok := p.packetSource.(CaptureTimeSource); ok {
+				p.captureTime = source.CaptureTime()
+			}
 			return nil
 		}
 	}
@@ -8,16 +8,21 @@ package main
 
 import (
 	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/collector"
 	"github.com/timpalpant/go-iex/consolidator"
 	"github.com/timpalpant/go-iex/iextp/tops"
+	"github.com/timpalpant/go-iex/pcapio"
 )
 
 var header = []string{
@@ -30,13 +35,8 @@ var header = []string{
 	"volume",
 }
 
-func makeBars(trades []*tops.TradeReportMessage, openTime, closeTime time.Time) []*consolidator.Bar {
+func makeBars(trades []*tops.TradeReportMessage) []*consolidator.Bar {
 	bars := consolidator.MakeBars(trades)
-	for _, bar := range bars {
-		bar.OpenTime = openTime
-		bar.CloseTime = closeTime
-	}
-
 	sort.Slice(bars, func(i, j int) bool {
 		return bars[i].Symbol < bars[j].Symbol
 	})
@@ -44,23 +44,34 @@ func makeBars(trades []*tops.TradeReportMessage, openTime, closeTime time.Time)
 	return bars
 }
 
-func writeBar(bar *consolidator.Bar, w *csv.Writer) error {
+func writeBar(bar *consolidator.Bar, w *csv.Writer, format consolidator.PriceFormat, enricher *iex.Enricher) error {
 	row := []string{
 		bar.Symbol,
-		bar.OpenTime.Format(time.RFC3339),
-		strconv.FormatFloat(bar.Open, 'f', 4, 64),
-		strconv.FormatFloat(bar.High, 'f', 4, 64),
-		strconv.FormatFloat(bar.Low, 'f', 4, 64),
-		strconv.FormatFloat(bar.Close, 'f', 4, 64),
+		// Bar times are derived from the trades; truncate to the
+		// bar interval for interval-aligned output rows.
+		bar.OpenTime.Truncate(time.Minute).Format(time.RFC3339),
+		format.Format(bar.Open),
+		format.Format(bar.High),
+		format.Format(bar.Low),
+		format.Format(bar.Close),
 		strconv.FormatInt(bar.Volume, 10),
 	}
 
+	if enricher != nil {
+		info, err := enricher.Info(bar.Symbol)
+		if err != nil {
+			return err
+		}
+
+		row = append(row, info.SecurityType, info.Sector)
+	}
+
 	return w.Write(row)
 }
 
-func writeBars(bars []*consolidator.Bar, w *csv.Writer) error {
+func writeBars(bars []*consolidator.Bar, w *csv.Writer, format consolidator.PriceFormat, enricher *iex.Enricher) error {
 	for _, bar := range bars {
-		if err := writeBar(bar, w); err != nil {
+		if err := writeBar(bar, w, format, enricher); err != nil {
 			return err
 		}
 	}
@@ -69,17 +80,98 @@ func writeBars(bars []*consolidator.Bar, w *csv.Writer) error {
 }
 
 func main() {
-	packetSource, err := iex.NewPacketDataSource(os.Stdin)
+	matrixField := flag.String("matrix", "",
+		"emit a wide by-minute matrix of the given bar field "+
+			"(close or volume) instead of long rows")
+	metadataFile := flag.String("metadata", "",
+		"write a sidecar JSON file of feed metadata to the given path")
+	precision := flag.Int("precision", consolidator.DefaultPriceFormat.Precision,
+		"number of decimal places for prices")
+	scaled := flag.Bool("scaled", false,
+		"emit integer prices in 1/10000ths of a dollar, "+
+			"matching the wire format")
+	enrich := flag.Bool("enrich", false,
+		"append securityType and sector columns to long rows, "+
+			"fetched from the IEX reference data endpoints")
+	stats := flag.Bool("stats", false,
+		"report message-rate percentiles, the peak second, and the "+
+			"busiest symbols and minutes to stderr")
+	universeFile := flag.String("universe", "",
+		"only include symbols in the given universe spec file "+
+			"(plain text or JSON)")
+	flag.Parse()
+
+	var rates *collector.RateStats
+	if *stats {
+		rates = collector.NewRateStats()
+	}
+
+	var universe *iex.Universe
+	if *universeFile != "" {
+		var err error
+		if universe, err = iex.LoadUniverse(*universeFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var enricher *iex.Enricher
+	if *enrich {
+		if *matrixField != "" {
+			log.Fatal("-enrich only applies to long rows, not -matrix output")
+		}
+
+		enricher = iex.NewEnricher(iex.NewClient(http.DefaultClient))
+	}
+
+	priceFormat := consolidator.PriceFormat{
+		Precision: *precision,
+		Scaled:    *scaled,
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(os.Stdin)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	scanner := iex.NewPcapScanner(packetSource)
-	writer := csv.NewWriter(os.Stdout)
-	if err := writer.Write(header); err != nil {
-		log.Fatal(err)
+
+	var emit func(bars []*consolidator.Bar) error
+	if *matrixField != "" {
+		field, err := consolidator.ParseMatrixField(*matrixField)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		matrix := consolidator.NewMatrix(time.Minute)
+		matrix.SetPriceFormat(priceFormat)
+		emit = func(bars []*consolidator.Bar) error {
+			for _, bar := range bars {
+				matrix.Add(bar)
+			}
+
+			return nil
+		}
+		defer func() {
+			if err := matrix.WriteCSV(os.Stdout, field); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	} else {
+		columns := header
+		if enricher != nil {
+			columns = append(columns, "securityType", "sector")
+		}
+
+		writer := csv.NewWriter(os.Stdout)
+		if err := writer.Write(columns); err != nil {
+			log.Fatal(err)
+		}
+		defer writer.Flush()
+
+		emit = func(bars []*consolidator.Bar) error {
+			return writeBars(bars, writer, priceFormat, enricher)
+		}
 	}
-	defer writer.Flush()
 
 	var trades []*tops.TradeReportMessage
 	var openTime, closeTime time.Time
@@ -93,6 +185,19 @@ func main() {
 			log.Fatal(err)
 		}
 
+		if universe != nil {
+			if symbol, ok := iex.MessageSymbol(msg); ok && !universe.Contains(symbol) {
+				continue
+			}
+		}
+
+		if rates != nil {
+			if ts, ok := iex.MessageTimestamp(msg); ok {
+				symbol, _ := iex.MessageSymbol(msg)
+				rates.Observe(ts, symbol)
+			}
+		}
+
 		if msg, ok := msg.(*tops.TradeReportMessage); ok {
 			if openTime.IsZero() {
 				openTime = msg.Timestamp.Truncate(time.Minute)
@@ -100,8 +205,7 @@ func main() {
 			}
 
 			if msg.Timestamp.After(closeTime) && len(trades) > 0 {
-				bars := makeBars(trades, openTime, closeTime)
-				if err := writeBars(bars, writer); err != nil {
+				if err := emit(makeBars(trades)); err != nil {
 					log.Fatal(err)
 				}
 
@@ -113,4 +217,34 @@ func main() {
 			trades = append(trades, msg)
 		}
 	}
+
+	if len(trades) > 0 {
+		if err := emit(makeBars(trades)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *metadataFile != "" {
+		if err := writeMetadata(scanner.Metadata(), *metadataFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if rates != nil {
+		if err := rates.WriteReport(os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func writeMetadata(metadata iex.FeedMetadata, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metadata)
 }
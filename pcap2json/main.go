@@ -8,16 +8,122 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"flag"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/collector"
 	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/pcapio"
+	"github.com/timpalpant/go-iex/sinks"
 )
 
 func main() {
-	packetSource, err := iex.NewPacketDataSource(os.Stdin)
+	metadataFile := flag.String("metadata", "",
+		"write a sidecar JSON file of feed metadata to the given path")
+	envelope := flag.Bool("envelope", false,
+		"wrap each message in a schema-versioned envelope with its "+
+			"feed, message type, and capture time")
+	enrich := flag.Bool("enrich", false,
+		"set securityType and sector on each envelope, fetched from "+
+			"the IEX reference data endpoints (requires -envelope)")
+	shardDir := flag.String("shard-dir", "",
+		"write one <SYMBOL>.ndjson file per symbol to the given "+
+			"directory instead of an interleaved stream on stdout")
+	partition := flag.String("partition", "",
+		"partition output files by event time into intervals of the "+
+			"given size (hour, day, or a duration like 30m); combines "+
+			"with -shard-dir for per-symbol, per-interval files")
+	partitionDir := flag.String("partition-dir", "",
+		"write time-partitioned ticks-<start>.ndjson files to the "+
+			"given directory (requires -partition)")
+	stats := flag.Bool("stats", false,
+		"report message-rate percentiles, the peak second, and the "+
+			"busiest symbols and minutes to stderr")
+	universeFile := flag.String("universe", "",
+		"only emit messages for symbols in the given universe spec "+
+			"file (plain text or JSON)")
+	flag.Parse()
+
+	var rates *collector.RateStats
+	if *stats {
+		rates = collector.NewRateStats()
+	}
+
+	var universe *iex.Universe
+	if *universeFile != "" {
+		var err error
+		if universe, err = iex.LoadUniverse(*universeFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var enricher *iex.Enricher
+	if *enrich {
+		if !*envelope {
+			log.Fatal("-enrich requires -envelope")
+		}
+
+		enricher = iex.NewEnricher(iex.NewClient(http.DefaultClient))
+	}
+
+	var partitionInterval time.Duration
+	if *partition != "" {
+		var err error
+		if partitionInterval, err = sinks.ParsePartition(*partition); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// A sink, when configured, replaces the interleaved stdout stream.
+	type messageSink interface {
+		Write(msg iextp.Message) error
+		Close() error
+	}
+	var sink messageSink
+	if *shardDir != "" || *partitionDir != "" {
+		if *enrich {
+			log.Fatal("-enrich is not supported with file sinks")
+		}
+		if *shardDir != "" && *partitionDir != "" {
+			log.Fatal("-shard-dir and -partition-dir are mutually exclusive")
+		}
+
+		codecName := "json"
+		if *envelope {
+			codecName = "json-envelope"
+		}
+		codec, err := sinks.LookupCodec(codecName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if *shardDir != "" {
+			sharded := sinks.NewShardedFileSink(*shardDir, codec)
+			if partitionInterval > 0 {
+				sharded.SetPartition(partitionInterval)
+			}
+			sink = sharded
+		} else {
+			if partitionInterval == 0 {
+				log.Fatal("-partition-dir requires -partition")
+			}
+			sink = sinks.NewPartitionedFileSink(
+				*partitionDir, codec, partitionInterval)
+		}
+
+		defer func() {
+			if err := sink.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	packetSource, err := pcapio.NewPacketDataSource(os.Stdin)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -28,7 +134,7 @@ func main() {
 	enc := json.NewEncoder(output)
 
 	for {
-		msg, err := scanner.NextMessage()
+		e, err := scanner.NextMessageEnvelope()
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -37,10 +143,76 @@ func main() {
 			log.Fatal(err)
 		}
 
-		if msg, ok := msg.(*iextp.UnsupportedMessage); ok {
+		if msg, ok := e.Message.(*iextp.UnsupportedMessage); ok {
 			log.Printf("WARNING: Unsupported message type %v", byte(msg.MessageType))
 		}
 
-		enc.Encode(msg)
+		if universe != nil {
+			if symbol, ok := iex.MessageSymbol(e.Message); ok && !universe.Contains(symbol) {
+				continue
+			}
+		}
+
+		if rates != nil {
+			symbol, _ := iex.MessageSymbol(e.Message)
+			rates.Observe(e.Timestamp, symbol)
+		}
+
+		if sink != nil {
+			if err := sink.Write(e.Message); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
+		if *envelope {
+			exported, err := iex.NewExportedMessage(
+				e.Message, scanner.Metadata().Feed, e.Timestamp)
+			if err != nil {
+				// Unsupported messages cannot be exported in a
+				// typed envelope; skip them.
+				continue
+			}
+
+			if enricher != nil {
+				if symbol, ok := iex.MessageSymbol(e.Message); ok {
+					info, err := enricher.Info(symbol)
+					if err != nil {
+						log.Fatal(err)
+					}
+
+					exported.SecurityType = info.SecurityType
+					exported.Sector = info.Sector
+				}
+			}
+
+			enc.Encode(exported)
+		} else {
+			enc.Encode(e.Message)
+		}
+	}
+
+	if *metadataFile != "" {
+		if err := writeMetadata(scanner.Metadata(), *metadataFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if rates != nil {
+		if err := rates.WriteReport(os.Stderr); err != nil {
+			log.Fatal(err)
+		}
 	}
 }
+
+func writeMetadata(metadata iex.FeedMetadata, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metadata)
+}
@@ -1,142 +1,293 @@
 package iex
 
 import (
+	"encoding/binary"
 	"io"
-	"net"
-	"os"
-	"path/filepath"
 	"testing"
 	"time"
-)
 
-func TestPcapScanner(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping pcap test in short mode.")
-	}
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
 
-	testFilename := filepath.Join("testdata", "DEEP10.pcap.gz")
-	count := testPcapScanner(t, testFilename)
+// fakePacketSource serves a fixed sequence of packet payloads.
+type fakePacketSource struct {
+	payloads [][]byte
+}
 
-	if count != 392000 {
-		t.Fatalf("expected to process 392000 messages, got: %v", count)
+func (f *fakePacketSource) NextPayload() ([]byte, error) {
+	if len(f.payloads) == 0 {
+		return nil, io.EOF
 	}
+
+	payload := f.payloads[0]
+	f.payloads = f.payloads[1:]
+	return payload, nil
 }
 
-func TestPcapNgScanner(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping pcap-ng test in short mode.")
+// makeSegment builds a raw IEX-TP segment with the given send time
+// and message blocks.
+func makeSegment(sendTime time.Time, messages ...[]byte) []byte {
+	payloadLength := 0
+	for _, msg := range messages {
+		payloadLength += 2 + len(msg)
 	}
 
-	testFilename := filepath.Join("testdata", "TOPS16.pcapng.gz")
-	count := testPcapScanner(t, testFilename)
-	if count != 57675 {
-		t.Fatalf("expected to process 57675 messages, got: %v", count)
+	segment := make([]byte, 40, 40+payloadLength)
+	segment[0] = 1 // Version
+	binary.LittleEndian.PutUint16(segment[2:4], 0x8003)
+	binary.LittleEndian.PutUint32(segment[8:12], 42) // SessionID
+	binary.LittleEndian.PutUint16(segment[12:14], uint16(payloadLength))
+	binary.LittleEndian.PutUint16(segment[14:16], uint16(len(messages)))
+	binary.LittleEndian.PutUint64(segment[32:40], uint64(sendTime.UnixNano()))
+
+	for _, msg := range messages {
+		var length [2]byte
+		binary.LittleEndian.PutUint16(length[:], uint16(len(msg)))
+		segment = append(segment, length[:]...)
+		segment = append(segment, msg...)
 	}
+
+	return segment
 }
 
-func testPcapScanner(t *testing.T, filename string) int {
-	f, err := os.Open(filename)
-	if err != nil {
-		t.Fatal(err)
+func TestPcapScannerHeartbeats(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{
+		0x53, 0x53, // System Event: Start of System Hours
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 	}
-	defer f.Close()
 
-	stat, err := f.Stat()
-	if err != nil {
-		t.Fatal(err)
-	}
+	source := &fakePacketSource{payloads: [][]byte{
+		makeSegment(sendTime), // heartbeat
+		makeSegment(sendTime.Add(time.Second), systemEvent),
+		makeSegment(sendTime.Add(2 * time.Second)), // heartbeat
+	}}
 
-	packetDataSource, err := NewPacketDataSource(f)
-	if err != nil {
-		t.Fatal(err)
+	scanner := NewPcapScanner(source)
+	var heartbeats []Heartbeat
+	scanner.OnHeartbeat(func(h Heartbeat) {
+		heartbeats = append(heartbeats, h)
+	})
+
+	count := 0
+	for {
+		if _, err := scanner.NextMessage(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatal(err)
+		}
+		count++
 	}
 
-	scanner := NewPcapScanner(packetDataSource)
+	if count != 1 {
+		t.Errorf("expected 1 message, got: %v", count)
+	}
+	if len(heartbeats) != 2 {
+		t.Fatalf("expected 2 heartbeats, got: %v", len(heartbeats))
+	}
+	if !heartbeats[0].SendTime.Equal(sendTime) {
+		t.Errorf("unexpected heartbeat send time: %v", heartbeats[0].SendTime)
+	}
+	if heartbeats[0].SessionID != 42 || heartbeats[0].MessageProtocolID != 0x8003 {
+		t.Errorf("unexpected heartbeat header fields: %+v", heartbeats[0])
+	}
+	if !heartbeats[0].ReceiptTime.IsZero() {
+		t.Errorf("expected no receipt time without a clock: %+v", heartbeats[0])
+	}
+}
 
-	start := time.Now()
-	count := 0
-	for err = nil; err == nil; count++ {
-		_, err = scanner.NextMessage()
+func TestPcapScannerStop(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{
+		0x53, 0x53, // System Event: Start of System Hours
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 	}
-	elapsed := time.Since(start)
 
-	msgsPerSec := float64(count) / elapsed.Seconds()
-	mbPerSec := float64(stat.Size()) / 1000 / 1000 / elapsed.Seconds()
-	t.Logf("Processed %d messages (%.0f msgs/sec, %.1f MB/s)",
-		count, msgsPerSec, mbPerSec)
+	source := &fakePacketSource{payloads: [][]byte{
+		makeSegment(sendTime, systemEvent),
+		makeSegment(sendTime.Add(time.Second), systemEvent),
+	}}
 
-	// The sample pcap file ends with an unexpected EOF.
-	// TODO(palpant): Fix it so that we can assert a clean ending here.
-	if err != io.EOF && err != io.ErrUnexpectedEOF {
+	scanner := NewPcapScanner(source)
+	if _, err := scanner.NextMessage(); err != nil {
 		t.Fatal(err)
 	}
 
-	return count
+	scanner.Stop()
+	if _, err := scanner.NextMessage(); err != ErrScanStopped {
+		t.Errorf("expected ErrScanStopped, got: %v", err)
+	}
+	if _, err := scanner.NextMessageEnvelope(); err != ErrScanStopped {
+		t.Errorf("expected ErrScanStopped, got: %v", err)
+	}
 }
 
-func TestUDPScanner(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping UDP test in short mode.")
+func TestPcapScannerSessionChange(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{
+		0x53, 0x53, // System Event: Start of System Hours
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 	}
 
-	nPacketsToSend := 100
-	packetConn, err := net.ListenPacket("udp", "127.0.0.1:")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer packetConn.Close()
-	packetSource := NewPacketConnDataSource(packetConn)
-	t.Logf("Listing on udp://%s", packetConn.LocalAddr())
+	firstDay := makeSegment(sendTime, systemEvent)
+	secondDay := makeSegment(sendTime.Add(24*time.Hour), systemEvent)
+	// makeSegment stamps SessionID 42; rewrite the second day's.
+	binary.LittleEndian.PutUint32(secondDay[8:12], 43)
 
-	// Replay the given pcap dump to the given UDP address.
-	testFilename := filepath.Join("testdata", "DEEP10.pcap.gz")
-	go udpReplay(t, testFilename, packetConn.LocalAddr(), nPacketsToSend)
+	source := &fakePacketSource{payloads: [][]byte{firstDay, secondDay}}
+	scanner := NewPcapScanner(source)
 
-	time.Sleep(time.Second)
-	t.Log("Scanning UDP packets")
-	scanner := NewPcapScanner(packetSource)
-	for i := 0; i < nPacketsToSend; i++ {
+	var changes []SessionChange
+	scanner.OnSessionChange(func(c SessionChange) {
+		changes = append(changes, c)
+	})
+
+	for {
 		if _, err := scanner.NextMessage(); err != nil {
-			t.Fatal(err)
+			break
 		}
 	}
+
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 session change, got: %v", len(changes))
+	}
+	change := changes[0]
+	if change.PreviousSessionID != 42 || change.SessionID != 43 {
+		t.Errorf("unexpected session change: %+v", change)
+	}
+	if !change.SendTime.Equal(sendTime.Add(24 * time.Hour)) {
+		t.Errorf("unexpected session change time: %v", change.SendTime)
+	}
 }
 
-// Replays all packets in the given pcap filename to the given address.
-func udpReplay(t *testing.T, pcapFilename string, addr net.Addr, nPacketsToSend int) {
-	t.Log("Dialing: ", addr)
-	conn, err := net.DialTimeout("udp", addr.String(), time.Second)
-	if err != nil {
-		t.Fatal("could not connect to server: ", err)
+func TestPcapScannerRawFilter(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	rawQuote := func(symbol string) []byte {
+		msg := make([]byte, 42)
+		msg[0] = 0x51 // Quote Update
+		copy(msg[10:18], "        ")
+		copy(msg[10:18], symbol)
+		return msg
 	}
-	defer conn.Close()
 
-	f, err := os.Open(pcapFilename)
+	source := &fakePacketSource{payloads: [][]byte{
+		makeSegment(sendTime,
+			rawQuote("FB"), rawQuote("SNAP"), rawQuote("AAPL")),
+	}}
+	scanner := NewPcapScanner(source)
+
+	filter, err := tops.NewSymbolFilter("SNAP")
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer f.Close()
+	scanner.SetRawFilter(filter.Match)
 
-	packetSource, err := NewPcapDataSource(f)
+	e, err := scanner.NextMessageEnvelope()
 	if err != nil {
 		t.Fatal(err)
 	}
+	quote, ok := e.Message.(*tops.QuoteUpdateMessage)
+	if !ok || quote.Symbol != "SNAP" {
+		t.Errorf("unexpected message: %v", e.Message)
+	}
+	// Sequence numbers reflect the message's wire position, not its
+	// position among the surviving messages.
+	if e.MessageIndex != 1 || e.SequenceNumber != 1 {
+		t.Errorf("unexpected envelope position: %+v", e)
+	}
 
-	t.Logf("Replaying first %d packets from %s", nPacketsToSend, pcapFilename)
-	for i := 0; i < nPacketsToSend; i++ {
-		payload, err := packetSource.NextPayload()
-		if err != nil {
-			if err == io.EOF {
-				return
-			}
+	if _, err := scanner.NextMessageEnvelope(); err != io.EOF {
+		t.Errorf("expected EOF after the only matching message, got: %v", err)
+	}
+}
+
+func TestPcapScannerGaps(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{
+		0x53, 0x53, // System Event: Start of System Hours
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	// stamp rewrites a segment's first message sequence number.
+	stamp := func(segment []byte, seq int64) []byte {
+		binary.LittleEndian.PutUint64(segment[24:32], uint64(seq))
+		return segment
+	}
+
+	// Sequences 1-2, then 3, then a jump to 10: 4-9 were dropped.
+	// A new session restarting at 1 is a rollover, not a gap.
+	first := stamp(makeSegment(sendTime, systemEvent, systemEvent), 1)
+	second := stamp(makeSegment(sendTime, systemEvent), 3)
+	gapTime := sendTime.Add(time.Minute)
+	third := stamp(makeSegment(gapTime, systemEvent), 10)
+	newSession := stamp(makeSegment(sendTime.Add(24*time.Hour), systemEvent), 1)
+	binary.LittleEndian.PutUint32(newSession[8:12], 43)
+
+	source := &fakePacketSource{payloads: [][]byte{first, second, third, newSession}}
+	scanner := NewPcapScanner(source)
 
-			t.Fatal("could not write payload to server:", err)
-			return
+	var fromCallback []Gap
+	scanner.OnGap(func(g Gap) {
+		fromCallback = append(fromCallback, g)
+	})
+
+	for {
+		if _, err := scanner.NextMessage(); err != nil {
+			break
 		}
+	}
+
+	gaps := scanner.Gaps()
+	if len(gaps) != 1 {
+		t.Fatalf("expected 1 gap, got: %+v", gaps)
+	}
+	gap := gaps[0]
+	if gap.SessionID != 42 || gap.Expected != 4 || gap.Received != 10 || gap.Missing != 6 {
+		t.Errorf("unexpected gap: %+v", gap)
+	}
+	if !gap.SendTime.Equal(gapTime) {
+		t.Errorf("unexpected gap send time: %v", gap.SendTime)
+	}
+	if len(fromCallback) != 1 || fromCallback[0] != gap {
+		t.Errorf("unexpected callback gaps: %+v", fromCallback)
+	}
+}
 
-		if _, err := conn.Write(payload); err != nil {
-			t.Fatal("could not write payload to server:", err)
-			return
+func TestPcapScannerNextMessages(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	systemEvent := []byte{
+		0x53, 0x53, // System Event: Start of System Hours
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	source := &fakePacketSource{payloads: [][]byte{
+		makeSegment(sendTime, systemEvent, systemEvent, systemEvent),
+		makeSegment(sendTime, systemEvent, systemEvent),
+	}}
+	scanner := NewPcapScanner(source)
+
+	// A batch read crosses the segment boundary.
+	dst := make([]iextp.Message, 4)
+	n, err := scanner.NextMessages(dst)
+	if n != 4 || err != nil {
+		t.Fatalf("expected 4 messages, got: %v, %v", n, err)
+	}
+	for i, msg := range dst {
+		if _, ok := msg.(*tops.SystemEventMessage); !ok {
+			t.Errorf("unexpected message %v: %T", i, msg)
 		}
 	}
+
+	// The final short batch delivers the remainder alongside io.EOF.
+	n, err = scanner.NextMessages(dst)
+	if n != 1 || err != io.EOF {
+		t.Fatalf("expected 1 message and EOF, got: %v, %v", n, err)
+	}
+
+	n, err = scanner.NextMessages(dst)
+	if n != 0 || err != io.EOF {
+		t.Errorf("expected no messages and EOF, got: %v, %v", n, err)
+	}
 }
@@ -0,0 +1,162 @@
+package pcapio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileBoundary describes the stream advancing into the next file of a
+// DirDataSource. For a directory of daily HIST files, each boundary is
+// a day boundary.
+type FileBoundary struct {
+	// The path of the file the stream is entering.
+	Path string
+	// The position of the file within the directory, starting at 0.
+	Index int
+	// The total number of files in the directory.
+	Total int
+}
+
+// DirDataSource presents a directory of daily HIST dumps as a single
+// continuous packet stream, so multi-day analytics can drive one
+// iex.PcapScanner instead of managing files manually. Files are read
+// in lexical filename order, which for HIST names (the date is
+// embedded in the filename) is date order. Session rollovers between
+// days surface through the scanner's OnSessionChange as usual;
+// OnFileBoundary additionally reports each file as it is entered.
+type DirDataSource struct {
+	files   []string
+	index   int
+	file    *os.File
+	current *GopacketDataSource
+	// Callbacks invoked as the stream enters each file.
+	boundaryCallbacks []func(FileBoundary)
+}
+
+// NewDirDataSource creates a DirDataSource over the pcap and pcap-ng
+// files (optionally gzipped) in the given directory.
+func NewDirDataSource(dir string) (*DirDataSource, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.Contains(entry.Name(), ".pcap") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no HIST files found in %v", dir)
+	}
+
+	sort.Strings(files)
+	return &DirDataSource{files: files}, nil
+}
+
+// Files returns the paths that will be read, in order.
+func (d *DirDataSource) Files() []string {
+	return d.files
+}
+
+// OnFileBoundary registers a callback invoked as the stream enters
+// each file, before any of its payloads are returned, including the
+// first file. Callbacks run synchronously during NextPayload.
+func (d *DirDataSource) OnFileBoundary(cb func(FileBoundary)) {
+	d.boundaryCallbacks = append(d.boundaryCallbacks, cb)
+}
+
+// NextPayload implements iex.PacketDataSource, advancing to the next
+// file as each one is exhausted. It returns io.EOF once the last file
+// has been read.
+func (d *DirDataSource) NextPayload() ([]byte, error) {
+	for {
+		if d.current == nil {
+			if d.index >= len(d.files) {
+				return nil, io.EOF
+			}
+
+			if err := d.open(d.files[d.index]); err != nil {
+				return nil, err
+			}
+
+			boundary := FileBoundary{
+				Path:  d.files[d.index],
+				Index: d.index,
+				Total: len(d.files),
+			}
+			d.index++
+			for _, cb := range d.boundaryCallbacks {
+				cb(boundary)
+			}
+		}
+
+		payload, err := d.current.NextPayload()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// A truncated final packet ends that day's dump, not the
+			// stream.
+			d.closeCurrent()
+			continue
+		}
+
+		return payload, err
+	}
+}
+
+// CaptureTime implements iex.CaptureTimeSource, returning the capture
+// timestamp of the payload most recently returned by NextPayload.
+func (d *DirDataSource) CaptureTime() time.Time {
+	if d.current == nil {
+		return time.Time{}
+	}
+
+	return d.current.CaptureTime()
+}
+
+// Close closes the file currently being read, if any. Reading to
+// io.EOF closes all files as they are exhausted; Close is for
+// abandoning a scan early.
+func (d *DirDataSource) Close() error {
+	if d.file == nil {
+		return nil
+	}
+
+	err := d.file.Close()
+	d.file = nil
+	d.current = nil
+	return err
+}
+
+func (d *DirDataSource) open(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	source, err := NewPcapDataSource(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("%v: %v", path, err)
+	}
+
+	d.file = f
+	d.current = source
+	return nil
+}
+
+func (d *DirDataSource) closeCurrent() {
+	if d.file != nil {
+		d.file.Close()
+		d.file = nil
+	}
+	d.current = nil
+}
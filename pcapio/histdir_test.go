@@ -0,0 +1,164 @@
+package pcapio
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/timpalpant/go-iex"
+)
+
+// makeTestSegment builds a raw IEX-TP segment with the given session
+// and message blocks.
+func makeTestSegment(sessionID uint32, sendTime time.Time, messages ...[]byte) []byte {
+	payloadLength := 0
+	for _, msg := range messages {
+		payloadLength += 2 + len(msg)
+	}
+
+	segment := make([]byte, 40, 40+payloadLength)
+	segment[0] = 1 // Version
+	binary.LittleEndian.PutUint16(segment[2:4], 0x8003)
+	binary.LittleEndian.PutUint32(segment[8:12], sessionID)
+	binary.LittleEndian.PutUint16(segment[12:14], uint16(payloadLength))
+	binary.LittleEndian.PutUint16(segment[14:16], uint16(len(messages)))
+	binary.LittleEndian.PutUint64(segment[32:40], uint64(sendTime.UnixNano()))
+
+	for _, msg := range messages {
+		var length [2]byte
+		binary.LittleEndian.PutUint16(length[:], uint16(len(msg)))
+		segment = append(segment, length[:]...)
+		segment = append(segment, msg...)
+	}
+
+	return segment
+}
+
+// writePcapFile writes a pcap file containing one UDP packet per
+// payload.
+func writePcapFile(t *testing.T, path string, captureTime time.Time, payloads ...[]byte) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, payload := range payloads {
+		eth := layers.Ethernet{
+			SrcMAC:       []byte{2, 0, 0, 0, 0, 1},
+			DstMAC:       []byte{2, 0, 0, 0, 0, 2},
+			EthernetType: layers.EthernetTypeIPv4,
+		}
+		ip := layers.IPv4{
+			Version:  4,
+			IHL:      5,
+			TTL:      64,
+			Protocol: layers.IPProtocolUDP,
+			SrcIP:    []byte{127, 0, 0, 1},
+			DstIP:    []byte{233, 215, 21, 4},
+		}
+		udp := layers.UDP{SrcPort: 10000, DstPort: 10378}
+		udp.SetNetworkLayerForChecksum(&ip)
+
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		err := gopacket.SerializeLayers(buf, opts,
+			&eth, &ip, &udp, gopacket.Payload(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info := gopacket.CaptureInfo{
+			Timestamp:     captureTime.Add(time.Duration(i) * time.Second),
+			CaptureLength: len(buf.Bytes()),
+			Length:        len(buf.Bytes()),
+		}
+		if err := w.WritePacket(info, buf.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDirDataSource(t *testing.T) {
+	dir := t.TempDir()
+	systemEvent := []byte{0x53, 0x45, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	day1 := time.Date(2017, time.April, 17, 13, 30, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	writePcapFile(t, filepath.Join(dir, "data_feeds_20170417.pcap"), day1,
+		makeTestSegment(42, day1, systemEvent),
+		makeTestSegment(42, day1.Add(time.Second), systemEvent))
+	writePcapFile(t, filepath.Join(dir, "data_feeds_20170418.pcap"), day2,
+		makeTestSegment(43, day2, systemEvent))
+	// A non-HIST file in the directory is ignored.
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewDirDataSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	if len(source.Files()) != 2 {
+		t.Fatalf("expected 2 files, got: %v", source.Files())
+	}
+
+	var boundaries []FileBoundary
+	source.OnFileBoundary(func(b FileBoundary) {
+		boundaries = append(boundaries, b)
+	})
+
+	var sessionChanges []iex.SessionChange
+	scanner := iex.NewPcapScanner(source)
+	scanner.OnSessionChange(func(change iex.SessionChange) {
+		sessionChanges = append(sessionChanges, change)
+	})
+
+	count := 0
+	for {
+		if _, err := scanner.NextMessage(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+
+	if count != 3 {
+		t.Errorf("expected 3 messages across both days, got: %v", count)
+	}
+	if len(boundaries) != 2 {
+		t.Fatalf("expected 2 file boundaries, got: %+v", boundaries)
+	}
+	if boundaries[0].Index != 0 || filepath.Base(boundaries[0].Path) != "data_feeds_20170417.pcap" {
+		t.Errorf("unexpected first boundary: %+v", boundaries[0])
+	}
+	if boundaries[1].Index != 1 || boundaries[1].Total != 2 {
+		t.Errorf("unexpected second boundary: %+v", boundaries[1])
+	}
+	if len(sessionChanges) != 1 || sessionChanges[0].SessionID != 43 {
+		t.Errorf("expected one session change at the day boundary: %+v", sessionChanges)
+	}
+}
+
+func TestDirDataSource_Empty(t *testing.T) {
+	if _, err := NewDirDataSource(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory with no HIST files")
+	}
+}
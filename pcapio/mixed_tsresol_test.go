@@ -0,0 +1,58 @@
+package pcapio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The fixture has two interfaces, the first with the default
+// microsecond resolution and the second with nanosecond resolution,
+// and one UDP packet captured on each. Each packet's timestamp must
+// be interpreted at the resolution of its own interface.
+func TestPcapDataSource_MixedResolutionInterfaces(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "mixed_tsresol.pcapng"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	source, err := NewPcapDataSource(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := source.NextPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, []byte("IEXPAYLOAD1")) {
+		t.Errorf("unexpected payload: %q", payload)
+	}
+	ci := source.CaptureInfo()
+	want := time.Date(2017, time.April, 17, 9, 30, 0, 123456000, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+	if ci.InterfaceIndex != 0 {
+		t.Errorf("unexpected interface: %v", ci.InterfaceIndex)
+	}
+
+	payload, err = source.NextPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, []byte("IEXPAYLOAD2")) {
+		t.Errorf("unexpected payload: %q", payload)
+	}
+	ci = source.CaptureInfo()
+	want = time.Date(2017, time.April, 17, 9, 30, 0, 123456789, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+	if ci.InterfaceIndex != 1 {
+		t.Errorf("unexpected interface: %v", ci.InterfaceIndex)
+	}
+}
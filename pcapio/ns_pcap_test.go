@@ -0,0 +1,49 @@
+package pcapio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Classic pcap files written with the nanosecond magic (0xa1b23c4d)
+// carry nanosecond fractions in their record headers; the capture
+// timestamps must retain that precision for latency analysis.
+func TestPcapDataSource_NanosecondPcap(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "ns.pcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	source, err := NewPacketDataSource(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcapSource, ok := source.(*GopacketDataSource)
+	if !ok {
+		t.Fatalf("expected a GopacketDataSource, got: %T", source)
+	}
+
+	payload, err := pcapSource.NextPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, []byte("IEXPAYLOADNS")) {
+		t.Errorf("unexpected payload: %q", payload)
+	}
+	want := time.Date(2017, time.April, 17, 9, 30, 0, 123456789, time.UTC)
+	if ci := pcapSource.CaptureInfo(); !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+
+	if _, err := pcapSource.NextPayload(); err != nil {
+		t.Fatal(err)
+	}
+	want = time.Date(2017, time.April, 17, 9, 30, 0, 987654321, time.UTC)
+	if ci := pcapSource.CaptureInfo(); !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+}
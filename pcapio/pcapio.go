@@ -0,0 +1,118 @@
+// Package pcapio provides packet payload sources backed by pcap and
+// pcap-ng dumps. It is the only package in this repository that
+// depends on gopacket, so consumers who only want the REST client can
+// import the root package without pulling in packet-capture
+// dependencies.
+package pcapio
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/timpalpant/go-iex"
+)
+
+const (
+	magicGzip1         = 0x1f
+	magicGzip2         = 0x8b
+	pcapNGMagic uint32 = 0x0A0D0D0A
+)
+
+// DEPRECATED: Use iex.NewPacketConnDataSource or NewPcapDataSource.
+func NewPacketDataSource(r io.Reader) (iex.PacketDataSource, error) {
+	// Check for live-streaming packet connection.
+	if conn, ok := r.(net.PacketConn); ok {
+		return iex.NewPacketConnDataSource(conn), nil
+	}
+
+	// Otherwise it must be data from a pcap or pcap-ng dump.
+	return NewPcapDataSource(r)
+}
+
+// GopacketDataSource implements iex.PacketDataSource for
+// gopacket.PacketSource. It can be used to source the packet payload
+// data from a pcap or pcap-ng file.
+type GopacketDataSource struct {
+	packetSource *gopacket.PacketSource
+	captureInfo  gopacket.CaptureInfo
+}
+
+func NewGopacketDataSource(packetSource *gopacket.PacketSource) *GopacketDataSource {
+	return &GopacketDataSource{packetSource: packetSource}
+}
+
+// Create a new GopacketDataSource from the given pcap or pcap-ng file data.
+func NewPcapDataSource(r io.Reader) (*GopacketDataSource, error) {
+	input := bufio.NewReader(r)
+	gzipMagic, err := input.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if gzipMagic[0] == magicGzip1 && gzipMagic[1] == magicGzip2 {
+		if gzf, err := gzip.NewReader(input); err != nil {
+			return nil, err
+		} else {
+			input = bufio.NewReader(gzf)
+		}
+	}
+
+	magicBuf, err := input.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	magic := binary.LittleEndian.Uint32(magicBuf)
+
+	var packetSource *gopacket.PacketSource
+	if magic == pcapNGMagic {
+		packetReader, err := NewNgReader(input)
+		if err != nil {
+			return nil, err
+		}
+		packetSource = gopacket.NewPacketSource(packetReader, packetReader.LinkType())
+	} else {
+		packetReader, err := pcapgo.NewReader(input)
+		if err != nil {
+			return nil, err
+		}
+		packetSource = gopacket.NewPacketSource(packetReader, packetReader.LinkType())
+	}
+
+	return NewGopacketDataSource(packetSource), nil
+}
+
+// NextPayload implements iex.PacketDataSource.
+func (gds *GopacketDataSource) NextPayload() ([]byte, error) {
+	for {
+		packet, err := gds.packetSource.NextPacket()
+		if err != nil {
+			return nil, err
+		}
+
+		if app := packet.ApplicationLayer(); app != nil {
+			gds.captureInfo = packet.Metadata().CaptureInfo
+			return app.Payload(), nil
+		}
+	}
+}
+
+// CaptureInfo returns the capture metadata of the payload most
+// recently returned by NextPayload, including its capture timestamp
+// at the resolution of the interface that recorded it.
+func (gds *GopacketDataSource) CaptureInfo() gopacket.CaptureInfo {
+	return gds.captureInfo
+}
+
+// CaptureTime returns the capture timestamp of the payload most
+// recently returned by NextPayload, implementing
+// iex.CaptureTimeSource so scanners can stamp envelopes with it.
+func (gds *GopacketDataSource) CaptureTime() time.Time {
+	return gds.captureInfo.Timestamp
+}
@@ -0,0 +1,144 @@
+package pcapio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+func TestNgReader_LittleEndianNanoseconds(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "ns.pcapng"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ng, err := NewNgReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ng.LinkType() != layers.LinkTypeEthernet {
+		t.Errorf("unexpected link type: %v", ng.LinkType())
+	}
+	interfaces := ng.Interfaces()
+	if len(interfaces) != 1 || interfaces[0].Name != "eth0" {
+		t.Errorf("unexpected interfaces: %+v", interfaces)
+	}
+
+	data, ci, err := ng.ReadPacketData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x01, 0x02, 0x03, 0x04, 0x05}) {
+		t.Errorf("unexpected packet data: %x", data)
+	}
+	want := time.Date(2017, time.April, 17, 9, 30, 0, 123456789, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+	if ci.CaptureLength != 5 || ci.Length != 5 {
+		t.Errorf("unexpected capture info: %+v", ci)
+	}
+
+	data, ci, err = ng.ReadPacketData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0xaa, 0xbb, 0xcc}) {
+		t.Errorf("unexpected packet data: %x", data)
+	}
+	want = time.Date(2017, time.April, 17, 9, 30, 0, 987654321, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+
+	if _, _, err := ng.ReadPacketData(); err != io.EOF {
+		t.Errorf("expected EOF, got: %v", err)
+	}
+}
+
+func TestNgReader_BigEndianMultiInterface(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "be_multi.pcapng"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ng, err := NewNgReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first packet is on interface 0, with the default
+	// microsecond resolution.
+	data, ci, err := ng.ReadPacketData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.InterfaceIndex != 0 {
+		t.Errorf("unexpected interface: %v", ci.InterfaceIndex)
+	}
+	want := time.Date(2017, time.April, 17, 9, 30, 0, 123456000, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+	if len(data) != 6 {
+		t.Errorf("unexpected packet data: %x", data)
+	}
+
+	// The second is on interface 1, with 2^-10 second resolution.
+	_, ci, err = ng.ReadPacketData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.InterfaceIndex != 1 {
+		t.Errorf("unexpected interface: %v", ci.InterfaceIndex)
+	}
+	want = time.Date(2017, time.April, 17, 9, 30, 0, 500000000, time.UTC)
+	if !ci.Timestamp.Equal(want) {
+		t.Errorf("unexpected timestamp: %v != %v", ci.Timestamp, want)
+	}
+
+	// The last is a Simple Packet Block, truncated to interface 0's
+	// snap length.
+	data, ci, err = ng.ReadPacketData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x0a, 0x0b, 0x0c, 0x0d}) {
+		t.Errorf("unexpected packet data: %x", data)
+	}
+	if ci.CaptureLength != 4 || ci.Length != 6 {
+		t.Errorf("unexpected capture info: %+v", ci)
+	}
+
+	if _, _, err := ng.ReadPacketData(); err != io.EOF {
+		t.Errorf("expected EOF, got: %v", err)
+	}
+}
+
+func TestTsUnitsPerSec(t *testing.T) {
+	cases := []struct {
+		resol byte
+		want  uint64
+	}{
+		{0, 1},
+		{3, 1000},
+		{6, 1000000},
+		{9, 1000000000},
+		{0x80, 1},
+		{0x8a, 1024},
+	}
+
+	for _, c := range cases {
+		if got := tsUnitsPerSec(c.resol); got != c.want {
+			t.Errorf("tsUnitsPerSec(%#x) = %v, expected %v", c.resol, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// ShardedBars consolidates trades into bars across multiple workers,
+// each owning an independent BarBuilder for its slice of the symbol
+// space. Bars merges the shards' output into one globally ordered
+// stream, so full-day consolidation of a DEEP or TOPS dump scales
+// across cores while producing the same bars as a single builder.
+type ShardedBars struct {
+	pipeline *Pipeline
+	shards   []*barShard
+}
+
+// NewShardedBars creates a sharded consolidator of the given width,
+// aggregating trades into bars of the given interval.
+func NewShardedBars(shards int, interval time.Duration) *ShardedBars {
+	s := &ShardedBars{shards: make([]*barShard, shards)}
+	s.pipeline = NewPipeline(shards, func(shard int) Handler {
+		s.shards[shard] = &barShard{
+			builder: consolidator.NewBarBuilder(interval),
+		}
+		return s.shards[shard]
+	})
+
+	return s
+}
+
+// OnMessage folds the given message into its shard's builder.
+// Non-trade messages are ignored.
+func (s *ShardedBars) OnMessage(msg iextp.Message) {
+	if _, ok := msg.(*tops.TradeReportMessage); !ok {
+		return
+	}
+
+	s.pipeline.OnMessage(msg)
+}
+
+// Bars stops the workers, flushes every shard's builder, and returns
+// all completed bars merged into one stream ordered by open time and
+// symbol.
+func (s *ShardedBars) Bars() []*consolidator.Bar {
+	s.pipeline.Close()
+
+	var bars []*consolidator.Bar
+	for _, shard := range s.shards {
+		bars = append(bars, shard.bars...)
+		bars = append(bars, shard.builder.Flush()...)
+		shard.bars = nil
+	}
+
+	sort.Slice(bars, func(i, j int) bool {
+		if !bars[i].OpenTime.Equal(bars[j].OpenTime) {
+			return bars[i].OpenTime.Before(bars[j].OpenTime)
+		}
+		return bars[i].Symbol < bars[j].Symbol
+	})
+	return bars
+}
+
+// barShard is one worker's builder and its completed bars.
+type barShard struct {
+	builder *consolidator.BarBuilder
+	bars    []*consolidator.Bar
+}
+
+func (s *barShard) OnMessage(msg iextp.Message) {
+	if trade, ok := msg.(*tops.TradeReportMessage); ok {
+		s.bars = append(s.bars, s.builder.Add(trade)...)
+	}
+}
@@ -0,0 +1,120 @@
+// Package pipeline shards a decoded message stream across workers by
+// symbol hash, so per-symbol stateful processing (bar building, book
+// reconstruction) can scale across cores.
+//
+// Each worker owns its handler exclusively and receives every message
+// for its symbols in feed order, so handlers need no locking.
+// Messages without a symbol (system events) are broadcast to every
+// shard. Cross-shard ordering is not preserved mid-stream; merged,
+// globally ordered output is produced at flush boundaries (see
+// ShardedBars).
+package pipeline
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultQueueSize is how many messages each shard's queue buffers
+// when none is configured.
+const DefaultQueueSize = 1024
+
+// Handler processes the messages routed to one shard. It is called
+// from that shard's worker goroutine only.
+type Handler interface {
+	OnMessage(msg iextp.Message)
+}
+
+// Pipeline routes messages to per-shard worker goroutines by symbol
+// hash.
+type Pipeline struct {
+	handlers  []Handler
+	queues    []chan iextp.Message
+	queueSize int
+	started   bool
+	wg        sync.WaitGroup
+}
+
+// NewPipeline creates a pipeline of the given number of shards, with
+// newHandler called once per shard to create its handler.
+func NewPipeline(shards int, newHandler func(shard int) Handler) *Pipeline {
+	p := &Pipeline{
+		handlers:  make([]Handler, shards),
+		queues:    make([]chan iextp.Message, shards),
+		queueSize: DefaultQueueSize,
+	}
+	for i := range p.handlers {
+		p.handlers[i] = newHandler(i)
+	}
+
+	return p
+}
+
+// SetQueueSize overrides how many messages each shard's queue
+// buffers. It must be called before the first OnMessage.
+func (p *Pipeline) SetQueueSize(messages int) {
+	p.queueSize = messages
+}
+
+// Shard returns the shard index the given symbol routes to.
+func (p *Pipeline) Shard(symbol string) int {
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// OnMessage routes the message to its symbol's shard, blocking if
+// that shard's queue is full. Messages without a symbol are broadcast
+// to every shard.
+func (p *Pipeline) OnMessage(msg iextp.Message) {
+	if !p.started {
+		p.start()
+	}
+
+	if symbol, ok := iex.MessageSymbol(msg); ok {
+		p.queues[p.Shard(symbol)] <- msg
+		return
+	}
+
+	for _, queue := range p.queues {
+		queue <- msg
+	}
+}
+
+// Close drains the queues and stops the workers. After Close returns,
+// the handlers are quiescent and may be read directly.
+func (p *Pipeline) Close() {
+	if !p.started {
+		return
+	}
+
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+	p.started = false
+}
+
+// Handler returns the given shard's handler. It must only be called
+// before the first OnMessage or after Close, when no worker owns it.
+func (p *Pipeline) Handler(shard int) Handler {
+	return p.handlers[shard]
+}
+
+// start launches the shard workers.
+func (p *Pipeline) start() {
+	p.started = true
+	for i := range p.queues {
+		p.queues[i] = make(chan iextp.Message, p.queueSize)
+		p.wg.Add(1)
+		go func(handler Handler, queue chan iextp.Message) {
+			defer p.wg.Done()
+			for msg := range queue {
+				handler.OnMessage(msg)
+			}
+		}(p.handlers[i], p.queues[i])
+	}
+}
@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var t0 = time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+func trade(symbol string, offset time.Duration, price float64, size uint32) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		Symbol:    symbol,
+		Timestamp: t0.Add(offset),
+		Price:     price,
+		Size:      size,
+	}
+}
+
+// countingHandler records the symbols it sees and the number of
+// broadcast (no-symbol) messages.
+type countingHandler struct {
+	symbols    map[string]int
+	broadcasts int
+}
+
+func (h *countingHandler) OnMessage(msg iextp.Message) {
+	switch m := msg.(type) {
+	case *tops.TradeReportMessage:
+		h.symbols[m.Symbol]++
+	case *tops.SystemEventMessage:
+		h.broadcasts++
+	}
+}
+
+func TestPipelineRouting(t *testing.T) {
+	handlers := make([]*countingHandler, 4)
+	pipeline := NewPipeline(len(handlers), func(shard int) Handler {
+		handlers[shard] = &countingHandler{symbols: make(map[string]int)}
+		return handlers[shard]
+	})
+
+	symbols := []string{"SNAP", "FB", "AAPL", "AMD", "TSLA"}
+	for i := 0; i < 3; i++ {
+		for _, symbol := range symbols {
+			pipeline.OnMessage(trade(symbol, 0, 20.10, 100))
+		}
+	}
+	pipeline.OnMessage(&tops.SystemEventMessage{})
+	pipeline.Close()
+
+	// Each symbol's messages all landed on its one shard.
+	for _, symbol := range symbols {
+		shard := pipeline.Shard(symbol)
+		for i, handler := range handlers {
+			expected := 0
+			if i == shard {
+				expected = 3
+			}
+			if handler.symbols[symbol] != expected {
+				t.Errorf("shard %v saw %v messages for %v, expected %v",
+					i, handler.symbols[symbol], symbol, expected)
+			}
+		}
+	}
+
+	// The system event was broadcast to every shard.
+	for i, handler := range handlers {
+		if handler.broadcasts != 1 {
+			t.Errorf("shard %v saw %v broadcasts, expected 1", i, handler.broadcasts)
+		}
+	}
+}
+
+func TestShardedBars(t *testing.T) {
+	var trades []*tops.TradeReportMessage
+	for i := 0; i < 200; i++ {
+		symbol := fmt.Sprintf("SYM%d", i%7)
+		offset := time.Duration(i) * 3 * time.Second
+		trades = append(trades, trade(symbol, offset, 20+float64(i%10)/100, 100))
+	}
+
+	sharded := NewShardedBars(4, time.Minute)
+	for _, trade := range trades {
+		sharded.OnMessage(trade)
+	}
+	// Non-trade messages are ignored.
+	sharded.OnMessage(&tops.QuoteUpdateMessage{Symbol: "SYM0"})
+	got := sharded.Bars()
+
+	// A single builder over the same trades produces the same bars.
+	builder := consolidator.NewBarBuilder(time.Minute)
+	var expected []*consolidator.Bar
+	for _, trade := range trades {
+		expected = append(expected, builder.Add(trade)...)
+	}
+	expected = append(expected, builder.Flush()...)
+	sort.Slice(expected, func(i, j int) bool {
+		if !expected[i].OpenTime.Equal(expected[j].OpenTime) {
+			return expected[i].OpenTime.Before(expected[j].OpenTime)
+		}
+		return expected[i].Symbol < expected[j].Symbol
+	})
+
+	if len(got) != len(expected) {
+		t.Fatalf("got %v bars, expected %v", len(got), len(expected))
+	}
+	for i := range got {
+		if !reflect.DeepEqual(got[i], expected[i]) {
+			t.Errorf("bar %v: got %+v, expected %+v", i, got[i], expected[i])
+		}
+	}
+}
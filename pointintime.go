@@ -0,0 +1,192 @@
+package iex
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// SymbolState is the reconstructed state of a single symbol at a point
+// in time: the most recent message of each kind folded so far.
+type SymbolState struct {
+	Symbol string
+	// The most recent trade report, or nil if none has printed.
+	LastTrade *tops.TradeReportMessage
+	// The most recent top-of-book quote, or nil.
+	Quote *tops.QuoteUpdateMessage
+	// The most recent trading status, or nil.
+	TradingStatus *tops.TradingStatusMessage
+	// The official opening and closing prices, once published.
+	OfficialOpen  float64
+	OfficialClose float64
+	// The aggregated order book reconstructed from price level
+	// updates. Nil for feeds without book depth (TOPS).
+	Book *Book
+}
+
+// MarketState is the reconstructed state of every symbol seen in a
+// message stream, as of a point in time.
+type MarketState struct {
+	// The timestamp of the latest message folded into the state.
+	AsOf    time.Time
+	Symbols map[string]*SymbolState
+}
+
+// MarketStateBuilder incrementally reconstructs per-symbol market
+// state from a message stream. It underlies MarketStateAt; replays
+// that need the state at several instants can drive it directly and
+// call State at each one.
+type MarketStateBuilder struct {
+	symbols map[string]*SymbolState
+	books   map[string]*bookLevels
+	asOf    time.Time
+}
+
+type bookLevels struct {
+	bids map[float64]uint32
+	asks map[float64]uint32
+}
+
+// NewMarketStateBuilder creates an empty builder.
+func NewMarketStateBuilder() *MarketStateBuilder {
+	return &MarketStateBuilder{
+		symbols: make(map[string]*SymbolState),
+		books:   make(map[string]*bookLevels),
+	}
+}
+
+// Add folds one message into the state. Message types that do not
+// contribute to symbol state are ignored.
+func (b *MarketStateBuilder) Add(msg iextp.Message) {
+	switch m := msg.(type) {
+	case *tops.TradeReportMessage:
+		copied := *m
+		b.symbol(m.Symbol).LastTrade = &copied
+	case *tops.QuoteUpdateMessage:
+		copied := *m
+		b.symbol(m.Symbol).Quote = &copied
+	case *tops.TradingStatusMessage:
+		copied := *m
+		b.symbol(m.Symbol).TradingStatus = &copied
+	case *tops.OfficialPriceMessage:
+		state := b.symbol(m.Symbol)
+		switch m.PriceType {
+		case tops.OpeningPrice:
+			state.OfficialOpen = m.OfficialPrice
+		case tops.ClosingPrice:
+			state.OfficialClose = m.OfficialPrice
+		}
+	case *deep.PriceLevelUpdateMessage:
+		// Ensure the symbol appears in the state even if only book
+		// updates have been seen for it.
+		b.symbol(m.Symbol)
+
+		book, ok := b.books[m.Symbol]
+		if !ok {
+			book = &bookLevels{
+				bids: make(map[float64]uint32),
+				asks: make(map[float64]uint32),
+			}
+			b.books[m.Symbol] = book
+		}
+
+		side := book.asks
+		if m.IsBuySide() {
+			side = book.bids
+		}
+		if m.Size == 0 {
+			delete(side, m.Price)
+		} else {
+			side[m.Price] = m.Size
+		}
+	}
+
+	if ts, ok := MessageTimestamp(msg); ok && ts.After(b.asOf) {
+		b.asOf = ts
+	}
+}
+
+// symbol returns the state for the given symbol, creating it on first
+// sight.
+func (b *MarketStateBuilder) symbol(symbol string) *SymbolState {
+	state, ok := b.symbols[symbol]
+	if !ok {
+		state = &SymbolState{Symbol: symbol}
+		b.symbols[symbol] = state
+	}
+
+	return state
+}
+
+// State materializes the current state of all symbols seen so far.
+// The result shares no memory with the builder, so it remains valid as
+// more messages are folded.
+func (b *MarketStateBuilder) State() *MarketState {
+	state := &MarketState{
+		AsOf:    b.asOf,
+		Symbols: make(map[string]*SymbolState, len(b.symbols)),
+	}
+
+	for symbol, symState := range b.symbols {
+		copied := *symState
+		if book, ok := b.books[symbol]; ok {
+			copied.Book = &Book{
+				Bids: quotesFromBookLevels(book.bids, true),
+				Asks: quotesFromBookLevels(book.asks, false),
+			}
+		}
+		state.Symbols[symbol] = &copied
+	}
+
+	return state
+}
+
+func quotesFromBookLevels(levels map[float64]uint32, descending bool) []*Quote {
+	quotes := make([]*Quote, 0, len(levels))
+	for price, size := range levels {
+		quotes = append(quotes, &Quote{
+			Price: price,
+			Size:  float64(size),
+		})
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		if descending {
+			return quotes[i].Price > quotes[j].Price
+		}
+		return quotes[i].Price < quotes[j].Price
+	})
+
+	return quotes
+}
+
+// MarketStateAt replays messages from the given packet source (e.g. a
+// HIST dump) up to and including the given time, and returns the
+// reconstructed market state at that instant. The scan stops at the
+// first message after t, so a mid-day reconstruction does not read the
+// rest of the file.
+func MarketStateAt(packetSource PacketDataSource, t time.Time) (*MarketState, error) {
+	scanner := NewPcapScanner(packetSource)
+	builder := NewMarketStateBuilder()
+	for {
+		msg, err := scanner.NextMessage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if ts, ok := MessageTimestamp(msg); ok && ts.After(t) {
+			break
+		}
+
+		builder.Add(msg)
+	}
+
+	return builder.State(), nil
+}
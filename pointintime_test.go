@@ -0,0 +1,121 @@
+package iex
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/deep"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestMarketStateBuilder(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 13, 30, 0, 0, time.UTC)
+	b := NewMarketStateBuilder()
+
+	b.Add(&tops.TradeReportMessage{
+		Symbol: "SNAP", Timestamp: t0, Price: 22.50, Size: 100,
+	})
+	b.Add(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", Timestamp: t0.Add(time.Second),
+		BidPrice: 22.49, BidSize: 200, AskPrice: 22.51, AskSize: 300,
+	})
+	b.Add(&tops.TradeReportMessage{
+		Symbol: "SNAP", Timestamp: t0.Add(2 * time.Second), Price: 22.55, Size: 50,
+	})
+	b.Add(&tops.OfficialPriceMessage{
+		Symbol: "SNAP", Timestamp: t0, PriceType: tops.OpeningPrice,
+		OfficialPrice: 22.48,
+	})
+	b.Add(&deep.PriceLevelUpdateMessage{
+		MessageType: deep.PriceLevelUpdateBuySide,
+		Symbol:      "FB", Timestamp: t0, Price: 140.00, Size: 500,
+	})
+	b.Add(&deep.PriceLevelUpdateMessage{
+		MessageType: deep.PriceLevelUpdateBuySide,
+		Symbol:      "FB", Timestamp: t0, Price: 139.99, Size: 700,
+	})
+	b.Add(&deep.PriceLevelUpdateMessage{
+		MessageType: deep.PriceLevelUpdateSellSide,
+		Symbol:      "FB", Timestamp: t0, Price: 140.02, Size: 400,
+	})
+	// A size of zero removes the level.
+	b.Add(&deep.PriceLevelUpdateMessage{
+		MessageType: deep.PriceLevelUpdateBuySide,
+		Symbol:      "FB", Timestamp: t0, Price: 139.99, Size: 0,
+	})
+
+	state := b.State()
+	if !state.AsOf.Equal(t0.Add(2 * time.Second)) {
+		t.Errorf("unexpected AsOf: %v", state.AsOf)
+	}
+	if len(state.Symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got: %v", len(state.Symbols))
+	}
+
+	snap := state.Symbols["SNAP"]
+	if snap.LastTrade == nil || snap.LastTrade.Price != 22.55 {
+		t.Errorf("unexpected last trade: %+v", snap.LastTrade)
+	}
+	if snap.Quote == nil || snap.Quote.BidPrice != 22.49 || snap.Quote.AskPrice != 22.51 {
+		t.Errorf("unexpected quote: %+v", snap.Quote)
+	}
+	if snap.OfficialOpen != 22.48 || snap.OfficialClose != 0 {
+		t.Errorf("unexpected official prices: %v, %v",
+			snap.OfficialOpen, snap.OfficialClose)
+	}
+	if snap.Book != nil {
+		t.Errorf("expected no book without price level updates: %+v", snap.Book)
+	}
+
+	fb := state.Symbols["FB"]
+	if fb.Book == nil {
+		t.Fatal("expected a book for FB")
+	}
+	if len(fb.Book.Bids) != 1 || fb.Book.Bids[0].Price != 140.00 || fb.Book.Bids[0].Size != 500 {
+		t.Errorf("unexpected bids: %+v", fb.Book.Bids)
+	}
+	if len(fb.Book.Asks) != 1 || fb.Book.Asks[0].Price != 140.02 {
+		t.Errorf("unexpected asks: %+v", fb.Book.Asks)
+	}
+}
+
+// rawTrade builds the wire form of a TOPS trade report.
+func rawTrade(ts time.Time, symbol string, price float64, size uint32) []byte {
+	buf := make([]byte, 38)
+	buf[0] = tops.TradeReport
+	binary.LittleEndian.PutUint64(buf[2:10], uint64(ts.UnixNano()))
+	copy(buf[10:18], symbol+"        ")
+	binary.LittleEndian.PutUint32(buf[18:22], size)
+	binary.LittleEndian.PutUint64(buf[22:30], uint64(int64(price*10000)))
+	return buf
+}
+
+func TestMarketStateAt(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 13, 30, 0, 0, time.UTC)
+	source := &fakePacketSource{payloads: [][]byte{
+		makeSegment(t0,
+			rawTrade(t0, "SNAP", 22.50, 100),
+			rawTrade(t0.Add(5*time.Second), "SNAP", 22.55, 50)),
+		makeSegment(t0.Add(time.Minute),
+			rawTrade(t0.Add(time.Minute), "SNAP", 22.70, 25)),
+	}}
+
+	// The cutoff falls between the second and third trades; the scan
+	// must stop before folding the third.
+	state, err := MarketStateAt(source, t0.Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.AsOf.Equal(t0.Add(5 * time.Second)) {
+		t.Errorf("unexpected AsOf: %v", state.AsOf)
+	}
+	snap := state.Symbols["SNAP"]
+	if snap == nil || snap.LastTrade == nil {
+		t.Fatalf("expected SNAP state, got: %+v", snap)
+	}
+	if snap.LastTrade.Price != 22.55 || snap.LastTrade.Size != 50 {
+		t.Errorf("unexpected last trade: %+v", snap.LastTrade)
+	}
+}
@@ -0,0 +1,181 @@
+package quality
+
+import (
+	"sort"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+// Divergence is a price level where the reconstructed book disagrees
+// with a REST snapshot.
+type Divergence struct {
+	Symbol string  `json:"symbol"`
+	Side   string  `json:"side"` // "bid" or "ask"
+	Price  float64 `json:"price"`
+	// The aggregated size in the reconstructed book.
+	HaveSize int `json:"haveSize"`
+	// The aggregated size in the snapshot.
+	WantSize int `json:"wantSize"`
+}
+
+// BookVerifier reconstructs per-symbol order books from DEEP price
+// level updates so they can be periodically cross-checked against
+// REST GetBook snapshots for the same symbols — a safety net for
+// both the decoder and IEX data quality.
+type BookVerifier struct {
+	books map[string]*bookState
+}
+
+type bookState struct {
+	bids map[float64]int
+	asks map[float64]int
+}
+
+// NewBookVerifier creates an empty verifier.
+func NewBookVerifier() *BookVerifier {
+	return &BookVerifier{
+		books: make(map[string]*bookState),
+	}
+}
+
+// OnMessage folds the given message into the reconstructed books.
+// Messages other than price level updates are ignored.
+func (v *BookVerifier) OnMessage(msg iextp.Message) {
+	update, ok := msg.(*deep.PriceLevelUpdateMessage)
+	if !ok {
+		return
+	}
+
+	book, ok := v.books[update.Symbol]
+	if !ok {
+		book = &bookState{
+			bids: make(map[float64]int),
+			asks: make(map[float64]int),
+		}
+		v.books[update.Symbol] = book
+	}
+
+	side := book.asks
+	if update.IsBuySide() {
+		side = book.bids
+	}
+
+	if update.Size == 0 {
+		delete(side, update.Price)
+	} else {
+		side[update.Price] = int(update.Size)
+	}
+}
+
+// Book returns the reconstructed book for the given symbol, with
+// bids sorted descending and asks ascending by price.
+func (v *BookVerifier) Book(symbol string) *iex.Book {
+	book, ok := v.books[symbol]
+	if !ok {
+		return &iex.Book{}
+	}
+
+	return &iex.Book{
+		Bids: quotesFromLevels(book.bids, true),
+		Asks: quotesFromLevels(book.asks, false),
+	}
+}
+
+// Books returns the reconstructed books for all symbols seen so far,
+// with bids sorted descending and asks ascending by price.
+func (v *BookVerifier) Books() map[string]*iex.Book {
+	books := make(map[string]*iex.Book, len(v.books))
+	for symbol := range v.books {
+		books[symbol] = v.Book(symbol)
+	}
+
+	return books
+}
+
+// Verify cross-checks the reconstructed book for the given symbol
+// against a REST snapshot, returning one Divergence per price level
+// where they disagree.
+func (v *BookVerifier) Verify(symbol string, snapshot *iex.Book) []Divergence {
+	book, ok := v.books[symbol]
+	if !ok {
+		book = &bookState{
+			bids: make(map[float64]int),
+			asks: make(map[float64]int),
+		}
+	}
+
+	var divergences []Divergence
+	divergences = append(divergences,
+		diffSide(symbol, "bid", book.bids, snapshot.Bids)...)
+	divergences = append(divergences,
+		diffSide(symbol, "ask", book.asks, snapshot.Asks)...)
+
+	sort.Slice(divergences, func(i, j int) bool {
+		if divergences[i].Side != divergences[j].Side {
+			return divergences[i].Side < divergences[j].Side
+		}
+		return divergences[i].Price < divergences[j].Price
+	})
+
+	return divergences
+}
+
+func diffSide(symbol, side string, have map[float64]int, want []*iex.Quote) []Divergence {
+	wantSizes := make(map[float64]int, len(want))
+	for _, quote := range want {
+		wantSizes[quote.Price] = int(quote.Size)
+	}
+
+	var divergences []Divergence
+	for price, size := range have {
+		if wantSizes[price] != size {
+			divergences = append(divergences, Divergence{
+				Symbol:   symbol,
+				Side:     side,
+				Price:    price,
+				HaveSize: size,
+				WantSize: wantSizes[price],
+			})
+		}
+	}
+	for price, size := range wantSizes {
+		if _, ok := have[price]; !ok {
+			divergences = append(divergences, Divergence{
+				Symbol:   symbol,
+				Side:     side,
+				Price:    price,
+				HaveSize: 0,
+				WantSize: size,
+			})
+		}
+	}
+
+	return divergences
+}
+
+func quotesFromLevels(levels map[float64]int, descending bool) []*iex.Quote {
+	quotes := make([]*iex.Quote, 0, len(levels))
+	for price, size := range levels {
+		quotes = append(quotes, &iex.Quote{
+			Price: price,
+			Size:  float64(size),
+		})
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		if descending {
+			return quotes[i].Price > quotes[j].Price
+		}
+		return quotes[i].Price < quotes[j].Price
+	})
+
+	return quotes
+}
+
+// Reset discards all reconstructed book state, for use at session
+// rollovers when sequence numbers and books start over.
+func (b *BookVerifier) Reset() {
+	b.books = make(map[string]*bookState)
+}
@@ -0,0 +1,82 @@
+package quality
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/deep"
+)
+
+func priceLevelUpdate(symbol string, buy bool, price float64, size uint32) *deep.PriceLevelUpdateMessage {
+	messageType := uint8(deep.PriceLevelUpdateSellSide)
+	if buy {
+		messageType = deep.PriceLevelUpdateBuySide
+	}
+
+	return &deep.PriceLevelUpdateMessage{
+		MessageType: messageType,
+		Symbol:      symbol,
+		Price:       price,
+		Size:        size,
+	}
+}
+
+func TestBookVerifier_Reconstruction(t *testing.T) {
+	v := NewBookVerifier()
+	v.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 100))
+	v.OnMessage(priceLevelUpdate("ZIEXT", true, 98.95, 200))
+	v.OnMessage(priceLevelUpdate("ZIEXT", false, 99.10, 300))
+	// Update an existing level, then remove one.
+	v.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 150))
+	v.OnMessage(priceLevelUpdate("ZIEXT", true, 98.95, 0))
+
+	book := v.Book("ZIEXT")
+	if len(book.Bids) != 1 || len(book.Asks) != 1 {
+		t.Fatalf("unexpected book: %+v", book)
+	}
+
+	if book.Bids[0].Price != 99.00 || book.Bids[0].Size != 150 {
+		t.Errorf("unexpected bid: %+v", book.Bids[0])
+	}
+	if book.Asks[0].Price != 99.10 || book.Asks[0].Size != 300 {
+		t.Errorf("unexpected ask: %+v", book.Asks[0])
+	}
+}
+
+func TestBookVerifier_Verify(t *testing.T) {
+	v := NewBookVerifier()
+	v.OnMessage(priceLevelUpdate("ZIEXT", true, 99.00, 100))
+	v.OnMessage(priceLevelUpdate("ZIEXT", false, 99.10, 300))
+
+	// A matching snapshot produces no divergences.
+	snapshot := &iex.Book{
+		Bids: []*iex.Quote{{Price: 99.00, Size: 100}},
+		Asks: []*iex.Quote{{Price: 99.10, Size: 300}},
+	}
+	if divergences := v.Verify("ZIEXT", snapshot); len(divergences) != 0 {
+		t.Fatalf("expected no divergences, got: %+v", divergences)
+	}
+
+	// Disagreeing size and a level missing from the reconstruction.
+	snapshot = &iex.Book{
+		Bids: []*iex.Quote{
+			{Price: 99.00, Size: 200},
+			{Price: 98.90, Size: 50},
+		},
+		Asks: []*iex.Quote{{Price: 99.10, Size: 300}},
+	}
+
+	divergences := v.Verify("ZIEXT", snapshot)
+	if len(divergences) != 2 {
+		t.Fatalf("expected 2 divergences, got: %+v", divergences)
+	}
+
+	if divergences[0].Price != 98.90 || divergences[0].HaveSize != 0 ||
+		divergences[0].WantSize != 50 {
+		t.Errorf("unexpected divergence: %+v", divergences[0])
+	}
+	if divergences[1].Price != 99.00 || divergences[1].HaveSize != 100 ||
+		divergences[1].WantSize != 200 {
+		t.Errorf("unexpected divergence: %+v", divergences[1])
+	}
+}
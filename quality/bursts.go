@@ -0,0 +1,203 @@
+package quality
+
+import (
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+const (
+	// DefaultBurstMinRate is the minimum updates/second before a
+	// second can be flagged as a burst, regardless of the baseline;
+	// it keeps thinly quoted symbols from flagging on tiny counts.
+	DefaultBurstMinRate = 20
+
+	// baselineWindow is how much trailing history the rolling
+	// updates/second baseline is computed over.
+	baselineWindow = 60 * time.Second
+)
+
+// Burst is one window of abnormally dense quote updates for a symbol:
+// a run of consecutive seconds whose update rate exceeded the
+// configured multiple of the symbol's rolling baseline.
+type Burst struct {
+	Symbol string    `json:"symbol"`
+	Start  time.Time `json:"start"`
+	// End of the last bursting second (exclusive).
+	End time.Time `json:"end"`
+	// Total quote updates within the window.
+	Updates int `json:"updates"`
+	// Updates in the window's busiest second.
+	PeakRate int `json:"peakRate"`
+	// The rolling updates/second baseline when the burst began.
+	Baseline float64 `json:"baseline"`
+}
+
+// BurstDetector scans a stream of decoded messages for quote stuffing:
+// per-symbol bursts of quote updates well above the symbol's own
+// rolling update rate.
+type BurstDetector struct {
+	// A second is bursting when its update count exceeds this
+	// multiple of the rolling baseline.
+	multiple float64
+	// Minimum updates/second before a second can be flagged.
+	minRate int
+
+	symbols map[string]*burstState
+	bursts  []*Burst
+}
+
+// NewBurstDetector creates a BurstDetector flagging seconds whose
+// quote update count exceeds the given multiple (e.g. 10 for 10x) of
+// the symbol's rolling baseline rate.
+func NewBurstDetector(multiple float64) *BurstDetector {
+	return &BurstDetector{
+		multiple: multiple,
+		minRate:  DefaultBurstMinRate,
+		symbols:  make(map[string]*burstState),
+	}
+}
+
+// SetMinRate overrides the minimum updates/second before a second can
+// be flagged as a burst.
+func (d *BurstDetector) SetMinRate(minRate int) {
+	d.minRate = minRate
+}
+
+// OnMessage incorporates the next message from the stream. Only quote
+// updates are counted; other message types are ignored.
+func (d *BurstDetector) OnMessage(msg iextp.Message) {
+	update, ok := msg.(*tops.QuoteUpdateMessage)
+	if !ok {
+		return
+	}
+
+	state, ok := d.symbols[update.Symbol]
+	if !ok {
+		state = &burstState{symbol: update.Symbol}
+		d.symbols[update.Symbol] = state
+	}
+
+	sec := update.Timestamp.Unix()
+	if !state.started {
+		state.started = true
+		state.sec = sec
+	} else if sec != state.sec {
+		d.finishSecond(state)
+		state.sec = sec
+	}
+
+	state.count++
+}
+
+// Bursts flushes any in-progress windows and returns all detected
+// bursts, ordered by start time.
+func (d *BurstDetector) Bursts() []*Burst {
+	for _, state := range d.symbols {
+		if state.started && state.count > 0 {
+			d.finishSecond(state)
+			state.sec++
+		}
+		if state.open != nil {
+			d.bursts = append(d.bursts, state.open)
+			state.open = nil
+		}
+	}
+
+	sort.Slice(d.bursts, func(i, j int) bool {
+		if !d.bursts[i].Start.Equal(d.bursts[j].Start) {
+			return d.bursts[i].Start.Before(d.bursts[j].Start)
+		}
+		return d.bursts[i].Symbol < d.bursts[j].Symbol
+	})
+
+	return d.bursts
+}
+
+// burstState is one symbol's rolling counts and in-progress burst.
+type burstState struct {
+	symbol string
+	// Completed nonzero one-second buckets within the baseline
+	// window; seconds with no updates contribute to elapsed time but
+	// are not stored.
+	buckets []burstBucket
+	// The in-progress second and its update count so far.
+	started bool
+	sec     int64
+	count   int
+	open    *Burst
+}
+
+type burstBucket struct {
+	sec   int64
+	count int
+}
+
+// finishSecond evaluates the symbol's completed second against its
+// rolling baseline, extending or closing the open burst, and resets
+// the in-progress count.
+func (d *BurstDetector) finishSecond(state *burstState) {
+	count := state.count
+	state.count = 0
+	baseline, ok := state.baseline()
+
+	if ok && count >= d.minRate && float64(count) > d.multiple*baseline {
+		start := time.Unix(state.sec, 0).UTC()
+		if state.open == nil {
+			state.open = &Burst{
+				Symbol:   state.symbol,
+				Start:    start,
+				Baseline: baseline,
+			}
+		}
+
+		state.open.End = start.Add(time.Second)
+		state.open.Updates += count
+		if count > state.open.PeakRate {
+			state.open.PeakRate = count
+		}
+
+		// Bursting seconds are excluded from the history so they do
+		// not inflate the baseline for the seconds that follow.
+		return
+	}
+
+	if state.open != nil {
+		d.bursts = append(d.bursts, state.open)
+		state.open = nil
+	}
+
+	if count > 0 {
+		state.buckets = append(state.buckets, burstBucket{state.sec, count})
+	}
+}
+
+// baseline returns the symbol's rolling updates/second over the
+// trailing window, excluding the in-progress second. It reports false
+// until at least one earlier second of history exists, so a symbol's
+// very first active second is never flagged.
+func (s *burstState) baseline() (float64, bool) {
+	windowSecs := int64(baselineWindow / time.Second)
+	cutoff := s.sec - windowSecs
+	for len(s.buckets) > 0 && s.buckets[0].sec < cutoff {
+		s.buckets = s.buckets[1:]
+	}
+
+	if len(s.buckets) == 0 {
+		return 0, false
+	}
+
+	elapsed := s.sec - s.buckets[0].sec
+	if elapsed <= 0 {
+		return 0, false
+	}
+
+	total := 0
+	for _, b := range s.buckets {
+		total += b.count
+	}
+
+	return float64(total) / float64(elapsed), true
+}
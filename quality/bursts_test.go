@@ -0,0 +1,80 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// sendQuotes delivers n quote updates for the symbol spread evenly
+// within the second starting at ts.
+func sendQuotes(d *BurstDetector, symbol string, ts time.Time, n int) {
+	interval := time.Second / time.Duration(n+1)
+	for i := 0; i < n; i++ {
+		d.OnMessage(&tops.QuoteUpdateMessage{
+			Symbol:    symbol,
+			Timestamp: ts.Add(time.Duration(i) * interval),
+			BidPrice:  20.00,
+			AskPrice:  20.10,
+		})
+	}
+}
+
+func TestBurstDetector(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 14, 30, 0, 0, time.UTC)
+
+	d := NewBurstDetector(10)
+	// Ten seconds of steady quoting at 2 updates/sec to establish the
+	// baseline, then two bursting seconds, then back to steady.
+	for i := 0; i < 10; i++ {
+		sendQuotes(d, "SNAP", t0.Add(time.Duration(i)*time.Second), 2)
+	}
+	sendQuotes(d, "SNAP", t0.Add(10*time.Second), 50)
+	sendQuotes(d, "SNAP", t0.Add(11*time.Second), 30)
+	for i := 12; i < 15; i++ {
+		sendQuotes(d, "SNAP", t0.Add(time.Duration(i)*time.Second), 2)
+	}
+
+	// A steady symbol should not be flagged, even at a higher rate.
+	for i := 0; i < 15; i++ {
+		sendQuotes(d, "FB", t0.Add(time.Duration(i)*time.Second), 10)
+	}
+
+	bursts := d.Bursts()
+	if len(bursts) != 1 {
+		t.Fatalf("expected 1 burst, got %v: %+v", len(bursts), bursts)
+	}
+
+	burst := bursts[0]
+	if burst.Symbol != "SNAP" {
+		t.Errorf("unexpected burst symbol: %v", burst.Symbol)
+	}
+	if !burst.Start.Equal(t0.Add(10 * time.Second)) {
+		t.Errorf("unexpected burst start: %v", burst.Start)
+	}
+	if !burst.End.Equal(t0.Add(12 * time.Second)) {
+		t.Errorf("unexpected burst end: %v", burst.End)
+	}
+	if burst.Updates != 80 || burst.PeakRate != 50 {
+		t.Errorf("unexpected burst counts: updates %v peak %v",
+			burst.Updates, burst.PeakRate)
+	}
+	if burst.Baseline != 2 {
+		t.Errorf("unexpected baseline: %v", burst.Baseline)
+	}
+}
+
+func TestBurstDetector_ColdStart(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 14, 30, 0, 0, time.UTC)
+
+	// A symbol's very first active second is never flagged: there is
+	// no baseline to compare against yet.
+	d := NewBurstDetector(10)
+	sendQuotes(d, "SNAP", t0, 100)
+	sendQuotes(d, "SNAP", t0.Add(time.Second), 2)
+
+	if bursts := d.Bursts(); len(bursts) != 0 {
+		t.Errorf("expected no bursts, got %+v", bursts)
+	}
+}
@@ -0,0 +1,156 @@
+// Package quality implements a data-quality validation pass over
+// decoded IEXTP message streams, flagging suspicious trades before a
+// dataset is trusted for research.
+package quality
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// AnomalyType identifies the kind of data-quality issue detected.
+type AnomalyType string
+
+const (
+	// Trade price deviates more than the configured fraction from
+	// the prevailing quote midpoint (or previous trade when no
+	// quote is available).
+	PriceSpike AnomalyType = "price_spike"
+	// Message timestamp is earlier than the previous message's.
+	BackwardsTimestamp AnomalyType = "backwards_timestamp"
+	// Trade with zero size.
+	ZeroSize AnomalyType = "zero_size"
+)
+
+// Anomaly is a single flagged data-quality issue.
+type Anomaly struct {
+	Type      AnomalyType `json:"type"`
+	Symbol    string      `json:"symbol,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	TradeID   int64       `json:"tradeId,omitempty"`
+	Detail    string      `json:"detail"`
+}
+
+// Report summarizes the anomalies found in a message stream.
+type Report struct {
+	Messages  int                 `json:"messages"`
+	Trades    int                 `json:"trades"`
+	Anomalies []Anomaly           `json:"anomalies"`
+	Counts    map[AnomalyType]int `json:"counts"`
+}
+
+// Validator scans a stream of decoded messages for trade-level
+// anomalies: price spikes, backwards timestamps, and zero-size
+// trades.
+type Validator struct {
+	// Maximum tolerated fractional deviation of a trade price from
+	// the reference price before it is flagged as a spike.
+	maxDeviation float64
+
+	lastTimestamp time.Time
+	lastTrade     map[string]float64
+	lastQuote     map[string]*tops.QuoteUpdateMessage
+
+	report *Report
+}
+
+// NewValidator creates a Validator flagging trades that deviate more
+// than maxDeviation (e.g. 0.10 for 10%) from the reference price.
+func NewValidator(maxDeviation float64) *Validator {
+	return &Validator{
+		maxDeviation: maxDeviation,
+		lastTrade:    make(map[string]float64),
+		lastQuote:    make(map[string]*tops.QuoteUpdateMessage),
+		report: &Report{
+			Counts: make(map[AnomalyType]int),
+		},
+	}
+}
+
+// OnMessage incorporates the next message from the stream.
+func (v *Validator) OnMessage(msg iextp.Message) {
+	v.report.Messages++
+
+	switch msg := msg.(type) {
+	case *tops.QuoteUpdateMessage:
+		v.checkTimestamp(msg.Symbol, msg.Timestamp)
+		v.lastQuote[msg.Symbol] = msg
+	case *tops.TradeReportMessage:
+		v.checkTimestamp(msg.Symbol, msg.Timestamp)
+		v.checkTrade(msg)
+	}
+}
+
+// Report returns the accumulated data-quality report.
+func (v *Validator) Report() *Report {
+	return v.report
+}
+
+func (v *Validator) flag(a Anomaly) {
+	v.report.Anomalies = append(v.report.Anomalies, a)
+	v.report.Counts[a.Type]++
+}
+
+func (v *Validator) checkTimestamp(symbol string, ts time.Time) {
+	if ts.Before(v.lastTimestamp) {
+		v.flag(Anomaly{
+			Type:      BackwardsTimestamp,
+			Symbol:    symbol,
+			Timestamp: ts,
+			Detail: fmt.Sprintf("timestamp %v before previous message at %v",
+				ts, v.lastTimestamp),
+		})
+	} else {
+		v.lastTimestamp = ts
+	}
+}
+
+func (v *Validator) checkTrade(msg *tops.TradeReportMessage) {
+	v.report.Trades++
+
+	if msg.Size == 0 {
+		v.flag(Anomaly{
+			Type:      ZeroSize,
+			Symbol:    msg.Symbol,
+			Timestamp: msg.Timestamp,
+			TradeID:   msg.TradeID,
+			Detail:    "trade with zero size",
+		})
+	}
+
+	if ref, ok := v.referencePrice(msg.Symbol); ok && ref > 0 && msg.Price > 0 {
+		if dev := math.Abs(msg.Price-ref) / ref; dev > v.maxDeviation {
+			v.flag(Anomaly{
+				Type:      PriceSpike,
+				Symbol:    msg.Symbol,
+				Timestamp: msg.Timestamp,
+				TradeID:   msg.TradeID,
+				Detail: fmt.Sprintf(
+					"price %.4f deviates %.1f%% from reference %.4f",
+					msg.Price, 100*dev, ref),
+			})
+		}
+	}
+
+	v.lastTrade[msg.Symbol] = msg.Price
+}
+
+// referencePrice returns the prevailing price to validate a trade
+// against: the quote midpoint if an active two-sided quote exists,
+// otherwise the previous trade price.
+func (v *Validator) referencePrice(symbol string) (float64, bool) {
+	if q, ok := v.lastQuote[symbol]; ok && q.IsActive() &&
+		q.BidPrice > 0 && q.AskPrice > 0 {
+		return (q.BidPrice + q.AskPrice) / 2, true
+	}
+
+	if price, ok := v.lastTrade[symbol]; ok {
+		return price, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,83 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var testStart = time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+func testTrade(ts time.Time, price float64, size uint32) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		MessageType: tops.TradeReport,
+		Timestamp:   ts,
+		Symbol:      "AAPL",
+		Price:       price,
+		Size:        size,
+		TradeID:     1,
+	}
+}
+
+func TestValidator_PriceSpike(t *testing.T) {
+	v := NewValidator(0.10)
+	v.OnMessage(testTrade(testStart, 100, 100))
+	v.OnMessage(testTrade(testStart.Add(time.Second), 101, 100))
+	v.OnMessage(testTrade(testStart.Add(2*time.Second), 150, 100))
+
+	report := v.Report()
+	if report.Counts[PriceSpike] != 1 {
+		t.Fatalf("expected 1 price spike, got: %v", report.Counts[PriceSpike])
+	}
+	if report.Trades != 3 {
+		t.Errorf("expected 3 trades, got: %v", report.Trades)
+	}
+}
+
+func TestValidator_QuoteReference(t *testing.T) {
+	v := NewValidator(0.10)
+	v.OnMessage(&tops.QuoteUpdateMessage{
+		MessageType: tops.QuoteUpdate,
+		Timestamp:   testStart,
+		Symbol:      "AAPL",
+		BidSize:     100,
+		BidPrice:    99,
+		AskPrice:    101,
+		AskSize:     100,
+	})
+
+	// 150 deviates 50% from the quote midpoint of 100, even though
+	// it is the first trade.
+	v.OnMessage(testTrade(testStart.Add(time.Second), 150, 100))
+
+	report := v.Report()
+	if report.Counts[PriceSpike] != 1 {
+		t.Fatalf("expected 1 price spike, got: %v", report.Counts[PriceSpike])
+	}
+}
+
+func TestValidator_BackwardsTimestamp(t *testing.T) {
+	v := NewValidator(0.10)
+	v.OnMessage(testTrade(testStart, 100, 100))
+	v.OnMessage(testTrade(testStart.Add(-time.Second), 100, 100))
+
+	report := v.Report()
+	if report.Counts[BackwardsTimestamp] != 1 {
+		t.Fatalf("expected 1 backwards timestamp, got: %v",
+			report.Counts[BackwardsTimestamp])
+	}
+}
+
+func TestValidator_ZeroSize(t *testing.T) {
+	v := NewValidator(0.10)
+	v.OnMessage(testTrade(testStart, 100, 0))
+
+	report := v.Report()
+	if report.Counts[ZeroSize] != 1 {
+		t.Fatalf("expected 1 zero size trade, got: %v", report.Counts[ZeroSize])
+	}
+	if len(report.Anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got: %v", len(report.Anomalies))
+	}
+}
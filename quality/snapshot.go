@@ -0,0 +1,112 @@
+package quality
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// BookSnapshotter periodically serializes the full per-symbol book
+// state reconstructed by a BookVerifier into compressed JSON files,
+// one file per snapshot, so book-state research can start from a
+// snapshot instead of replaying the whole feed. Snapshots are
+// triggered by message timestamps (every interval of feed time)
+// and/or by update counts (every K price level updates), so replays
+// of the same dump produce identical snapshot files.
+type BookSnapshotter struct {
+	books *BookVerifier
+	dir   string
+	// Snapshot every interval of feed time, if non-zero.
+	interval time.Duration
+	// Snapshot every updateInterval price level updates, if non-zero.
+	updateInterval int
+
+	lastSnapshot time.Time
+	updates      int
+	// Paths of the snapshot files written so far.
+	written []string
+}
+
+// NewBookSnapshotter creates a snapshotter writing into the given
+// directory, snapshotting every interval of feed time. Use
+// SetUpdateInterval to additionally (or instead) snapshot on update
+// counts; an interval of zero disables time-based snapshots.
+func NewBookSnapshotter(books *BookVerifier, dir string, interval time.Duration) *BookSnapshotter {
+	return &BookSnapshotter{
+		books:    books,
+		dir:      dir,
+		interval: interval,
+	}
+}
+
+// SetUpdateInterval enables a snapshot every k price level updates.
+func (s *BookSnapshotter) SetUpdateInterval(k int) {
+	s.updateInterval = k
+}
+
+// OnMessage folds the given message into the books and writes a
+// snapshot if one is due. Messages other than price level updates
+// are ignored.
+func (s *BookSnapshotter) OnMessage(msg iextp.Message) error {
+	s.books.OnMessage(msg)
+
+	ts, ok := iex.MessageTimestamp(msg)
+	if !ok {
+		return nil
+	}
+	s.updates++
+
+	due := false
+	if s.interval > 0 {
+		if s.lastSnapshot.IsZero() {
+			s.lastSnapshot = ts
+		} else if ts.Sub(s.lastSnapshot) >= s.interval {
+			due = true
+		}
+	}
+	if s.updateInterval > 0 && s.updates%s.updateInterval == 0 {
+		due = true
+	}
+
+	if !due {
+		return nil
+	}
+
+	s.lastSnapshot = ts
+	return s.Snapshot(ts)
+}
+
+// Snapshot writes the current book state for all symbols to a
+// gzipped JSON file named for the given timestamp.
+func (s *BookSnapshotter) Snapshot(ts time.Time) error {
+	filename := filepath.Join(s.dir,
+		"books-"+ts.UTC().Format("20060102-150405.000000000")+".json.gz")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	if err := json.NewEncoder(gz).Encode(s.books.Books()); err != nil {
+		gz.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	s.written = append(s.written, filename)
+	return nil
+}
+
+// Written returns the paths of the snapshot files written so far.
+func (s *BookSnapshotter) Written() []string {
+	return s.written
+}
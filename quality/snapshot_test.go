@@ -0,0 +1,75 @@
+package quality
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+)
+
+func TestBookSnapshotter_TimeInterval(t *testing.T) {
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	s := NewBookSnapshotter(NewBookVerifier(), dir, time.Minute)
+
+	update := priceLevelUpdate("ZIEXT", true, 99.00, 100)
+	update.Timestamp = ts
+	if err := s.OnMessage(update); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Written()) != 0 {
+		t.Fatalf("unexpected snapshot before interval elapsed: %v", s.Written())
+	}
+
+	update = priceLevelUpdate("ZIEXT", true, 99.00, 150)
+	update.Timestamp = ts.Add(2 * time.Minute)
+	if err := s.OnMessage(update); err != nil {
+		t.Fatal(err)
+	}
+
+	written := s.Written()
+	if len(written) != 1 {
+		t.Fatalf("expected 1 snapshot, got: %v", written)
+	}
+
+	f, err := os.Open(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var books map[string]*iex.Book
+	if err := json.NewDecoder(gz).Decode(&books); err != nil {
+		t.Fatal(err)
+	}
+	book := books["ZIEXT"]
+	if book == nil || len(book.Bids) != 1 || book.Bids[0].Size != 150 {
+		t.Errorf("unexpected snapshot contents: %+v", books)
+	}
+}
+
+func TestBookSnapshotter_UpdateInterval(t *testing.T) {
+	s := NewBookSnapshotter(NewBookVerifier(), t.TempDir(), 0)
+	s.SetUpdateInterval(2)
+
+	ts := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		update := priceLevelUpdate("ZIEXT", true, 99.00, 100)
+		update.Timestamp = ts.Add(time.Duration(i) * time.Second)
+		if err := s.OnMessage(update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(s.Written()) != 2 {
+		t.Errorf("expected 2 snapshots, got: %v", s.Written())
+	}
+}
@@ -0,0 +1,76 @@
+package iex
+
+import "sync"
+
+// mappingClient is the subset of Client used by MappingCache.
+type mappingClient interface {
+	GetISINMapping(isin string) ([]*SymbolMapping, error)
+	GetFIGIMapping(figi string) ([]*SymbolMapping, error)
+}
+
+// MappingCache caches identifier mappings from the ref-data
+// endpoints. Mappings change rarely (corporate actions), so callers
+// joining a dataset symbol-by-symbol should not re-fetch the same
+// identifier for every row. It is safe for concurrent use.
+type MappingCache struct {
+	client mappingClient
+
+	mu     sync.Mutex
+	byISIN map[string][]*SymbolMapping
+	byFIGI map[string][]*SymbolMapping
+}
+
+// NewMappingCache creates an empty cache backed by the given client.
+func NewMappingCache(client *Client) *MappingCache {
+	return newMappingCache(client)
+}
+
+func newMappingCache(client mappingClient) *MappingCache {
+	return &MappingCache{
+		client: client,
+		byISIN: make(map[string][]*SymbolMapping),
+		byFIGI: make(map[string][]*SymbolMapping),
+	}
+}
+
+// ISINToSymbols returns the symbols associated with the given ISIN,
+// fetching the mapping on first use. Errors are not cached.
+func (c *MappingCache) ISINToSymbols(isin string) ([]*SymbolMapping, error) {
+	c.mu.Lock()
+	mappings, ok := c.byISIN[isin]
+	c.mu.Unlock()
+	if ok {
+		return mappings, nil
+	}
+
+	mappings, err := c.client.GetISINMapping(isin)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.byISIN[isin] = mappings
+	c.mu.Unlock()
+	return mappings, nil
+}
+
+// FIGIToSymbols returns the symbols associated with the given FIGI,
+// fetching the mapping on first use. Errors are not cached.
+func (c *MappingCache) FIGIToSymbols(figi string) ([]*SymbolMapping, error) {
+	c.mu.Lock()
+	mappings, ok := c.byFIGI[figi]
+	c.mu.Unlock()
+	if ok {
+		return mappings, nil
+	}
+
+	mappings, err := c.client.GetFIGIMapping(figi)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.byFIGI[figi] = mappings
+	c.mu.Unlock()
+	return mappings, nil
+}
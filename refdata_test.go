@@ -0,0 +1,60 @@
+package iex
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeMappingClient struct {
+	calls    int
+	mappings []*SymbolMapping
+	err      error
+}
+
+func (c *fakeMappingClient) GetISINMapping(isin string) ([]*SymbolMapping, error) {
+	c.calls++
+	return c.mappings, c.err
+}
+
+func (c *fakeMappingClient) GetFIGIMapping(figi string) ([]*SymbolMapping, error) {
+	c.calls++
+	return c.mappings, c.err
+}
+
+func TestMappingCache(t *testing.T) {
+	client := &fakeMappingClient{
+		mappings: []*SymbolMapping{{Symbol: "AAPL", FIGI: "BBG000B9XRY4"}},
+	}
+	cache := newMappingCache(client)
+
+	for i := 0; i < 3; i++ {
+		mappings, err := cache.ISINToSymbols("US0378331005")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(mappings) != 1 || mappings[0].Symbol != "AAPL" {
+			t.Fatalf("unexpected mappings: %+v", mappings)
+		}
+	}
+
+	if client.calls != 1 {
+		t.Errorf("expected 1 client call, got: %v", client.calls)
+	}
+}
+
+func TestMappingCache_ErrorsNotCached(t *testing.T) {
+	client := &fakeMappingClient{err: errors.New("Received error response: 404 Not Found")}
+	cache := newMappingCache(client)
+
+	if _, err := cache.FIGIToSymbols("BBG000B9XRY4"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := cache.FIGIToSymbols("BBG000B9XRY4"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if client.calls != 2 {
+		t.Errorf("expected errors not to be cached, got %v calls", client.calls)
+	}
+}
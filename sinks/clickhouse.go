@@ -0,0 +1,161 @@
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultClickHouseBatchSize is how many rows a ClickHouseSink
+// accumulates before inserting, when no batch size is configured.
+const DefaultClickHouseBatchSize = 1000
+
+// ClickHouseReferenceSchema is a reference DDL for tick storage in
+// ClickHouse: trades and quotes as written by a ClickHouseSink with
+// the json codec, and bars as exported by the consolidator. Adjust
+// engines and TTLs to taste.
+const ClickHouseReferenceSchema = `
+CREATE TABLE IF NOT EXISTS iex.trades (
+    symbol     LowCardinality(String),
+    timestamp  DateTime64(9, 'UTC'),
+    price      Float64,
+    size       UInt32,
+    tradeId    Int64
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (symbol, timestamp);
+
+CREATE TABLE IF NOT EXISTS iex.quotes (
+    symbol     LowCardinality(String),
+    timestamp  DateTime64(9, 'UTC'),
+    bidSize    UInt32,
+    bidPrice   Float64,
+    askPrice   Float64,
+    askSize    UInt32
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (symbol, timestamp);
+
+CREATE TABLE IF NOT EXISTS iex.bars (
+    symbol     LowCardinality(String),
+    openTime   DateTime64(9, 'UTC'),
+    closeTime  DateTime64(9, 'UTC'),
+    open       Float64,
+    high       Float64,
+    low        Float64,
+    close      Float64,
+    volume     Int64
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(openTime)
+ORDER BY (symbol, openTime);
+`
+
+// ClickHouseSink streams encoded messages into a ClickHouse table
+// over its HTTP interface, batching rows into JSONEachRow inserts.
+// With async insert enabled, batching is additionally delegated to
+// the server (async_insert=1) so many small writers can share one
+// table efficiently.
+type ClickHouseSink struct {
+	endpoint string
+	table    string
+	codec    Codec
+	client   *http.Client
+
+	batchSize int
+	async     bool
+
+	batch bytes.Buffer
+	rows  int
+}
+
+// NewClickHouseSink creates a sink inserting into the given table
+// (e.g. "iex.trades") via the HTTP interface at endpoint (e.g.
+// "http://localhost:8123"). Credentials, if any, go in the endpoint
+// URL.
+func NewClickHouseSink(endpoint, table string, codec Codec) *ClickHouseSink {
+	return &ClickHouseSink{
+		endpoint:  endpoint,
+		table:     table,
+		codec:     codec,
+		client:    http.DefaultClient,
+		batchSize: DefaultClickHouseBatchSize,
+	}
+}
+
+// SetHTTPClient overrides the HTTP client used for inserts.
+func (s *ClickHouseSink) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetBatchSize overrides how many rows are accumulated per insert.
+func (s *ClickHouseSink) SetBatchSize(rows int) {
+	s.batchSize = rows
+}
+
+// SetAsyncInsert enables server-side async inserts: the server
+// acknowledges writes before they are flushed to the table, trading
+// durability for throughput.
+func (s *ClickHouseSink) SetAsyncInsert(async bool) {
+	s.async = async
+}
+
+// Write implements the message sink interface, appending the encoded
+// message to the current batch and inserting when it is full.
+func (s *ClickHouseSink) Write(msg iextp.Message) error {
+	data, err := s.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	s.batch.Write(data)
+	s.batch.WriteByte('\n')
+	s.rows++
+
+	if s.rows >= s.batchSize {
+		return s.Flush()
+	}
+
+	return nil
+}
+
+// Flush inserts the current batch, if any.
+func (s *ClickHouseSink) Flush() error {
+	if s.rows == 0 {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf(
+		"INSERT INTO %v FORMAT JSONEachRow", s.table))
+	if s.async {
+		query.Set("async_insert", "1")
+		query.Set("wait_for_async_insert", "0")
+	}
+
+	resp, err := s.client.Post(s.endpoint+"?"+query.Encode(),
+		"application/x-ndjson", bytes.NewReader(s.batch.Bytes()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// ClickHouse returns the error message in the body.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse insert failed: %v: %s",
+			resp.Status, bytes.TrimSpace(body))
+	}
+
+	s.batch.Reset()
+	s.rows = 0
+	return nil
+}
+
+// Close inserts any remaining rows.
+func (s *ClickHouseSink) Close() error {
+	return s.Flush()
+}
@@ -0,0 +1,96 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type clickHouseInsert struct {
+	query url.Values
+	body  string
+}
+
+func TestClickHouseSink(t *testing.T) {
+	var inserts []clickHouseInsert
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			inserts = append(inserts, clickHouseInsert{
+				query: r.URL.Query(),
+				body:  string(body),
+			})
+		}))
+	defer server.Close()
+
+	sink := NewClickHouseSink(server.URL, "iex.trades", JSONCodec{})
+	sink.SetBatchSize(2)
+	sink.SetAsyncInsert(true)
+
+	for _, symbol := range []string{"SNAP", "FB", "AAPL"} {
+		if err := sink.Write(shardTrade(symbol, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two writes fill the first batch; the third is flushed by Close.
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inserts) != 2 {
+		t.Fatalf("expected 2 inserts, got %v", len(inserts))
+	}
+
+	first := inserts[0]
+	if got := first.query.Get("query"); got != "INSERT INTO iex.trades FORMAT JSONEachRow" {
+		t.Errorf("unexpected insert query: %q", got)
+	}
+	if first.query.Get("async_insert") != "1" ||
+		first.query.Get("wait_for_async_insert") != "0" {
+		t.Errorf("expected async insert settings, got: %v", first.query)
+	}
+
+	lines := strings.Split(strings.TrimSpace(first.body), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 rows in first insert, got %v", len(lines))
+	}
+	var row struct {
+		Symbol string `json:"symbol"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.Symbol != "FB" {
+		t.Errorf("unexpected second row symbol: %v", row.Symbol)
+	}
+
+	if got := strings.Count(inserts[1].body, "\n"); got != 1 {
+		t.Errorf("expected 1 row in final insert, got %v", got)
+	}
+}
+
+func TestClickHouseSink_InsertError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Code: 60. DB::Exception: Table iex.trades does not exist",
+				http.StatusNotFound)
+		}))
+	defer server.Close()
+
+	sink := NewClickHouseSink(server.URL, "iex.trades", JSONCodec{})
+	if err := sink.Write(shardTrade("SNAP", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	err := sink.Close()
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected insert error with server message, got: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package sinks contains building blocks for streaming decoded IEXTP
+// messages into external systems (message queues, webhooks, files).
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// Codec serializes messages for a sink, so the output format is
+// orthogonal to the transport carrying it. Implementations for
+// formats that require external libraries (protobuf, msgpack, Avro)
+// can live in separate modules and plug in via RegisterCodec.
+type Codec interface {
+	// Encode serializes the given message.
+	Encode(msg iextp.Message) ([]byte, error)
+	// ContentType returns the MIME type of the encoded data, for
+	// sinks that transmit it (e.g. webhooks).
+	ContentType() string
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   = map[string]Codec{}
+)
+
+// RegisterCodec makes a codec available to sinks under the given
+// name, replacing any codec previously registered under it.
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[name] = codec
+}
+
+// LookupCodec returns the codec registered under the given name.
+func LookupCodec(name string) (Codec, error) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codec, ok := codecs[name]
+	if !ok {
+		names := make([]string, 0, len(codecs))
+		for name := range codecs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown codec: %v (registered: %v)",
+			name, names)
+	}
+
+	return codec, nil
+}
+
+func init() {
+	RegisterCodec("json", JSONCodec{})
+	RegisterCodec("json-envelope", EnvelopeJSONCodec{})
+}
+
+// JSONCodec encodes each message as its bare JSON serialization.
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(msg iextp.Message) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+// ContentType implements Codec.
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+// EnvelopeJSONCodec encodes each message in the schema-versioned
+// export envelope, so downstream consumers can evolve independently
+// of the library's struct layout.
+type EnvelopeJSONCodec struct{}
+
+// Encode implements Codec.
+func (EnvelopeJSONCodec) Encode(msg iextp.Message) ([]byte, error) {
+	captureTime, _ := iex.MessageTimestamp(msg)
+	exported, err := iex.NewExportedMessage(msg, "", captureTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(exported)
+}
+
+// ContentType implements Codec.
+func (EnvelopeJSONCodec) ContentType() string {
+	return "application/json"
+}
+
+// Interface checks.
+var (
+	_ Codec = JSONCodec{}
+	_ Codec = EnvelopeJSONCodec{}
+)
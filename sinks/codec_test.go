@@ -0,0 +1,90 @@
+package sinks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func TestJSONCodec(t *testing.T) {
+	codec, err := LookupCodec("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &tops.TradeReportMessage{Symbol: "ZIEXT", Price: 99.05, Size: 100}
+	data, err := codec.Encode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded tops.TradeReportMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Symbol != "ZIEXT" || decoded.Price != 99.05 {
+		t.Errorf("unexpected round trip: %+v", decoded)
+	}
+
+	if codec.ContentType() != "application/json" {
+		t.Errorf("unexpected content type: %v", codec.ContentType())
+	}
+}
+
+func TestEnvelopeJSONCodec(t *testing.T) {
+	codec, err := LookupCodec("json-envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &tops.TradeReportMessage{Symbol: "ZIEXT", Price: 99.05, Size: 100}
+	data, err := codec.Encode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exported iex.ExportedMessage
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported.MessageType != "TradeReportMessage" {
+		t.Errorf("unexpected envelope: %+v", exported)
+	}
+
+	decoded, err := exported.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade, ok := decoded.(*tops.TradeReportMessage); !ok || trade.Price != 99.05 {
+		t.Errorf("unexpected decoded message: %+v", decoded)
+	}
+}
+
+func TestLookupCodec_Unknown(t *testing.T) {
+	if _, err := LookupCodec("carrier-pigeon"); err == nil {
+		t.Fatal("expected error for unknown codec")
+	}
+}
+
+// fixedCodec is a trivial codec for testing registration.
+type fixedCodec struct{}
+
+func (fixedCodec) Encode(msg iextp.Message) ([]byte, error) { return []byte("x"), nil }
+func (fixedCodec) ContentType() string                      { return "application/octet-stream" }
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("fixed", fixedCodec{})
+
+	codec, err := LookupCodec("fixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := codec.Encode(nil)
+	if err != nil || string(data) != "x" {
+		t.Errorf("unexpected encode result: %s, %v", data, err)
+	}
+}
@@ -0,0 +1,145 @@
+package sinks
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// Sink is the interface shared by all message sinks in this package:
+// anything that accepts a stream of decoded messages and can be
+// flushed and closed when the stream ends.
+type Sink interface {
+	Write(msg iextp.Message) error
+	Close() error
+}
+
+// DefaultFanoutQueueSize is how many messages each sink's queue
+// buffers when none is configured.
+const DefaultFanoutQueueSize = 1024
+
+// Fanout forwards each message to multiple sinks, each with an
+// optional filter and its own buffered queue drained by a dedicated
+// goroutine — so one slow sink does not stall the others. When a
+// sink's queue is full, messages for that sink are dropped and
+// counted rather than blocking the stream; when a sink returns an
+// error, it stops receiving messages and the error is reported by
+// Close.
+type Fanout struct {
+	queueSize int
+	workers   []*fanoutWorker
+	started   bool
+	wg        sync.WaitGroup
+}
+
+// fanoutWorker is the queue and drain state for one sink.
+type fanoutWorker struct {
+	sink   Sink
+	filter func(msg iextp.Message) bool
+	queue  chan iextp.Message
+	// Written by the drain goroutine, read after wg.Wait in Close.
+	err error
+	// Incremented only by Write, read after wg.Wait in Close.
+	dropped int64
+}
+
+// NewFanout creates a fanout with no sinks; add them with Add and
+// AddFiltered before the first Write.
+func NewFanout() *Fanout {
+	return &Fanout{queueSize: DefaultFanoutQueueSize}
+}
+
+// SetQueueSize overrides how many messages each sink's queue buffers.
+// It must be called before the first Write.
+func (f *Fanout) SetQueueSize(messages int) {
+	f.queueSize = messages
+}
+
+// Add forwards all messages to the given sink.
+func (f *Fanout) Add(sink Sink) {
+	f.AddFiltered(sink, nil)
+}
+
+// AddFiltered forwards messages for which filter returns true to the
+// given sink. A nil filter forwards everything.
+func (f *Fanout) AddFiltered(sink Sink, filter func(msg iextp.Message) bool) {
+	f.workers = append(f.workers, &fanoutWorker{sink: sink, filter: filter})
+}
+
+// Write enqueues the message for each sink whose filter accepts it.
+// It never blocks: if a sink's queue is full the message is dropped
+// for that sink and counted.
+func (f *Fanout) Write(msg iextp.Message) error {
+	if !f.started {
+		f.start()
+	}
+
+	for _, w := range f.workers {
+		if w.filter != nil && !w.filter(msg) {
+			continue
+		}
+
+		select {
+		case w.queue <- msg:
+		default:
+			w.dropped++
+		}
+	}
+
+	return nil
+}
+
+// Close drains the queues, closes every sink, and returns their
+// errors, including a note for any sink that dropped messages.
+func (f *Fanout) Close() error {
+	if f.started {
+		for _, w := range f.workers {
+			close(w.queue)
+		}
+		f.wg.Wait()
+	}
+
+	var errs []error
+	for _, w := range f.workers {
+		errs = append(errs, w.err, w.sink.Close())
+		if w.dropped > 0 {
+			errs = append(errs, errors.New(
+				"fanout: dropped messages on a full sink queue"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Dropped returns the total number of messages dropped across all
+// sinks because their queues were full.
+func (f *Fanout) Dropped() int64 {
+	var total int64
+	for _, w := range f.workers {
+		total += w.dropped
+	}
+
+	return total
+}
+
+// start launches the drain goroutines.
+func (f *Fanout) start() {
+	f.started = true
+	for _, w := range f.workers {
+		w.queue = make(chan iextp.Message, f.queueSize)
+		f.wg.Add(1)
+		go func(w *fanoutWorker) {
+			defer f.wg.Done()
+			for msg := range w.queue {
+				if w.err != nil {
+					// The sink has failed; discard the rest of the
+					// queue so Close does not block on it.
+					continue
+				}
+
+				w.err = w.sink.Write(msg)
+			}
+		}(w)
+	}
+}
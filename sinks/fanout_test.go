@@ -0,0 +1,113 @@
+package sinks
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// recordingSink records written messages, optionally failing after a
+// configured number of writes.
+type recordingSink struct {
+	mu        sync.Mutex
+	messages  []iextp.Message
+	failAfter int
+	closed    bool
+}
+
+func (s *recordingSink) Write(msg iextp.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failAfter > 0 && len(s.messages) >= s.failAfter {
+		return errors.New("sink full")
+	}
+
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingSink) symbols() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var symbols []string
+	for _, msg := range s.messages {
+		if trade, ok := msg.(*tops.TradeReportMessage); ok {
+			symbols = append(symbols, trade.Symbol)
+		}
+	}
+
+	return symbols
+}
+
+func TestFanout(t *testing.T) {
+	all := &recordingSink{}
+	snapOnly := &recordingSink{}
+
+	fanout := NewFanout()
+	fanout.Add(all)
+	fanout.AddFiltered(snapOnly, func(msg iextp.Message) bool {
+		trade, ok := msg.(*tops.TradeReportMessage)
+		return ok && trade.Symbol == "SNAP"
+	})
+
+	for _, symbol := range []string{"SNAP", "FB", "SNAP"} {
+		if err := fanout.Write(shardTrade(symbol, 20.10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fanout.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(all.symbols(), ","); got != "SNAP,FB,SNAP" {
+		t.Errorf("unexpected messages in unfiltered sink: %v", got)
+	}
+	if got := strings.Join(snapOnly.symbols(), ","); got != "SNAP,SNAP" {
+		t.Errorf("unexpected messages in filtered sink: %v", got)
+	}
+	if !all.closed || !snapOnly.closed {
+		t.Error("expected both sinks to be closed")
+	}
+	if fanout.Dropped() != 0 {
+		t.Errorf("unexpected drops: %v", fanout.Dropped())
+	}
+}
+
+func TestFanout_FailureIsolation(t *testing.T) {
+	healthy := &recordingSink{}
+	failing := &recordingSink{failAfter: 1}
+
+	fanout := NewFanout()
+	fanout.Add(healthy)
+	fanout.Add(failing)
+
+	for _, symbol := range []string{"SNAP", "FB", "AAPL"} {
+		if err := fanout.Write(shardTrade(symbol, 20.10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := fanout.Close()
+	if err == nil || !strings.Contains(err.Error(), "sink full") {
+		t.Errorf("expected the failing sink's error from Close, got: %v", err)
+	}
+
+	// The healthy sink still received everything.
+	if got := strings.Join(healthy.symbols(), ","); got != "SNAP,FB,AAPL" {
+		t.Errorf("unexpected messages in healthy sink: %v", got)
+	}
+	if !healthy.closed || !failing.closed {
+		t.Error("expected both sinks to be closed")
+	}
+}
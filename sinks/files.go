@@ -0,0 +1,138 @@
+package sinks
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// fileSet manages a set of append-mode output files keyed by name,
+// with buffered writers and a bounded number of concurrently open
+// handles: the least-recently-written file is flushed and closed when
+// the bound is reached, and reopened in append mode when its key next
+// appears. It is shared by the sharded and partitioned file sinks.
+type fileSet struct {
+	dir     string
+	maxOpen int
+
+	files map[string]*managedFile
+	open  int
+	// Monotonic write counter, for least-recently-used eviction.
+	sequence int64
+}
+
+// managedFile is the output state for one key.
+type managedFile struct {
+	path     string
+	f        *os.File
+	w        *bufio.Writer
+	lastUsed int64
+}
+
+func newFileSet(dir string, maxOpen int) *fileSet {
+	return &fileSet{
+		dir:     dir,
+		maxOpen: maxOpen,
+		files:   make(map[string]*managedFile),
+	}
+}
+
+// writeLine appends data and a trailing newline to the named file.
+func (fs *fileSet) writeLine(name string, data []byte) error {
+	mf, err := fs.file(name)
+	if err != nil {
+		return err
+	}
+
+	fs.sequence++
+	mf.lastUsed = fs.sequence
+	if _, err := mf.w.Write(data); err != nil {
+		return err
+	}
+	return mf.w.WriteByte('\n')
+}
+
+// file returns the named file with an open writer, evicting the
+// least-recently-written file if the open-handle bound is reached.
+func (fs *fileSet) file(name string) (*managedFile, error) {
+	mf, ok := fs.files[name]
+	if !ok {
+		mf = &managedFile{path: filepath.Join(fs.dir, name)}
+		fs.files[name] = mf
+	}
+
+	if mf.f == nil {
+		if fs.open >= fs.maxOpen {
+			if err := fs.evict(); err != nil {
+				return nil, err
+			}
+		}
+
+		f, err := os.OpenFile(mf.path,
+			os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		mf.f = f
+		mf.w = bufio.NewWriter(f)
+		fs.open++
+	}
+
+	return mf, nil
+}
+
+// evict flushes and closes the least-recently-written open file.
+func (fs *fileSet) evict() error {
+	var oldest *managedFile
+	for _, mf := range fs.files {
+		if mf.f == nil {
+			continue
+		}
+		if oldest == nil || mf.lastUsed < oldest.lastUsed {
+			oldest = mf
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+
+	return fs.close(oldest)
+}
+
+func (fs *fileSet) close(mf *managedFile) error {
+	err := mf.w.Flush()
+	if closeErr := mf.f.Close(); err == nil {
+		err = closeErr
+	}
+	mf.f = nil
+	mf.w = nil
+	fs.open--
+	return err
+}
+
+// Close flushes and closes all open files.
+func (fs *fileSet) Close() error {
+	var firstErr error
+	for _, mf := range fs.files {
+		if mf.f == nil {
+			continue
+		}
+		if err := fs.close(mf); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Paths returns the paths of all files written so far, sorted.
+func (fs *fileSet) Paths() []string {
+	paths := make([]string, 0, len(fs.files))
+	for _, mf := range fs.files {
+		paths = append(paths, mf.path)
+	}
+	sort.Strings(paths)
+	return paths
+}
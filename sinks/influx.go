@@ -0,0 +1,219 @@
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+	"github.com/timpalpant/go-iex/summary"
+)
+
+// DefaultInfluxBatchSize is how many points an InfluxSink accumulates
+// before writing, when no batch size is configured.
+const DefaultInfluxBatchSize = 1000
+
+// InfluxSink publishes bars and intraday stats as time-series points
+// in InfluxDB line protocol over HTTP. The endpoint is the full write
+// URL including database/bucket parameters, so the sink works against
+// InfluxDB 1.x (/write?db=iex), 2.x (/api/v2/write?bucket=iex), and
+// line-protocol-compatible stores like VictoriaMetrics — which makes
+// Grafana dashboards over IEX data a matter of pointing at the
+// measurement.
+type InfluxSink struct {
+	endpoint string
+	token    string
+	client   *http.Client
+
+	batchSize int
+	batch     bytes.Buffer
+	points    int
+}
+
+// NewInfluxSink creates a sink posting points to the given write
+// endpoint.
+func NewInfluxSink(endpoint string) *InfluxSink {
+	return &InfluxSink{
+		endpoint:  endpoint,
+		client:    http.DefaultClient,
+		batchSize: DefaultInfluxBatchSize,
+	}
+}
+
+// SetToken sets the API token sent with each write (InfluxDB 2.x).
+func (s *InfluxSink) SetToken(token string) {
+	s.token = token
+}
+
+// SetHTTPClient overrides the HTTP client used for writes.
+func (s *InfluxSink) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetBatchSize overrides how many points are accumulated per write.
+func (s *InfluxSink) SetBatchSize(points int) {
+	s.batchSize = points
+}
+
+// WriteBar publishes a consolidated bar as a point in the "bars"
+// measurement, tagged with its symbol and security type.
+func (s *InfluxSink) WriteBar(bar *consolidator.Bar) error {
+	tags := map[string]string{"symbol": bar.Symbol}
+	if bar.SecurityType != "" {
+		tags["securityType"] = bar.SecurityType
+	}
+
+	return s.WritePoint("bars", tags, map[string]interface{}{
+		"open":   bar.Open,
+		"high":   bar.High,
+		"low":    bar.Low,
+		"close":  bar.Close,
+		"volume": bar.Volume,
+	}, bar.OpenTime)
+}
+
+// WriteSummary publishes a symbol's end-of-day summary as a point in
+// the "eod" measurement, stamped with the given time (typically the
+// session date).
+func (s *InfluxSink) WriteSummary(sum *summary.SymbolSummary, ts time.Time) error {
+	return s.WritePoint("eod", map[string]string{"symbol": sum.Symbol},
+		map[string]interface{}{
+			"officialOpen":  sum.OfficialOpen,
+			"officialClose": sum.OfficialClose,
+			"volume":        sum.Volume,
+			"oddLotVolume":  sum.OddLotVolume,
+			"halts":         int64(sum.Halts),
+			"vwap":          sum.VWAP,
+			"high":          sum.High,
+			"low":           sum.Low,
+		}, ts)
+}
+
+// WritePoint appends one point in line protocol, for callers
+// publishing measurements beyond bars and summaries. Field values may
+// be float64, int, int64, bool, or string. Tags are emitted in sorted
+// order so output is deterministic.
+func (s *InfluxSink) WritePoint(measurement string, tags map[string]string,
+	fields map[string]interface{}, ts time.Time) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("point with no fields: %v", measurement)
+	}
+
+	s.batch.WriteString(escapeLP(measurement))
+	for _, key := range sortedKeys(tags) {
+		s.batch.WriteByte(',')
+		s.batch.WriteString(escapeLP(key))
+		s.batch.WriteByte('=')
+		s.batch.WriteString(escapeLP(tags[key]))
+	}
+
+	sep := byte(' ')
+	for _, key := range sortedFieldKeys(fields) {
+		value, err := fieldValue(fields[key])
+		if err != nil {
+			return fmt.Errorf("%v.%v: %v", measurement, key, err)
+		}
+
+		s.batch.WriteByte(sep)
+		sep = ','
+		s.batch.WriteString(escapeLP(key))
+		s.batch.WriteByte('=')
+		s.batch.WriteString(value)
+	}
+
+	s.batch.WriteByte(' ')
+	s.batch.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	s.batch.WriteByte('\n')
+	s.points++
+
+	if s.points >= s.batchSize {
+		return s.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes the current batch, if any.
+func (s *InfluxSink) Flush() error {
+	if s.points == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint,
+		bytes.NewReader(s.batch.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("influx write failed: %v: %s",
+			resp.Status, bytes.TrimSpace(body))
+	}
+
+	s.batch.Reset()
+	s.points = 0
+	return nil
+}
+
+// Close writes any remaining points.
+func (s *InfluxSink) Close() error {
+	return s.Flush()
+}
+
+// escapeLP escapes the characters line protocol reserves in
+// measurements, tag keys/values, and field keys.
+func escapeLP(value string) string {
+	replacer := strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	return replacer.Replace(value)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fieldValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return strconv.Quote(v), nil
+	}
+
+	return "", fmt.Errorf("unsupported field type %T", value)
+}
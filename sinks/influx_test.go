@@ -0,0 +1,106 @@
+package sinks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/consolidator"
+)
+
+func TestInfluxSink(t *testing.T) {
+	var bodies []string
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			bodies = append(bodies, string(body))
+			tokens = append(tokens, r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	defer server.Close()
+
+	openTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	sink := NewInfluxSink(server.URL + "/api/v2/write?bucket=iex")
+	sink.SetToken("secret")
+	sink.SetBatchSize(2)
+
+	err := sink.WriteBar(&consolidator.Bar{
+		Symbol:       "SNAP",
+		SecurityType: "cs",
+		OpenTime:     openTime,
+		Open:         20.10,
+		High:         20.25,
+		Low:          20.05,
+		Close:        20.20,
+		Volume:       400,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.WriteBar(&consolidator.Bar{
+		Symbol:   "FB",
+		OpenTime: openTime,
+		Open:     140, High: 140, Low: 140, Close: 140, Volume: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bodies) != 1 {
+		t.Fatalf("expected 1 write, got %v", len(bodies))
+	}
+	if tokens[0] != "Token secret" {
+		t.Errorf("unexpected auth header: %q", tokens[0])
+	}
+
+	lines := strings.Split(strings.TrimSpace(bodies[0]), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 points, got %v", len(lines))
+	}
+
+	expected := "bars,securityType=cs,symbol=SNAP " +
+		"close=20.2,high=20.25,low=20.05,open=20.1,volume=400i " +
+		"1492421400000000000"
+	if lines[0] != expected {
+		t.Errorf("unexpected point:\n got: %v\nwant: %v", lines[0], expected)
+	}
+	if !strings.HasPrefix(lines[1], "bars,symbol=FB ") {
+		t.Errorf("unexpected second point: %v", lines[1])
+	}
+}
+
+func TestInfluxSink_Escaping(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	defer server.Close()
+
+	sink := NewInfluxSink(server.URL)
+	err := sink.WritePoint("test", map[string]string{"tag key": "a,b"},
+		map[string]interface{}{"note": "hello"}, time.Unix(0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `test,tag\ key=a\,b note="hello" 1` + "\n"
+	if body != expected {
+		t.Errorf("unexpected body:\n got: %q\nwant: %q", body, expected)
+	}
+}
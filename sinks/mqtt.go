@@ -0,0 +1,275 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultMQTTTopicTemplate is the topic template used when none is
+// configured. {type} expands to the message kind (trades, quotes,
+// status, auctions, events) and {symbol} to the symbol.
+const DefaultMQTTTopicTemplate = "iex/{type}/{symbol}"
+
+// MQTTSink publishes encoded messages to an MQTT broker, speaking
+// MQTT 3.1.1 directly over a TCP connection — light enough that
+// dashboards and edge devices can consume quotes without a heavier
+// broker in between. QoS 0 (fire and forget) and QoS 1 (acknowledged)
+// are supported.
+type MQTTSink struct {
+	dial     func() (net.Conn, error)
+	codec    Codec
+	clientID string
+
+	template string
+	qos      byte
+
+	conn     net.Conn
+	r        *bufio.Reader
+	packetID uint16
+}
+
+// NewMQTTSink creates a sink publishing to the MQTT broker at addr
+// (host:port) with the given client identifier. Messages without a
+// symbol are skipped.
+func NewMQTTSink(addr, clientID string, codec Codec) *MQTTSink {
+	return newMQTTSink(func() (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}, clientID, codec)
+}
+
+func newMQTTSink(dial func() (net.Conn, error), clientID string, codec Codec) *MQTTSink {
+	return &MQTTSink{
+		dial:     dial,
+		codec:    codec,
+		clientID: clientID,
+		template: DefaultMQTTTopicTemplate,
+	}
+}
+
+// SetTopicTemplate overrides the topic template; {type} and {symbol}
+// are expanded per message.
+func (s *MQTTSink) SetTopicTemplate(template string) {
+	s.template = template
+}
+
+// SetQoS sets the publish quality of service: 0 (at most once, the
+// default) or 1 (at least once, each publish acknowledged by the
+// broker).
+func (s *MQTTSink) SetQoS(qos byte) error {
+	if qos > 1 {
+		return fmt.Errorf("unsupported QoS: %v", qos)
+	}
+
+	s.qos = qos
+	return nil
+}
+
+// Write publishes the encoded message to its topic. At QoS 1 the
+// broker's acknowledgement is awaited before returning.
+func (s *MQTTSink) Write(msg iextp.Message) error {
+	symbol, ok := iex.MessageSymbol(msg)
+	if !ok {
+		return nil
+	}
+
+	data, err := s.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	topic := strings.NewReplacer(
+		"{type}", subjectKind(msg),
+		"{symbol}", symbol,
+	).Replace(s.template)
+
+	if err := s.publish(topic, data); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	return nil
+}
+
+// Close sends the MQTT DISCONNECT packet and closes the connection.
+func (s *MQTTSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	_, err := s.conn.Write([]byte{0xe0, 0x00}) // DISCONNECT
+	s.disconnect()
+	return err
+}
+
+// connect dials the broker and performs the CONNECT/CONNACK
+// handshake with a clean session.
+func (s *MQTTSink) connect() error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.r = bufio.NewReader(conn)
+
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4)              // protocol level 3.1.1
+	body.WriteByte(0x02)           // clean session
+	body.Write([]byte{0x00, 0x00}) // keepalive disabled
+	writeMQTTString(&body, s.clientID)
+
+	if err := s.writePacket(0x10, body.Bytes()); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	packetType, packet, err := s.readPacket()
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+	if packetType != 0x20 || len(packet) != 2 {
+		s.disconnect()
+		return fmt.Errorf("unexpected MQTT CONNACK: type 0x%x", packetType)
+	}
+	if code := packet[1]; code != 0 {
+		s.disconnect()
+		return fmt.Errorf("MQTT connection refused: code %v", code)
+	}
+
+	return nil
+}
+
+func (s *MQTTSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// publish sends one PUBLISH packet and, at QoS 1, awaits its PUBACK.
+func (s *MQTTSink) publish(topic string, payload []byte) error {
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	header := byte(0x30)
+	var packetID uint16
+	if s.qos == 1 {
+		header = 0x32
+		s.packetID++
+		if s.packetID == 0 {
+			s.packetID = 1
+		}
+		packetID = s.packetID
+		body.WriteByte(byte(packetID >> 8))
+		body.WriteByte(byte(packetID))
+	}
+	body.Write(payload)
+
+	if err := s.writePacket(header, body.Bytes()); err != nil {
+		return err
+	}
+
+	if s.qos == 1 {
+		packetType, packet, err := s.readPacket()
+		if err != nil {
+			return err
+		}
+		if packetType != 0x40 || len(packet) != 2 {
+			return fmt.Errorf("unexpected MQTT PUBACK: type 0x%x", packetType)
+		}
+		if acked := uint16(packet[0])<<8 | uint16(packet[1]); acked != packetID {
+			return fmt.Errorf("MQTT PUBACK for packet %v, expected %v",
+				acked, packetID)
+		}
+	}
+
+	return nil
+}
+
+// writePacket sends one MQTT packet with the given fixed header byte
+// and variable header/payload body.
+func (s *MQTTSink) writePacket(header byte, body []byte) error {
+	packet := make([]byte, 0, len(body)+5)
+	packet = append(packet, header)
+	packet = appendMQTTLength(packet, len(body))
+	packet = append(packet, body...)
+
+	_, err := s.conn.Write(packet)
+	return err
+}
+
+// readPacket reads one MQTT packet, returning its fixed header byte
+// and body.
+func (s *MQTTSink) readPacket() (byte, []byte, error) {
+	header, err := s.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, err := readMQTTLength(s.r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header & 0xf0, body, nil
+}
+
+// writeMQTTString appends a length-prefixed UTF-8 string.
+func writeMQTTString(buf *bytes.Buffer, value string) {
+	buf.WriteByte(byte(len(value) >> 8))
+	buf.WriteByte(byte(len(value)))
+	buf.WriteString(value)
+}
+
+// appendMQTTLength appends the variable-length remaining length
+// encoding.
+func appendMQTTLength(packet []byte, length int) []byte {
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			return packet
+		}
+	}
+}
+
+// readMQTTLength decodes the variable-length remaining length
+// encoding.
+func readMQTTLength(r *bufio.Reader) (int, error) {
+	length := 0
+	for shift := uint(0); shift <= 21; shift += 7 {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		length |= int(digit&0x7f) << shift
+		if digit&0x80 == 0 {
+			return length, nil
+		}
+	}
+
+	return 0, fmt.Errorf("malformed MQTT remaining length")
+}
@@ -0,0 +1,172 @@
+package sinks
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// mqttPub is one message published to the fake broker.
+type mqttPub struct {
+	topic   string
+	qos     byte
+	payload string
+}
+
+// fakeMQTT accepts connections, answers CONNECT with a successful
+// CONNACK, records published messages, and acknowledges QoS 1
+// publishes with PUBACK.
+type fakeMQTT struct {
+	ln   net.Listener
+	pubs chan mqttPub
+}
+
+func newFakeMQTT(t *testing.T) *fakeMQTT {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeMQTT{ln: ln, pubs: make(chan mqttPub, 64)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeMQTT) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch header & 0xf0 {
+		case 0x10: // CONNECT
+			if _, err := conn.Write([]byte{0x20, 0x02, 0x00, 0x00}); err != nil {
+				return
+			}
+		case 0x30: // PUBLISH
+			topicLen := int(body[0])<<8 | int(body[1])
+			topic := string(body[2 : 2+topicLen])
+			payload := body[2+topicLen:]
+			qos := (header >> 1) & 0x03
+			if qos == 1 {
+				packetID := payload[:2]
+				payload = payload[2:]
+				puback := []byte{0x40, 0x02, packetID[0], packetID[1]}
+				if _, err := conn.Write(puback); err != nil {
+					return
+				}
+			}
+			f.pubs <- mqttPub{topic: topic, qos: qos, payload: string(payload)}
+		case 0xe0: // DISCONNECT
+			return
+		}
+	}
+}
+
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, err := readMQTTLength(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header, body, nil
+}
+
+func TestMQTTSink(t *testing.T) {
+	broker := newFakeMQTT(t)
+
+	sink := NewMQTTSink(broker.ln.Addr().String(), "go-iex-test", JSONCodec{})
+	if err := sink.Write(shardTrade("AAPL", 150.00)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", BidPrice: 20.00, AskPrice: 20.10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Messages without a symbol are skipped.
+	if err := sink.Write(&tops.SystemEventMessage{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	trade := <-broker.pubs
+	if trade.topic != "iex/trades/AAPL" {
+		t.Errorf("unexpected topic: %v", trade.topic)
+	}
+	if trade.qos != 0 {
+		t.Errorf("unexpected QoS: %v", trade.qos)
+	}
+	if !strings.Contains(trade.payload, `"Symbol":"AAPL"`) {
+		t.Errorf("unexpected payload: %v", trade.payload)
+	}
+
+	quote := <-broker.pubs
+	if quote.topic != "iex/quotes/SNAP" {
+		t.Errorf("unexpected topic: %v", quote.topic)
+	}
+
+	select {
+	case pub := <-broker.pubs:
+		t.Errorf("unexpected extra publish: %+v", pub)
+	default:
+	}
+}
+
+func TestMQTTSink_QoS1(t *testing.T) {
+	broker := newFakeMQTT(t)
+
+	sink := NewMQTTSink(broker.ln.Addr().String(), "go-iex-test", JSONCodec{})
+	if err := sink.SetQoS(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.SetQoS(2); err == nil {
+		t.Error("expected error for unsupported QoS")
+	}
+	sink.SetTopicTemplate("md/{symbol}/{type}")
+
+	if err := sink.Write(shardTrade("SNAP", 20.10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pub := <-broker.pubs
+	if pub.topic != "md/SNAP/trades" {
+		t.Errorf("unexpected topic: %v", pub.topic)
+	}
+	if pub.qos != 1 {
+		t.Errorf("unexpected QoS: %v", pub.qos)
+	}
+}
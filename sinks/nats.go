@@ -0,0 +1,223 @@
+package sinks
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// DefaultNATSSubjectPrefix is the subject prefix used when none is
+// configured.
+const DefaultNATSSubjectPrefix = "iex.tops"
+
+// DefaultNATSPipelineSize is how many messages a NATSSink buffers
+// before flushing, when none is configured.
+const DefaultNATSPipelineSize = 100
+
+// NATSSink publishes encoded messages to NATS subjects of the form
+// <prefix>.<kind>.<symbol>, e.g. iex.tops.trades.AAPL, speaking the
+// plain NATS client protocol over a TCP connection. With JetStream,
+// persistence is configured server-side by binding a stream to the
+// subject space (e.g. "iex.tops.>"); the sink itself is
+// publisher-only.
+type NATSSink struct {
+	dial   func() (net.Conn, error)
+	codec  Codec
+	prefix string
+
+	pipeline int
+
+	conn    net.Conn
+	w       *bufio.Writer
+	r       *bufio.Reader
+	pending int
+}
+
+// NewNATSSink creates a sink publishing to the NATS server at addr
+// (host:port). Messages without a symbol are skipped.
+func NewNATSSink(addr string, codec Codec) *NATSSink {
+	return newNATSSink(func() (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}, codec)
+}
+
+func newNATSSink(dial func() (net.Conn, error), codec Codec) *NATSSink {
+	return &NATSSink{
+		dial:     dial,
+		codec:    codec,
+		prefix:   DefaultNATSSubjectPrefix,
+		pipeline: DefaultNATSPipelineSize,
+	}
+}
+
+// SetSubjectPrefix overrides the subject prefix.
+func (s *NATSSink) SetSubjectPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// SetPipelineSize overrides how many messages are buffered per flush.
+func (s *NATSSink) SetPipelineSize(messages int) {
+	s.pipeline = messages
+}
+
+// Write publishes the encoded message to its subject, flushing the
+// pipeline when it is full.
+func (s *NATSSink) Write(msg iextp.Message) error {
+	symbol, ok := iex.MessageSymbol(msg)
+	if !ok {
+		return nil
+	}
+
+	data, err := s.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	subject := s.prefix + "." + subjectKind(msg) + "." + symbol
+	if _, err := fmt.Fprintf(s.w, "PUB %v %v\r\n", subject, len(data)); err != nil {
+		s.disconnect()
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		s.disconnect()
+		return err
+	}
+	if _, err := s.w.WriteString("\r\n"); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	s.pending++
+	if s.pending >= s.pipeline {
+		return s.Flush()
+	}
+
+	return nil
+}
+
+// Flush sends buffered messages and services any protocol traffic
+// from the server (PING, errors). On an I/O error the connection is
+// dropped and redialed on the next Write.
+func (s *NATSSink) Flush() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	if s.pending > 0 {
+		if err := s.w.Flush(); err != nil {
+			s.disconnect()
+			return err
+		}
+		s.pending = 0
+	}
+
+	if err := s.drain(); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	return nil
+}
+
+// Close flushes buffered messages and closes the connection.
+func (s *NATSSink) Close() error {
+	err := s.Flush()
+	s.disconnect()
+	return err
+}
+
+// connect dials the server, consumes its INFO line, and sends the
+// CONNECT handshake. Verbose mode is off, so publishes are not
+// individually acknowledged.
+func (s *NATSSink) connect() error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.w = bufio.NewWriter(conn)
+	s.r = bufio.NewReader(conn)
+	s.pending = 0
+
+	info, err := s.r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		s.disconnect()
+		return fmt.Errorf("unexpected NATS server greeting: %q (%v)", info, err)
+	}
+
+	if _, err := s.w.WriteString(
+		`CONNECT {"verbose":false,"name":"go-iex"}` + "\r\n"); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	return s.w.Flush()
+}
+
+func (s *NATSSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.pending = 0
+}
+
+// drain reads any protocol lines the server has sent, answering PING
+// and surfacing -ERR. It waits only briefly, so a quiet server does
+// not stall the pipeline.
+func (s *NATSSink) drain() error {
+	for {
+		if s.r.Buffered() == 0 {
+			s.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+		}
+		line, err := s.r.ReadString('\n')
+		s.conn.SetReadDeadline(time.Time{})
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return nil
+			}
+
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %v", line)
+		}
+	}
+}
+
+// subjectKind groups message types into subject segments.
+func subjectKind(msg iextp.Message) string {
+	switch msg.(type) {
+	case *tops.TradeReportMessage, *tops.TradeBreakMessage:
+		return "trades"
+	case *tops.QuoteUpdateMessage:
+		return "quotes"
+	case *tops.TradingStatusMessage, *tops.OperationalHaltStatusMessage,
+		*tops.ShortSalePriceTestStatusMessage:
+		return "status"
+	case *tops.AuctionInformationMessage:
+		return "auctions"
+	}
+
+	return "events"
+}
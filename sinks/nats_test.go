@@ -0,0 +1,175 @@
+package sinks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// natsPub is one message published to the fake server.
+type natsPub struct {
+	subject string
+	payload string
+}
+
+// fakeNATS accepts connections, performs the INFO/CONNECT handshake,
+// pings once, and records published messages.
+type fakeNATS struct {
+	ln    net.Listener
+	pubs  chan natsPub
+	pongs chan struct{}
+}
+
+func newFakeNATS(t *testing.T) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeNATS{
+		ln:    ln,
+		pubs:  make(chan natsPub, 64),
+		pongs: make(chan struct{}, 1),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_name\":\"fake\"}\r\n")
+
+	r := bufio.NewReader(conn)
+	connect, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(connect, "CONNECT ") {
+		return
+	}
+	fmt.Fprintf(conn, "PING\r\n")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "PONG":
+			select {
+			case f.pongs <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "PUB "):
+			parts := strings.Split(line, " ")
+			if len(parts) != 3 {
+				return
+			}
+			size, err := strconv.Atoi(parts[2])
+			if err != nil {
+				return
+			}
+
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			f.pubs <- natsPub{subject: parts[1], payload: string(payload[:size])}
+		}
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	server := newFakeNATS(t)
+
+	sink := NewNATSSink(server.ln.Addr().String(), JSONCodec{})
+	if err := sink.Write(shardTrade("AAPL", 150.00)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", BidPrice: 20.00, AskPrice: 20.10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Messages without a symbol are skipped.
+	if err := sink.Write(&tops.SystemEventMessage{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flush until the server's PING has been answered; each flush
+	// services pending protocol traffic.
+	deadline := time.Now().Add(5 * time.Second)
+	for answered := false; !answered; {
+		select {
+		case <-server.pongs:
+			answered = true
+		default:
+			if time.Now().After(deadline) {
+				t.Fatal("server PING was never answered")
+			}
+			if err := sink.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	trade := <-server.pubs
+	if trade.subject != "iex.tops.trades.AAPL" {
+		t.Errorf("unexpected subject: %v", trade.subject)
+	}
+	if !strings.Contains(trade.payload, `"Symbol":"AAPL"`) {
+		t.Errorf("unexpected payload: %v", trade.payload)
+	}
+
+	quote := <-server.pubs
+	if quote.subject != "iex.tops.quotes.SNAP" {
+		t.Errorf("unexpected subject: %v", quote.subject)
+	}
+
+	select {
+	case pub := <-server.pubs:
+		t.Errorf("unexpected extra publish: %+v", pub)
+	default:
+	}
+}
+
+func TestNATSSink_SubjectPrefix(t *testing.T) {
+	server := newFakeNATS(t)
+
+	sink := NewNATSSink(server.ln.Addr().String(), JSONCodec{})
+	sink.SetSubjectPrefix("md.iex")
+	if err := sink.Write(&tops.TradingStatusMessage{
+		Symbol: "SNAP", TradingStatus: tops.TradingHalt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pub := <-server.pubs
+	if pub.subject != "md.iex.status.SNAP" {
+		t.Errorf("unexpected subject: %v", pub.subject)
+	}
+}
@@ -0,0 +1,110 @@
+package sinks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePartition(t *testing.T) {
+	cases := []struct {
+		spec string
+		want time.Duration
+	}{
+		{"hour", time.Hour},
+		{"day", 24 * time.Hour},
+		{"30m", 30 * time.Minute},
+		{"15m", 15 * time.Minute},
+	}
+	for _, c := range cases {
+		got, err := ParsePartition(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("ParsePartition(%q) = %v, expected %v", c.spec, got, c.want)
+		}
+	}
+
+	for _, spec := range []string{"", "bogus", "-1h", "0s"} {
+		if _, err := ParsePartition(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestPartitionedFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewPartitionedFileSink(dir, JSONCodec{}, 30*time.Minute)
+
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	trades := []struct {
+		ts   time.Time
+		want string
+	}{
+		{t0, "ticks-20170417T093000.ndjson"},
+		{t0.Add(29 * time.Minute), "ticks-20170417T093000.ndjson"},
+		{t0.Add(31 * time.Minute), "ticks-20170417T100000.ndjson"},
+	}
+	for _, trade := range trades {
+		msg := shardTrade("SNAP", 22.50)
+		msg.Timestamp = trade.ts
+		if err := sink.Write(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := sink.Paths()
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 partitions, got: %v", paths)
+	}
+	if filepath.Base(paths[0]) != "ticks-20170417T093000.ndjson" ||
+		filepath.Base(paths[1]) != "ticks-20170417T100000.ndjson" {
+		t.Errorf("unexpected partition files: %v", paths)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("expected 2 messages in first partition, got: %v", len(lines))
+	}
+}
+
+func TestShardedFileSink_Partitioned(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewShardedFileSink(dir, JSONCodec{})
+	sink.SetPartition(time.Hour)
+
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	first := shardTrade("SNAP", 22.50)
+	first.Timestamp = t0
+	second := shardTrade("SNAP", 22.60)
+	second.Timestamp = t0.Add(time.Hour)
+	if err := sink.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := sink.Paths()
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 shards, got: %v", paths)
+	}
+	if filepath.Base(paths[0]) != "SNAP-20170417T090000.ndjson" ||
+		filepath.Base(paths[1]) != "SNAP-20170417T100000.ndjson" {
+		t.Errorf("unexpected shard files: %v", paths)
+	}
+}
@@ -0,0 +1,232 @@
+package sinks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultRedisPipelineSize is how many commands a RedisSink pipelines
+// before flushing and reading replies, when none is configured.
+const DefaultRedisPipelineSize = 100
+
+// DefaultRedisKeyPrefix is the channel/stream key prefix used when
+// none is configured.
+const DefaultRedisKeyPrefix = "iex.ticks."
+
+// RedisSink publishes encoded messages per symbol to Redis, either as
+// pub/sub channels (the default) or as Redis Streams entries — a
+// lightweight fan-out for consumers on the same host or rack. It
+// speaks RESP directly over a TCP connection, pipelining commands and
+// redialing automatically after a broken connection.
+type RedisSink struct {
+	dial  func() (net.Conn, error)
+	codec Codec
+
+	keyPrefix string
+	streams   bool
+	pipeline  int
+
+	conn    net.Conn
+	w       *bufio.Writer
+	r       *bufio.Reader
+	pending int
+}
+
+// NewRedisSink creates a sink publishing to the Redis server at addr
+// (host:port). Messages without a symbol are skipped.
+func NewRedisSink(addr string, codec Codec) *RedisSink {
+	return newRedisSink(func() (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}, codec)
+}
+
+func newRedisSink(dial func() (net.Conn, error), codec Codec) *RedisSink {
+	return &RedisSink{
+		dial:      dial,
+		codec:     codec,
+		keyPrefix: DefaultRedisKeyPrefix,
+		pipeline:  DefaultRedisPipelineSize,
+	}
+}
+
+// SetKeyPrefix overrides the channel/stream key prefix; the symbol is
+// appended to it.
+func (s *RedisSink) SetKeyPrefix(prefix string) {
+	s.keyPrefix = prefix
+}
+
+// SetStreams switches the sink to XADD entries on per-symbol Redis
+// Streams instead of pub/sub, so late-joining consumers can catch up.
+func (s *RedisSink) SetStreams(streams bool) {
+	s.streams = streams
+}
+
+// SetPipelineSize overrides how many commands are pipelined per
+// flush.
+func (s *RedisSink) SetPipelineSize(commands int) {
+	s.pipeline = commands
+}
+
+// Write publishes the encoded message to the symbol's channel or
+// stream, flushing the pipeline when it is full.
+func (s *RedisSink) Write(msg iextp.Message) error {
+	symbol, ok := iex.MessageSymbol(msg)
+	if !ok {
+		return nil
+	}
+
+	data, err := s.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	key := s.keyPrefix + symbol
+	if s.streams {
+		err = s.command("XADD", key, "*", "data", string(data))
+	} else {
+		err = s.command("PUBLISH", key, string(data))
+	}
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+
+	s.pending++
+	if s.pending >= s.pipeline {
+		return s.Flush()
+	}
+
+	return nil
+}
+
+// Flush sends the pipelined commands and checks their replies. On an
+// I/O error the connection is dropped and redialed on the next Write.
+func (s *RedisSink) Flush() error {
+	if s.pending == 0 {
+		return nil
+	}
+
+	if err := s.w.Flush(); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	for ; s.pending > 0; s.pending-- {
+		if err := s.readReply(); err != nil {
+			s.disconnect()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes the pipeline and closes the connection.
+func (s *RedisSink) Close() error {
+	err := s.Flush()
+	s.disconnect()
+	return err
+}
+
+func (s *RedisSink) connect() error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.w = bufio.NewWriter(conn)
+	s.r = bufio.NewReader(conn)
+	s.pending = 0
+	return nil
+}
+
+func (s *RedisSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.pending = 0
+}
+
+// command appends one RESP command to the pipeline.
+func (s *RedisSink) command(args ...string) error {
+	if _, err := fmt.Fprintf(s.w, "*%v\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(s.w, "$%v\r\n%v\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readReply consumes one RESP reply, returning Redis errors as
+// errors.
+func (s *RedisSink) readReply() error {
+	line, err := s.readLine()
+	if err != nil {
+		return err
+	}
+	if len(line) == 0 {
+		return fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return nil
+	case '-':
+		return fmt.Errorf("redis: %v", line[1:])
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return nil
+		}
+
+		// Bulk string payload plus trailing CRLF.
+		_, err = io.CopyN(io.Discard, s.r, int64(length)+2)
+		return err
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			if err := s.readReply(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unexpected redis reply: %q", line)
+}
+
+func (s *RedisSink) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed redis reply: %q", line)
+	}
+
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,147 @@
+package sinks
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// fakeRedis accepts connections and parses RESP commands, replying
+// ":1" to each and sending them to the commands channel as joined
+// argument strings.
+type fakeRedis struct {
+	ln       net.Listener
+	commands chan string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeRedis{ln: ln, commands: make(chan string, 64)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		f.commands <- strings.Join(args, " ")
+		if _, err := fmt.Fprintf(conn, ":1\r\n"); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := respLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if _, err := respLine(r); err != nil { // $<len>
+			return nil, err
+		}
+		if args[i], err = respLine(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+func respLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func TestRedisSink_Publish(t *testing.T) {
+	server := newFakeRedis(t)
+
+	sink := NewRedisSink(server.ln.Addr().String(), JSONCodec{})
+	sink.SetPipelineSize(2)
+
+	for _, symbol := range []string{"SNAP", "FB", "AAPL"} {
+		if err := sink.Write(shardTrade(symbol, 20.10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Messages without a symbol are skipped.
+	if err := sink.Write(&tops.SystemEventMessage{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, symbol := range []string{"SNAP", "FB", "AAPL"} {
+		command := <-server.commands
+		if !strings.HasPrefix(command, "PUBLISH iex.ticks."+symbol+" ") {
+			t.Errorf("unexpected command for %v: %v", symbol, command)
+		}
+		if !strings.Contains(command, `"Symbol":"`+symbol+`"`) {
+			t.Errorf("expected encoded payload in command: %v", command)
+		}
+	}
+
+	select {
+	case command := <-server.commands:
+		t.Errorf("unexpected extra command: %v", command)
+	default:
+	}
+}
+
+func TestRedisSink_Streams(t *testing.T) {
+	server := newFakeRedis(t)
+
+	sink := NewRedisSink(server.ln.Addr().String(), JSONCodec{})
+	sink.SetStreams(true)
+	sink.SetKeyPrefix("ticks:")
+
+	if err := sink.Write(shardTrade("SNAP", 20.10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	command := <-server.commands
+	if !strings.HasPrefix(command, "XADD ticks:SNAP * data ") {
+		t.Errorf("unexpected command: %v", command)
+	}
+}
@@ -0,0 +1,81 @@
+package sinks
+
+import (
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultMaxOpenShards is the number of shard files a ShardedFileSink
+// keeps open at once when no limit is configured.
+const DefaultMaxOpenShards = 64
+
+// ShardedFileSink writes one newline-delimited output file per
+// symbol, for research workflows that want per-symbol tick files
+// rather than one interleaved firehose file. Writers are buffered,
+// and at most a bounded number of file handles are open at once:
+// least-recently-written shards are flushed and closed, and reopened
+// in append mode when their symbol next appears.
+type ShardedFileSink struct {
+	codec     Codec
+	files     *fileSet
+	partition time.Duration
+}
+
+// NewShardedFileSink creates a sink writing <SYMBOL>.ndjson files to
+// the given directory, encoding messages with the given codec.
+func NewShardedFileSink(dir string, codec Codec) *ShardedFileSink {
+	return &ShardedFileSink{
+		codec: codec,
+		files: newFileSet(dir, DefaultMaxOpenShards),
+	}
+}
+
+// SetMaxOpenShards bounds the number of concurrently open file
+// handles. It must be called before the first Write.
+func (s *ShardedFileSink) SetMaxOpenShards(n int) {
+	s.files.maxOpen = n
+}
+
+// SetPartition additionally partitions each symbol's output by time:
+// shards are named <SYMBOL>-<partition start>.ndjson, with partition
+// boundaries aligned to multiples of the given interval. Messages of
+// all types are assigned to partitions by their event timestamp, so
+// trades and quotes land in consistent files.
+func (s *ShardedFileSink) SetPartition(interval time.Duration) {
+	s.partition = interval
+}
+
+// Write encodes the given message and appends it to its symbol's
+// file. Messages without a symbol are skipped.
+func (s *ShardedFileSink) Write(msg iextp.Message) error {
+	symbol, ok := iex.MessageSymbol(msg)
+	if !ok {
+		return nil
+	}
+
+	data, err := s.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	name := symbol
+	if s.partition > 0 {
+		if ts, ok := iex.MessageTimestamp(msg); ok {
+			name += "-" + partitionStamp(ts, s.partition)
+		}
+	}
+
+	return s.files.writeLine(name+".ndjson", data)
+}
+
+// Close flushes and closes all open shard files.
+func (s *ShardedFileSink) Close() error {
+	return s.files.Close()
+}
+
+// Paths returns the paths of all shard files written so far, sorted.
+func (s *ShardedFileSink) Paths() []string {
+	return s.files.Paths()
+}
@@ -0,0 +1,89 @@
+package sinks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func shardTrade(symbol string, price float64) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		Symbol:    symbol,
+		Timestamp: time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC),
+		Price:     price,
+		Size:      100,
+	}
+}
+
+func TestShardedFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewShardedFileSink(dir, JSONCodec{})
+	// A bound smaller than the number of symbols forces eviction and
+	// append-mode reopening.
+	sink.SetMaxOpenShards(2)
+
+	messages := []*tops.TradeReportMessage{
+		shardTrade("SNAP", 22.50),
+		shardTrade("FB", 140.00),
+		shardTrade("AAPL", 141.00),
+		shardTrade("SNAP", 22.60),
+		shardTrade("FB", 140.10),
+		shardTrade("SNAP", 22.70),
+	}
+	for _, msg := range messages {
+		if err := sink.Write(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages without a symbol are skipped.
+	if err := sink.Write(&tops.SystemEventMessage{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := sink.Paths()
+	want := []string{
+		filepath.Join(dir, "AAPL.ndjson"),
+		filepath.Join(dir, "FB.ndjson"),
+		filepath.Join(dir, "SNAP.ndjson"),
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+	for i, path := range paths {
+		if path != want[i] {
+			t.Errorf("unexpected path: %v != %v", path, want[i])
+		}
+	}
+
+	counts := map[string]int{"AAPL": 1, "FB": 2, "SNAP": 3}
+	for symbol, count := range counts {
+		data, err := os.ReadFile(filepath.Join(dir, symbol+".ndjson"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != count {
+			t.Errorf("expected %v lines for %v, got: %v", count, symbol, len(lines))
+		}
+		for _, line := range lines {
+			var msg tops.TradeReportMessage
+			if err := json.Unmarshal([]byte(line), &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Symbol != symbol {
+				t.Errorf("message for %v in %v's shard", msg.Symbol, symbol)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package iex
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSkewWindow is the number of observations per estimation
+// window used by NewSkewEstimator.
+const DefaultSkewWindow = 1024
+
+// SkewEstimator estimates the local clock's offset relative to IEX
+// timestamps, for machines without PTP where raw latency measurements
+// mix network delay with clock skew. It tracks the minimum observed
+// latency over rolling windows: during quiet periods the one-way
+// delay reaches its floor, so the windowed minimum approximates the
+// clock offset plus a small constant delay. Subtracting it from
+// receipt times removes the skew component from latency analytics.
+// It is safe for concurrent use.
+type SkewEstimator struct {
+	mu         sync.Mutex
+	windowSize int
+	// Minimum latency in the current and previous windows. The
+	// estimate spans both so a fresh window does not forget the floor
+	// immediately.
+	current      time.Duration
+	previous     time.Duration
+	count        int
+	havePrevious bool
+}
+
+// NewSkewEstimator creates an estimator with DefaultSkewWindow
+// observations per window.
+func NewSkewEstimator() *SkewEstimator {
+	return NewSkewEstimatorWindow(DefaultSkewWindow)
+}
+
+// NewSkewEstimatorWindow creates an estimator with the given number
+// of observations per window.
+func NewSkewEstimatorWindow(windowSize int) *SkewEstimator {
+	return &SkewEstimator{windowSize: windowSize}
+}
+
+// Observe records one raw latency observation: local receipt time
+// minus IEX send time, uncorrected.
+func (s *SkewEstimator) Observe(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 || latency < s.current {
+		s.current = latency
+	}
+	s.count++
+
+	if s.count >= s.windowSize {
+		s.previous = s.current
+		s.havePrevious = true
+		s.count = 0
+	}
+}
+
+// ObserveEnvelope records the raw latency of the given envelope, if
+// it carries a receipt time.
+func (s *SkewEstimator) ObserveEnvelope(e *MessageEnvelope) {
+	if latency, ok := e.Latency(); ok {
+		s.Observe(latency)
+	}
+}
+
+// Offset returns the estimated clock offset: the amount by which the
+// local clock runs ahead of IEX's. ok is false until at least one
+// observation has been recorded.
+func (s *SkewEstimator) Offset() (offset time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 && !s.havePrevious {
+		return 0, false
+	}
+
+	offset = s.current
+	if s.havePrevious && s.previous < offset {
+		offset = s.previous
+	}
+	if s.count == 0 {
+		offset = s.previous
+	}
+
+	return offset, true
+}
+
+// Correct adjusts the envelope's receipt time by the estimated clock
+// offset, in place. Envelopes without a receipt time, or corrections
+// attempted before any estimate exists, are left untouched.
+func (s *SkewEstimator) Correct(e *MessageEnvelope) {
+	offset, ok := s.Offset()
+	if !ok || e.ReceiptTime.IsZero() {
+		return
+	}
+
+	e.ReceiptTime = e.ReceiptTime.Add(-offset)
+}
+
+// CaptureSkew compares pcap capture timestamps against IEX-TP send
+// times across a dump. HIST files carry two clocks — the capturing
+// host's and IEX's — and they disagree by the network delay plus any
+// clock offset; the distribution of deltas (and in particular any
+// negative ones, which are impossible without capture-clock error)
+// tells a researcher which clock to trust for event-time bars.
+type CaptureSkew struct {
+	deltas []time.Duration
+}
+
+// CaptureSkewReport summarizes the distribution of capture-minus-send
+// deltas observed across a dump.
+type CaptureSkewReport struct {
+	// The number of segments observed.
+	Count int
+	// How many deltas were negative: the capture timestamp preceded
+	// the send time, indicating capture-clock error.
+	Negative int
+	// Distribution of capture time minus send time.
+	Min  time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+}
+
+// NewCaptureSkew creates an empty skew analysis.
+func NewCaptureSkew() *CaptureSkew {
+	return &CaptureSkew{}
+}
+
+// Observe records one segment: the local capture timestamp from the
+// pcap record header and the SendTime from the IEX-TP segment header.
+func (s *CaptureSkew) Observe(captureTime, sendTime time.Time) {
+	s.deltas = append(s.deltas, captureTime.Sub(sendTime))
+}
+
+// Report summarizes the deltas observed so far.
+func (s *CaptureSkew) Report() CaptureSkewReport {
+	report := CaptureSkewReport{Count: len(s.deltas)}
+	if report.Count == 0 {
+		return report
+	}
+
+	sorted := make([]time.Duration, len(s.deltas))
+	copy(sorted, s.deltas)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, delta := range sorted {
+		sum += delta
+		if delta < 0 {
+			report.Negative++
+		}
+	}
+
+	report.Min = sorted[0]
+	report.Max = sorted[len(sorted)-1]
+	report.Mean = sum / time.Duration(len(sorted))
+	report.P50 = percentileDuration(sorted, 50)
+	report.P90 = percentileDuration(sorted, 90)
+	report.P99 = percentileDuration(sorted, 99)
+	return report
+}
+
+// percentileDuration returns the p'th percentile of sorted values by
+// nearest rank.
+func percentileDuration(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return sorted[i]
+}
@@ -0,0 +1,97 @@
+package iex
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSkewEstimator(t *testing.T) {
+	s := NewSkewEstimatorWindow(4)
+
+	if _, ok := s.Offset(); ok {
+		t.Error("expected no estimate before observations")
+	}
+
+	// The local clock runs 10ms ahead; network delay varies from
+	// 1-5ms, reaching its floor during a quiet period.
+	for _, delay := range []time.Duration{
+		5 * time.Millisecond,
+		3 * time.Millisecond,
+		time.Millisecond,
+		4 * time.Millisecond,
+	} {
+		s.Observe(10*time.Millisecond + delay)
+	}
+
+	offset, ok := s.Offset()
+	if !ok || offset != 11*time.Millisecond {
+		t.Errorf("unexpected offset: %v (ok=%v)", offset, ok)
+	}
+
+	// The estimate spans the previous window too, so the start of a
+	// new window does not forget the floor.
+	s.Observe(20 * time.Millisecond)
+	offset, ok = s.Offset()
+	if !ok || offset != 11*time.Millisecond {
+		t.Errorf("unexpected offset: %v (ok=%v)", offset, ok)
+	}
+}
+
+func TestSkewEstimator_Correct(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	s := NewSkewEstimatorWindow(1)
+	s.Observe(10 * time.Millisecond)
+
+	e := &MessageEnvelope{
+		Timestamp:   sendTime.Add(-time.Millisecond),
+		SendTime:    sendTime,
+		ReceiptTime: sendTime.Add(13 * time.Millisecond),
+	}
+	s.Correct(e)
+
+	latency, ok := e.Latency()
+	if !ok || latency != 3*time.Millisecond {
+		t.Errorf("unexpected corrected latency: %v (ok=%v)", latency, ok)
+	}
+
+	// Envelopes without a receipt time are untouched.
+	bare := &MessageEnvelope{Timestamp: sendTime}
+	s.Correct(bare)
+	if !bare.ReceiptTime.IsZero() {
+		t.Errorf("unexpected receipt time: %v", bare.ReceiptTime)
+	}
+}
+
+func TestCaptureSkew(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	s := NewCaptureSkew()
+	if report := s.Report(); report.Count != 0 {
+		t.Fatalf("unexpected empty report: %+v", report)
+	}
+
+	// 1ms..10ms, with one negative observation.
+	for i := 1; i <= 10; i++ {
+		delta := time.Duration(i) * time.Millisecond
+		s.Observe(sendTime.Add(delta), sendTime)
+	}
+	s.Observe(sendTime.Add(-time.Millisecond), sendTime)
+
+	report := s.Report()
+	if report.Count != 11 || report.Negative != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if report.Min != -time.Millisecond || report.Max != 10*time.Millisecond {
+		t.Errorf("unexpected extremes: %+v", report)
+	}
+	if report.Mean != 54*time.Millisecond/11 {
+		t.Errorf("unexpected mean: %v", report.Mean)
+	}
+	if report.P50 != 5*time.Millisecond {
+		t.Errorf("unexpected p50: %v", report.P50)
+	}
+	if report.P99 != 10*time.Millisecond {
+		t.Errorf("unexpected p99: %v", report.P99)
+	}
+}
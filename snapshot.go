@@ -0,0 +1,146 @@
+package iex
+
+import "sync"
+
+// Snapshot is a composite intraday view of a single symbol, combining
+// the quote, top of book, last trade, key stats, and trading status
+// endpoints in one call.
+type Snapshot struct {
+	Symbol        string
+	Quote         *StockQuote
+	Book          *Book
+	LastTrade     *Last
+	KeyStats      *KeyStats
+	TradingStatus *TradingStatusMessage
+}
+
+// GetSnapshot fetches the quote, top of book, last trade, key stats,
+// and trading status for the given symbol concurrently and returns
+// them as a single composite struct. If any underlying request fails,
+// the first error is returned.
+func (c *Client) GetSnapshot(symbol string) (*Snapshot, error) {
+	snapshot := &Snapshot{Symbol: symbol}
+	symbols := []string{symbol}
+
+	fetches := []func() error{
+		func() error {
+			quotes, err := c.GetStockQuotes(symbols)
+			if err == nil {
+				snapshot.Quote = quotes[symbol]
+			}
+			return err
+		},
+		func() error {
+			books, err := c.GetBook(symbols)
+			if err == nil {
+				snapshot.Book = books[symbol]
+			}
+			return err
+		},
+		func() error {
+			last, err := c.GetLast(symbols)
+			if err == nil && len(last) > 0 {
+				snapshot.LastTrade = last[0]
+			}
+			return err
+		},
+		func() error {
+			stats, err := c.GetKeyStats(symbol)
+			if err == nil {
+				snapshot.KeyStats = stats
+			}
+			return err
+		},
+		func() error {
+			statuses, err := c.GetTradingStatus(symbols)
+			if err == nil {
+				snapshot.TradingStatus = statuses[symbol]
+			}
+			return err
+		},
+	}
+
+	errs := make(chan error, len(fetches))
+	var wg sync.WaitGroup
+	for _, fetch := range fetches {
+		wg.Add(1)
+		go func(fetch func() error) {
+			defer wg.Done()
+			errs <- fetch()
+		}(fetch)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// DefaultSnapshotConcurrency is the number of symbols fetched at once
+// by GetSnapshots when SnapshotOptions.Concurrency is zero.
+const DefaultSnapshotConcurrency = 4
+
+// SnapshotOptions configures GetSnapshots.
+type SnapshotOptions struct {
+	// Concurrency is the maximum number of symbols fetched at once.
+	// Zero means DefaultSnapshotConcurrency.
+	Concurrency int
+}
+
+// GetSnapshots fetches snapshots for all of the given symbols using a
+// bounded pool of workers. Each symbol is fetched individually, which
+// keeps every underlying request within the per-endpoint symbol
+// limits. Snapshots are returned per symbol; symbols whose fetch
+// failed appear in the error map instead.
+func (c *Client) GetSnapshots(symbols []string, opts SnapshotOptions) (map[string]*Snapshot, map[string]error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSnapshotConcurrency
+	}
+
+	type result struct {
+		symbol   string
+		snapshot *Snapshot
+		err      error
+	}
+
+	work := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range work {
+				snapshot, err := c.GetSnapshot(symbol)
+				results <- result{symbol, snapshot, err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, symbol := range symbols {
+			work <- symbol
+		}
+		close(work)
+		wg.Wait()
+		close(results)
+	}()
+
+	snapshots := make(map[string]*Snapshot, len(symbols))
+	errs := make(map[string]error)
+	for r := range results {
+		if r.err != nil {
+			errs[r.symbol] = r.err
+		} else {
+			snapshots[r.symbol] = r.snapshot
+		}
+	}
+
+	return snapshots, errs
+}
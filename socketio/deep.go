@@ -0,0 +1,338 @@
+package socketio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+)
+
+// Socket.io packet types, carried inside engine.io message packets.
+const (
+	socketPacketConnect    = '0'
+	socketPacketDisconnect = '1'
+	socketPacketEvent      = '2'
+)
+
+// DEEPNamespace is the socket.io namespace for DEEP streaming data.
+const DEEPNamespace = "/1.0/deep"
+
+// DEEPConn manages subscriptions to the DEEP socket.io namespace.
+//
+// Unlike the TOPS namespace, the DEEP namespace accepts only a single
+// symbol per subscribe message, so multi-symbol subscriptions are
+// multiplexed under the hood by issuing one subscribe message per
+// symbol.
+type DEEPConn struct {
+	transport Transport
+
+	mu sync.Mutex
+	// Reference counts per subscribed symbol: a symbol may be
+	// requested by several context-scoped subscriptions, and the
+	// unsubscribe message is only sent when the last reference is
+	// released.
+	symbols map[string]int
+	// Number of live SubscribeTo contexts. The namespace is
+	// disconnected when the last one is cancelled.
+	activeContexts int
+	// While paused, the subscription set is retained but no symbols
+	// are subscribed on the wire.
+	paused    bool
+	closed    bool
+	callbacks []func(event []byte)
+}
+
+// NewDEEPConn joins the DEEP namespace on the given transport.
+func NewDEEPConn(transport Transport) (*DEEPConn, error) {
+	c := &DEEPConn{
+		transport: transport,
+		symbols:   make(map[string]int),
+	}
+
+	transport.AddPacketCallback(c.onPacket)
+	connect := fmt.Sprintf("%c%s", socketPacketConnect, DEEPNamespace)
+	if err := transport.Write([]byte(connect)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// AddEventCallback registers a callback to be invoked with the JSON
+// payload of each event received on the DEEP namespace.
+func (c *DEEPConn) AddEventCallback(cb func(event []byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callbacks = append(c.callbacks, cb)
+}
+
+// Subscribe subscribes to DEEP updates for the given symbols, issuing
+// one subscribe message per symbol as the namespace requires. Symbols
+// already subscribed gain a reference rather than a duplicate
+// subscribe message.
+func (c *DEEPConn) Subscribe(symbols ...string) error {
+	for _, symbol := range symbols {
+		c.mu.Lock()
+		count := c.symbols[symbol]
+		paused := c.paused
+		c.mu.Unlock()
+
+		if count == 0 && !paused {
+			if err := c.sendEvent("subscribe", symbol); err != nil {
+				return err
+			}
+		}
+
+		c.mu.Lock()
+		c.symbols[symbol]++
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Unsubscribe releases one reference to each of the given symbols,
+// sending the unsubscribe message for symbols whose last reference is
+// released.
+func (c *DEEPConn) Unsubscribe(symbols ...string) error {
+	for _, symbol := range symbols {
+		c.mu.Lock()
+		count := c.symbols[symbol]
+		if count > 1 {
+			c.symbols[symbol] = count - 1
+			c.mu.Unlock()
+			continue
+		}
+		delete(c.symbols, symbol)
+		paused := c.paused
+		c.mu.Unlock()
+
+		if count == 0 || paused {
+			continue
+		}
+
+		if err := c.sendEvent("unsubscribe", symbol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SubscribeTo subscribes to the given symbols for the lifetime of
+// ctx. When ctx is cancelled the symbols are unsubscribed and no
+// further callbacks are delivered for them; when the last active
+// context is cancelled, the namespace is disconnected.
+func (c *DEEPConn) SubscribeTo(ctx context.Context, symbols ...string) error {
+	if err := c.Subscribe(symbols...); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.activeContexts++
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.Unsubscribe(symbols...)
+
+		c.mu.Lock()
+		c.activeContexts--
+		last := c.activeContexts == 0
+		c.mu.Unlock()
+
+		if last {
+			c.Close()
+		}
+	}()
+
+	return nil
+}
+
+// SubscribeToMany reconciles the subscription set to exactly the
+// given symbols, subscribing to new ones and unsubscribing from ones
+// no longer wanted. Symbols already subscribed are left untouched.
+func (c *DEEPConn) SubscribeToMany(symbols []string) error {
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	var toAdd, toRemove []string
+	c.mu.Lock()
+	for symbol := range wanted {
+		if c.symbols[symbol] == 0 {
+			toAdd = append(toAdd, symbol)
+		}
+	}
+	for symbol := range c.symbols {
+		if !wanted[symbol] {
+			toRemove = append(toRemove, symbol)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Strings(toAdd)
+	sort.Strings(toRemove)
+
+	if err := c.Subscribe(toAdd...); err != nil {
+		return err
+	}
+
+	return c.Unsubscribe(toRemove...)
+}
+
+// Subscribed returns the currently subscribed symbols, sorted.
+func (c *DEEPConn) Subscribed() []string {
+	c.mu.Lock()
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Pause unsubscribes all symbols on the wire without forgetting the
+// subscription set, so Resume can restore it. Subscription changes
+// made while paused are tracked but not sent until Resume.
+func (c *DEEPConn) Pause() error {
+	c.mu.Lock()
+	if c.paused {
+		c.mu.Unlock()
+		return nil
+	}
+	c.paused = true
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		if err := c.sendEvent("unsubscribe", symbol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resume restores the subscription set paused by Pause.
+func (c *DEEPConn) Resume() error {
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return nil
+	}
+	c.paused = false
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		if err := c.sendEvent("subscribe", symbol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marketHoursPollInterval is how often PauseOutsideMarketHours checks
+// whether the market state has changed.
+const marketHoursPollInterval = time.Minute
+
+// PauseOutsideMarketHours pauses and resumes the subscription set as
+// the market closes and opens, polling isOpen (e.g. iex.IsMarketOpen,
+// or the IsOpen method of an iex.MarketHours) once per minute on the
+// given clock. The returned stop function ends the gating, leaving
+// the connection in whichever state it was last put.
+func (c *DEEPConn) PauseOutsideMarketHours(clock iex.Clock, isOpen func(now time.Time) bool) (stop func()) {
+	apply := func() {
+		if isOpen(clock.Now()) {
+			c.Resume()
+		} else {
+			c.Pause()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := clock.NewTicker(marketHoursPollInterval)
+		defer ticker.Stop()
+
+		apply()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.Chan():
+				apply()
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Close leaves the namespace and stops delivering events to the
+// registered callbacks. It does not close the underlying transport,
+// which may carry other namespaces.
+func (c *DEEPConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	disconnect := fmt.Sprintf("%c%s", socketPacketDisconnect, DEEPNamespace)
+	return c.transport.Write([]byte(disconnect))
+}
+
+// sendEvent emits a socket.io event with a single string argument on
+// the DEEP namespace.
+func (c *DEEPConn) sendEvent(event, arg string) error {
+	args, err := json.Marshal([]string{event, arg})
+	if err != nil {
+		return err
+	}
+
+	packet := fmt.Sprintf("%c%s,%s", socketPacketEvent, DEEPNamespace, args)
+	return c.transport.Write([]byte(packet))
+}
+
+// onPacket dispatches events received on the DEEP namespace to the
+// registered callbacks.
+func (c *DEEPConn) onPacket(packet []byte) {
+	prefix := []byte(fmt.Sprintf("%c%s,", socketPacketEvent, DEEPNamespace))
+	if !bytes.HasPrefix(packet, prefix) {
+		return
+	}
+
+	event := packet[len(prefix):]
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	callbacks := c.callbacks
+	c.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
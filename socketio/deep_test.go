@@ -0,0 +1,242 @@
+package socketio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/socketio/sockettest"
+)
+
+func TestDEEPConn_Subscribe(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Subscribe("SNAP", "FB"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"0/1.0/deep",
+		`2/1.0/deep,["subscribe","SNAP"]`,
+		`2/1.0/deep,["subscribe","FB"]`,
+	}
+
+	packets := transport.Written()
+	if len(packets) != len(expected) {
+		t.Fatalf("unexpected packets: %v", packets)
+	}
+	for i := range expected {
+		if packets[i] != expected[i] {
+			t.Errorf("packet %v: expected %v, got: %v",
+				i, expected[i], packets[i])
+		}
+	}
+
+	subscribed := conn.Subscribed()
+	if len(subscribed) != 2 || subscribed[0] != "FB" || subscribed[1] != "SNAP" {
+		t.Errorf("unexpected subscriptions: %v", subscribed)
+	}
+}
+
+func TestDEEPConn_SubscribeToMany(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Subscribe("AAPL", "SNAP"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.SubscribeToMany([]string{"SNAP", "FB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	subscribed := conn.Subscribed()
+	if len(subscribed) != 2 || subscribed[0] != "FB" || subscribed[1] != "SNAP" {
+		t.Errorf("unexpected subscriptions: %v", subscribed)
+	}
+
+	packets := transport.Written()
+	last := packets[len(packets)-2:]
+	if last[0] != `2/1.0/deep,["subscribe","FB"]` ||
+		last[1] != `2/1.0/deep,["unsubscribe","AAPL"]` {
+		t.Errorf("unexpected reconciliation packets: %v", last)
+	}
+}
+
+func TestDEEPConn_Events(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	conn.AddEventCallback(func(event []byte) {
+		events = append(events, string(event))
+	})
+
+	transport.InjectPacket([]byte(`2/1.0/deep,["message","{\"symbol\":\"SNAP\"}"]`))
+	// Events for other namespaces are ignored.
+	transport.InjectPacket([]byte(`2/1.0/tops,["message","{}"]`))
+
+	if len(events) != 1 || events[0] != `["message","{\"symbol\":\"SNAP\"}"]` {
+		t.Errorf("unexpected events: %v", events)
+	}
+}
+
+// waitFor polls until condition returns true, failing the test after
+// a timeout.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
+
+func TestDEEPConn_SubscribeTo(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+
+	if err := conn.SubscribeTo(ctx1, "SNAP", "FB"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SubscribeTo(ctx2, "SNAP"); err != nil {
+		t.Fatal(err)
+	}
+
+	// SNAP is referenced by both contexts, so only one subscribe
+	// message is sent for it.
+	packets := transport.Written()
+	if len(packets) != 3 {
+		t.Fatalf("unexpected packets: %v", packets)
+	}
+
+	// Cancelling the first context releases FB, but SNAP is still
+	// referenced by the second context.
+	cancel1()
+	waitFor(t, func() bool {
+		subscribed := conn.Subscribed()
+		return len(subscribed) == 1 && subscribed[0] == "SNAP"
+	})
+
+	// Cancelling the last context unsubscribes SNAP and leaves the
+	// namespace.
+	cancel2()
+	waitFor(t, func() bool {
+		packets := transport.Written()
+		return len(packets) > 0 && packets[len(packets)-1] == "1/1.0/deep"
+	})
+
+	// Events after the namespace is closed are dropped.
+	var events []string
+	conn.AddEventCallback(func(event []byte) {
+		events = append(events, string(event))
+	})
+	transport.InjectPacket([]byte(`2/1.0/deep,["message","{}"]`))
+	if len(events) != 0 {
+		t.Errorf("expected no events after close, got: %v", events)
+	}
+}
+
+func TestDEEPConn_PauseResume(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Subscribe("AAPL", "SNAP"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	packets := transport.Written()
+	expected := []string{
+		`2/1.0/deep,["unsubscribe","AAPL"]`,
+		`2/1.0/deep,["unsubscribe","SNAP"]`,
+	}
+	if len(packets) != 5 ||
+		packets[3] != expected[0] || packets[4] != expected[1] {
+		t.Fatalf("unexpected packets after pause: %v", packets)
+	}
+
+	// The subscription set is retained and changeable while paused,
+	// without wire traffic.
+	if err := conn.Subscribe("FB"); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.Written()) != 5 {
+		t.Errorf("unexpected packets while paused: %v", transport.Written())
+	}
+
+	if err := conn.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	packets = transport.Written()
+	expected = []string{
+		`2/1.0/deep,["subscribe","AAPL"]`,
+		`2/1.0/deep,["subscribe","FB"]`,
+		`2/1.0/deep,["subscribe","SNAP"]`,
+	}
+	if len(packets) != 8 || packets[5] != expected[0] ||
+		packets[6] != expected[1] || packets[7] != expected[2] {
+		t.Fatalf("unexpected packets after resume: %v", packets)
+	}
+}
+
+func TestDEEPConn_PauseOutsideMarketHours(t *testing.T) {
+	transport := sockettest.NewTransport()
+	conn, err := NewDEEPConn(transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Subscribe("SNAP"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The market "opens" one minute after the clock's start time.
+	start := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	openAt := start.Add(time.Minute)
+	clock := iex.NewFakeClock(start)
+	isOpen := func(now time.Time) bool { return !now.Before(openAt) }
+
+	stop := conn.PauseOutsideMarketHours(clock, isOpen)
+	defer stop()
+
+	// Initially closed: the subscription is paused.
+	waitFor(t, func() bool {
+		packets := transport.Written()
+		return len(packets) == 3 &&
+			packets[2] == `2/1.0/deep,["unsubscribe","SNAP"]`
+	})
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	// Market open: the subscription is restored.
+	waitFor(t, func() bool {
+		packets := transport.Written()
+		return len(packets) == 4 &&
+			packets[3] == `2/1.0/deep,["subscribe","SNAP"]`
+	})
+}
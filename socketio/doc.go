@@ -0,0 +1,6 @@
+/*
+Package socketio implements a client for IEX's streaming socket.io API.
+
+https://iextrading.com/developer/docs/#websockets
+*/
+package socketio
@@ -0,0 +1,44 @@
+package socketio_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/timpalpant/go-iex/socketio"
+	"github.com/timpalpant/go-iex/socketio/sockettest"
+)
+
+// Subscribe to DEEP events for a symbol for the lifetime of a
+// context. In production the transport comes from NewWSTransport; a
+// fake keeps the example self-contained.
+func ExampleDEEPConn_SubscribeTo() {
+	transport := sockettest.NewTransport()
+
+	conn, err := socketio.NewDEEPConn(transport)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn.AddEventCallback(func(event []byte) {
+		fmt.Printf("event: %s\n", event)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := conn.SubscribeTo(ctx, "SNAP"); err != nil {
+		log.Fatal(err)
+	}
+
+	// An inbound event from the server is dispatched to the callback.
+	transport.InjectPacket([]byte(`2/1.0/deep,["message","{\"symbol\":\"SNAP\"}"]`))
+
+	for _, packet := range transport.Written() {
+		fmt.Printf("sent: %v\n", packet)
+	}
+
+	// Output:
+	// event: ["message","{\"symbol\":\"SNAP\"}"]
+	// sent: 0/1.0/deep
+	// sent: 2/1.0/deep,["subscribe","SNAP"]
+}
@@ -0,0 +1,42 @@
+package socketio
+
+import (
+	"encoding/json"
+	"time"
+
+	iex "github.com/timpalpant/go-iex"
+)
+
+// latencyEvent is the subset of a streaming feed message carrying a
+// send time. The TOPS feed reports lastUpdated and the Last feed
+// reports time, both in milliseconds since the epoch.
+type latencyEvent struct {
+	LastUpdated int64 `json:"lastUpdated"`
+	Time        int64 `json:"time"`
+}
+
+// NewLatencyCallback returns an event callback that records the delta
+// between each event's send time and the clock's current time into
+// the given histogram, for monitoring feed latency and clock skew on
+// the socket.io streams. Register it alongside the consuming
+// callbacks, e.g. with DEEPConn.AddEventCallback. Events without a
+// recognizable send time are ignored.
+func NewLatencyCallback(hist *iex.LatencyHistogram, clock iex.Clock) func(event []byte) {
+	return func(event []byte) {
+		var e latencyEvent
+		if err := json.Unmarshal(event, &e); err != nil {
+			return
+		}
+
+		sendMs := e.LastUpdated
+		if sendMs == 0 {
+			sendMs = e.Time
+		}
+		if sendMs == 0 {
+			return
+		}
+
+		sendTime := time.Unix(0, sendMs*int64(time.Millisecond))
+		hist.Observe(clock.Now().Sub(sendTime))
+	}
+}
@@ -0,0 +1,31 @@
+package socketio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex"
+)
+
+func TestNewLatencyCallback(t *testing.T) {
+	sendTime := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	clock := iex.NewFakeClock(sendTime.Add(7 * time.Millisecond))
+	hist := iex.NewLatencyHistogram()
+
+	cb := NewLatencyCallback(hist, clock)
+	cb([]byte(`{"symbol": "SNAP", "lastUpdated": 1492421400000}`))
+
+	if hist.Count() != 1 {
+		t.Fatalf("unexpected count: %v", hist.Count())
+	}
+	if hist.Max() != 7*time.Millisecond {
+		t.Errorf("unexpected latency: %v", hist.Max())
+	}
+
+	// Events without a send time are ignored.
+	cb([]byte(`{"symbol": "SNAP"}`))
+	cb([]byte(`not json`))
+	if hist.Count() != 1 {
+		t.Errorf("unexpected count: %v", hist.Count())
+	}
+}
@@ -0,0 +1,144 @@
+package socketio
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// recordedFrame is a single inbound frame captured from a Transport,
+// serialized as one JSON object per line.
+type recordedFrame struct {
+	// Time the frame was received, in nanoseconds since the Unix epoch.
+	Time int64 `json:"time"`
+	// The frame exactly as received on the wire.
+	Frame []byte `json:"frame"`
+}
+
+// Recorder saves all inbound frames from a Transport, with receive
+// timestamps, so that a session can be replayed later with a
+// ReplayTransport.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder creates a Recorder writing recorded frames to w.
+// Attach it to a transport with transport.AddRawCallback(r.Record).
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Record captures a single inbound frame. It has the signature
+// expected by Transport.AddRawCallback.
+func (r *Recorder) Record(frame []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enc.Encode(&recordedFrame{
+		Time:  time.Now().UnixNano(),
+		Frame: frame,
+	})
+}
+
+// ReplayTransport implements Transport by feeding back frames
+// previously captured by a Recorder, so that streaming sessions can
+// be reproduced deterministically in tests and bug reports.
+//
+// Frames written to the transport are discarded.
+type ReplayTransport struct {
+	frames []recordedFrame
+
+	mu              sync.Mutex
+	packetCallbacks []func([]byte)
+	rawCallbacks    []func([]byte)
+	closed          bool
+}
+
+// NewReplayTransport loads a recorded session from r.
+// Call Replay to deliver the recorded frames to the registered
+// callbacks.
+func NewReplayTransport(r io.Reader) (*ReplayTransport, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var frames []recordedFrame
+	for {
+		var frame recordedFrame
+		if err := dec.Decode(&frame); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, err
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return &ReplayTransport{frames: frames}, nil
+}
+
+// Replay delivers all recorded frames to the registered callbacks,
+// in order. If realtime is true, the original inter-frame delays are
+// reproduced; otherwise frames are delivered as fast as possible.
+func (t *ReplayTransport) Replay(realtime bool) {
+	var prev int64
+	for _, frame := range t.frames {
+		if realtime && prev != 0 {
+			time.Sleep(time.Duration(frame.Time - prev))
+		}
+		prev = frame.Time
+
+		t.dispatch(frame.Frame)
+	}
+}
+
+func (t *ReplayTransport) dispatch(frame []byte) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	rawCallbacks := t.rawCallbacks
+	packetCallbacks := t.packetCallbacks
+	t.mu.Unlock()
+
+	for _, cb := range rawCallbacks {
+		cb(frame)
+	}
+
+	if len(frame) == 0 || frame[0] != enginePacketMessage {
+		return
+	}
+
+	for _, cb := range packetCallbacks {
+		cb(frame[1:])
+	}
+}
+
+// Write implements Transport. Written packets are discarded.
+func (t *ReplayTransport) Write(packet []byte) error {
+	return nil
+}
+
+// AddPacketCallback implements Transport.
+func (t *ReplayTransport) AddPacketCallback(cb func(packet []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.packetCallbacks = append(t.packetCallbacks, cb)
+}
+
+// AddRawCallback implements Transport.
+func (t *ReplayTransport) AddRawCallback(cb func(frame []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rawCallbacks = append(t.rawCallbacks, cb)
+}
+
+// Close implements Transport.
+func (t *ReplayTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
@@ -0,0 +1,84 @@
+package socketio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	recorder.Record([]byte(testHandshake))
+	recorder.Record([]byte(`42["message","hello"]`))
+	recorder.Record([]byte(`42["message","world"]`))
+
+	transport, err := NewReplayTransport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transport.Close()
+
+	var frames, packets []string
+	transport.AddRawCallback(func(frame []byte) {
+		frames = append(frames, string(frame))
+	})
+	transport.AddPacketCallback(func(packet []byte) {
+		packets = append(packets, string(packet))
+	})
+
+	transport.Replay(false)
+
+	expectedFrames := []string{
+		`0{"sid":"test","pingInterval":60000,"pingTimeout":120000}`,
+		`42["message","hello"]`,
+		`42["message","world"]`,
+	}
+	if !reflect.DeepEqual(frames, expectedFrames) {
+		t.Fatalf("expected frames %v, got: %v", expectedFrames, frames)
+	}
+
+	expectedPackets := []string{
+		`2["message","hello"]`,
+		`2["message","world"]`,
+	}
+	if !reflect.DeepEqual(packets, expectedPackets) {
+		t.Fatalf("expected packets %v, got: %v", expectedPackets, packets)
+	}
+}
+
+func TestRecordLiveSession(t *testing.T) {
+	fs := newFakeServer(`42["message","hello"]`)
+	defer fs.Close()
+
+	transport, begin := dialTestTransport(t, fs)
+	defer transport.Close()
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	transport.AddRawCallback(recorder.Record)
+
+	// Wait until both the handshake and the message frame have
+	// been recorded.
+	cb, done := collectFrames(2)
+	transport.AddRawCallback(cb)
+	begin()
+	<-done
+
+	replay, err := NewReplayTransport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replay.Close()
+
+	var packets []string
+	replay.AddPacketCallback(func(packet []byte) {
+		packets = append(packets, string(packet))
+	})
+	replay.Replay(false)
+
+	expected := []string{`2["message","hello"]`}
+	if !reflect.DeepEqual(packets, expected) {
+		t.Fatalf("expected packets %v, got: %v", expected, packets)
+	}
+}
@@ -0,0 +1,100 @@
+// Package sockettest provides a fake transport for testing handlers
+// against the streaming socket.io client without a network
+// connection.
+package sockettest
+
+import "sync"
+
+// Transport is an in-memory implementation of socketio.Transport. It
+// records every packet written to it and lets tests inject inbound
+// packets and frames.
+//
+// This package deliberately does not import the socketio package, so
+// it can be used from socketio's own tests; the interface match is
+// verified in sockettest's tests.
+type Transport struct {
+	mu              sync.Mutex
+	written         [][]byte
+	packetCallbacks []func([]byte)
+	rawCallbacks    []func([]byte)
+	closed          bool
+}
+
+// NewTransport creates an empty fake transport.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Write implements socketio.Transport, recording the packet.
+func (t *Transport) Write(packet []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.written = append(t.written, append([]byte(nil), packet...))
+	return nil
+}
+
+// AddPacketCallback implements socketio.Transport.
+func (t *Transport) AddPacketCallback(cb func(packet []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.packetCallbacks = append(t.packetCallbacks, cb)
+}
+
+// AddRawCallback implements socketio.Transport.
+func (t *Transport) AddRawCallback(cb func(frame []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rawCallbacks = append(t.rawCallbacks, cb)
+}
+
+// Close implements socketio.Transport.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
+
+// Closed returns true if the transport has been closed.
+func (t *Transport) Closed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// InjectPacket delivers an inbound socket.io packet to the registered
+// packet callbacks, as if it had been received from the server.
+func (t *Transport) InjectPacket(packet []byte) {
+	t.mu.Lock()
+	callbacks := t.packetCallbacks
+	t.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(packet)
+	}
+}
+
+// InjectFrame delivers a raw inbound frame to the registered raw
+// callbacks.
+func (t *Transport) InjectFrame(frame []byte) {
+	t.mu.Lock()
+	callbacks := t.rawCallbacks
+	t.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(frame)
+	}
+}
+
+// Written returns the packets written so far, as strings for easy
+// assertions.
+func (t *Transport) Written() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	packets := make([]string, len(t.written))
+	for i, p := range t.written {
+		packets[i] = string(p)
+	}
+	return packets
+}
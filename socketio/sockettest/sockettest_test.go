@@ -0,0 +1,52 @@
+package sockettest_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-iex/socketio"
+	"github.com/timpalpant/go-iex/socketio/sockettest"
+)
+
+// The fake must satisfy the real transport interface.
+var _ socketio.Transport = (*sockettest.Transport)(nil)
+
+func TestTransport(t *testing.T) {
+	transport := sockettest.NewTransport()
+
+	if err := transport.Write([]byte("2/1.0/deep,[]")); err != nil {
+		t.Fatal(err)
+	}
+
+	written := transport.Written()
+	if len(written) != 1 || written[0] != "2/1.0/deep,[]" {
+		t.Errorf("unexpected written packets: %v", written)
+	}
+
+	var packets, frames []string
+	transport.AddPacketCallback(func(packet []byte) {
+		packets = append(packets, string(packet))
+	})
+	transport.AddRawCallback(func(frame []byte) {
+		frames = append(frames, string(frame))
+	})
+
+	transport.InjectPacket([]byte("2/1.0/deep,[1]"))
+	transport.InjectFrame([]byte("3"))
+
+	if len(packets) != 1 || packets[0] != "2/1.0/deep,[1]" {
+		t.Errorf("unexpected packets: %v", packets)
+	}
+	if len(frames) != 1 || frames[0] != "3" {
+		t.Errorf("unexpected frames: %v", frames)
+	}
+
+	if transport.Closed() {
+		t.Error("transport should not be closed yet")
+	}
+	if err := transport.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !transport.Closed() {
+		t.Error("transport should be closed")
+	}
+}
@@ -0,0 +1,187 @@
+package socketio
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+)
+
+// SpoolingTransport wraps a Transport with a disk-backed queue
+// between the transport read loop and packet callbacks. Inbound
+// packets are appended to a spool file and delivered to callbacks
+// from a separate goroutine, so temporary consumer slowness backs up
+// onto disk instead of blocking the read loop or dropping live
+// messages.
+type SpoolingTransport struct {
+	inner Transport
+	file  *os.File
+	// Maximum number of bytes queued on disk. Packets arriving while
+	// the spool is full are dropped and counted. Zero means no limit.
+	maxBytes int64
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// The spooled region of the file is [readOffset, writeOffset).
+	// When the spool drains completely the file is truncated and both
+	// offsets reset, so the file does not grow without bound.
+	readOffset  int64
+	writeOffset int64
+	pending     int
+	dropped     int64
+	closed      bool
+	callbacks   []func(packet []byte)
+}
+
+// NewSpoolingTransport wraps the given transport with a disk spool at
+// the given path. maxBytes bounds the spool size on disk; zero means
+// unlimited.
+func NewSpoolingTransport(inner Transport, path string, maxBytes int64) (*SpoolingTransport, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SpoolingTransport{
+		inner:    inner,
+		file:     file,
+		maxBytes: maxBytes,
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	inner.AddPacketCallback(s.enqueue)
+	go s.deliverLoop()
+	return s, nil
+}
+
+// Write implements Transport, passing writes through to the
+// underlying transport.
+func (s *SpoolingTransport) Write(packet []byte) error {
+	return s.inner.Write(packet)
+}
+
+// AddPacketCallback implements Transport. Callbacks are invoked from
+// the spool's delivery goroutine rather than the transport read loop.
+func (s *SpoolingTransport) AddPacketCallback(cb func(packet []byte)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, cb)
+}
+
+// AddRawCallback implements Transport. Raw callbacks bypass the spool
+// so recorders observe frames at wire time.
+func (s *SpoolingTransport) AddRawCallback(cb func(frame []byte)) {
+	s.inner.AddRawCallback(cb)
+}
+
+// Close implements Transport, closing the underlying transport and
+// discarding any packets still spooled.
+func (s *SpoolingTransport) Close() error {
+	err := s.inner.Close()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return err
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Depth returns the number of packets and bytes currently spooled.
+func (s *SpoolingTransport) Depth() (packets int, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending, s.writeOffset - s.readOffset
+}
+
+// Dropped returns the number of packets dropped because the spool was
+// full.
+func (s *SpoolingTransport) Dropped() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// enqueue appends a packet to the spool file. Each record is a 4-byte
+// little-endian length prefix followed by the packet.
+func (s *SpoolingTransport) enqueue(packet []byte) {
+	record := make([]byte, 4+len(packet))
+	binary.LittleEndian.PutUint32(record, uint32(len(packet)))
+	copy(record[4:], packet)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.maxBytes > 0 && s.writeOffset-s.readOffset+int64(len(record)) > s.maxBytes {
+		s.dropped++
+		return
+	}
+
+	if _, err := s.file.WriteAt(record, s.writeOffset); err != nil {
+		s.dropped++
+		return
+	}
+	s.writeOffset += int64(len(record))
+	s.pending++
+	s.cond.Signal()
+}
+
+// deliverLoop reads spooled packets in order and dispatches them to
+// the registered callbacks, until the transport is closed.
+func (s *SpoolingTransport) deliverLoop() {
+	for {
+		packet, callbacks, ok := s.next()
+		if !ok {
+			return
+		}
+
+		for _, cb := range callbacks {
+			cb(packet)
+		}
+	}
+}
+
+// next blocks until a packet is spooled, then dequeues it. ok is
+// false once the transport has been closed.
+func (s *SpoolingTransport) next() (packet []byte, callbacks []func([]byte), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.pending == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return nil, nil, false
+	}
+
+	var header [4]byte
+	if _, err := s.file.ReadAt(header[:], s.readOffset); err != nil {
+		return nil, nil, false
+	}
+	length := binary.LittleEndian.Uint32(header[:])
+	packet = make([]byte, length)
+	if _, err := s.file.ReadAt(packet, s.readOffset+4); err != nil {
+		return nil, nil, false
+	}
+
+	s.readOffset += 4 + int64(length)
+	s.pending--
+	if s.pending == 0 {
+		if err := s.file.Truncate(0); err == nil {
+			s.readOffset, s.writeOffset = 0, 0
+		}
+	}
+
+	return packet, s.callbacks, true
+}
+
+// Interface check.
+var _ Transport = (*SpoolingTransport)(nil)
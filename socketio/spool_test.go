@@ -0,0 +1,116 @@
+package socketio
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/timpalpant/go-iex/socketio/sockettest"
+)
+
+func TestSpoolingTransport(t *testing.T) {
+	inner := sockettest.NewTransport()
+	path := filepath.Join(t.TempDir(), "spool")
+	spool, err := NewSpoolingTransport(inner, path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	var mu sync.Mutex
+	var received []string
+	spool.AddPacketCallback(func(packet []byte) {
+		mu.Lock()
+		received = append(received, string(packet))
+		mu.Unlock()
+	})
+
+	inner.InjectPacket([]byte("one"))
+	inner.InjectPacket([]byte("two"))
+	inner.InjectPacket([]byte("three"))
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received[0] != "one" || received[1] != "two" || received[2] != "three" {
+		t.Errorf("packets delivered out of order: %v", received)
+	}
+}
+
+func TestSpoolingTransport_SlowConsumer(t *testing.T) {
+	inner := sockettest.NewTransport()
+	path := filepath.Join(t.TempDir(), "spool")
+	spool, err := NewSpoolingTransport(inner, path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	delivered := 0
+	spool.AddPacketCallback(func(packet []byte) {
+		<-release
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	})
+
+	// The consumer is stuck, but injection (the read loop) is not
+	// blocked: packets back up in the spool.
+	for i := 0; i < 10; i++ {
+		inner.InjectPacket([]byte("packet"))
+	}
+	waitFor(t, func() bool {
+		packets, bytes := spool.Depth()
+		return packets >= 9 && bytes > 0
+	})
+
+	close(release)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return delivered == 10
+	})
+
+	// Fully drained: the spool file has been reset.
+	waitFor(t, func() bool {
+		packets, bytes := spool.Depth()
+		return packets == 0 && bytes == 0
+	})
+}
+
+func TestSpoolingTransport_SizeLimit(t *testing.T) {
+	inner := sockettest.NewTransport()
+	path := filepath.Join(t.TempDir(), "spool")
+	// Room for two records of 4+6 bytes each, but not three.
+	spool, err := NewSpoolingTransport(inner, path, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	entered := make(chan struct{}, 5)
+	release := make(chan struct{})
+	defer close(release)
+	spool.AddPacketCallback(func(packet []byte) {
+		entered <- struct{}{}
+		<-release
+	})
+
+	// Wedge the consumer on the first packet, then fill the spool.
+	inner.InjectPacket([]byte("packet"))
+	<-entered
+	for i := 0; i < 4; i++ {
+		inner.InjectPacket([]byte("packet"))
+	}
+
+	if dropped := spool.Dropped(); dropped != 2 {
+		t.Errorf("expected 2 dropped packets, got: %v", dropped)
+	}
+}
@@ -0,0 +1,131 @@
+package socketio
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// TradeEvent is a trade normalized from either the TOPS or Last
+// streaming feeds, which report the same executions with different
+// shapes.
+type TradeEvent struct {
+	Symbol string
+	Price  float64
+	Size   int
+	Time   time.Time
+}
+
+// tradeKey identifies a trade across feeds. The feeds do not share a
+// trade ID, but (symbol, lastSaleTime, size, price) is unique in
+// practice: two identical fills in the same millisecond are reported
+// with distinct timestamps.
+type tradeKey struct {
+	symbol string
+	timeMs int64
+	size   int
+	price  float64
+}
+
+// How many recently seen trades to remember for de-duplication.
+const tradeDedupWindow = 1024
+
+// TradeEvents merges the TOPS and Last feeds into a single
+// de-duplicated stream of trades. Feed the raw feed payloads to
+// OnTOPSEvent and OnLastEvent; each distinct trade is delivered to
+// the registered callbacks exactly once, no matter how many feeds
+// report it.
+type TradeEvents struct {
+	mu        sync.Mutex
+	seen      map[tradeKey]bool
+	order     []tradeKey
+	callbacks []func(TradeEvent)
+}
+
+// NewTradeEvents creates an empty merged trade stream.
+func NewTradeEvents() *TradeEvents {
+	return &TradeEvents{
+		seen: make(map[tradeKey]bool),
+	}
+}
+
+// AddCallback registers a callback to be invoked once per distinct
+// trade.
+func (t *TradeEvents) AddCallback(cb func(TradeEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callbacks = append(t.callbacks, cb)
+}
+
+// topsEvent is the subset of a TOPS feed message that describes the
+// last trade.
+type topsEvent struct {
+	Symbol        string  `json:"symbol"`
+	LastSalePrice float64 `json:"lastSalePrice"`
+	LastSaleSize  int     `json:"lastSaleSize"`
+	LastSaleTime  int64   `json:"lastSaleTime"` // ms since epoch
+}
+
+// lastEvent is a Last feed message.
+type lastEvent struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+	Size   int     `json:"size"`
+	Time   int64   `json:"time"` // ms since epoch
+}
+
+// OnTOPSEvent feeds a raw TOPS message into the merged stream.
+func (t *TradeEvents) OnTOPSEvent(event []byte) error {
+	var e topsEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		return err
+	}
+
+	t.deliver(tradeKey{e.Symbol, e.LastSaleTime, e.LastSaleSize, e.LastSalePrice})
+	return nil
+}
+
+// OnLastEvent feeds a raw Last message into the merged stream.
+func (t *TradeEvents) OnLastEvent(event []byte) error {
+	var e lastEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		return err
+	}
+
+	t.deliver(tradeKey{e.Symbol, e.Time, e.Size, e.Price})
+	return nil
+}
+
+// deliver invokes the callbacks for the trade unless it has already
+// been seen on another feed.
+func (t *TradeEvents) deliver(key tradeKey) {
+	if key.size == 0 {
+		// TOPS quote updates carry no trade.
+		return
+	}
+
+	t.mu.Lock()
+	if t.seen[key] {
+		t.mu.Unlock()
+		return
+	}
+
+	t.seen[key] = true
+	t.order = append(t.order, key)
+	if len(t.order) > tradeDedupWindow {
+		delete(t.seen, t.order[0])
+		t.order = t.order[1:]
+	}
+	callbacks := t.callbacks
+	t.mu.Unlock()
+
+	trade := TradeEvent{
+		Symbol: key.symbol,
+		Price:  key.price,
+		Size:   key.size,
+		Time:   time.Unix(0, key.timeMs*int64(time.Millisecond)).UTC(),
+	}
+	for _, cb := range callbacks {
+		cb(trade)
+	}
+}
@@ -0,0 +1,70 @@
+package socketio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTradeEvents_Dedup(t *testing.T) {
+	events := NewTradeEvents()
+	var trades []TradeEvent
+	events.AddCallback(func(trade TradeEvent) {
+		trades = append(trades, trade)
+	})
+
+	// The same execution reported by both feeds.
+	topsMsg := []byte(`{"symbol":"SNAP","lastSalePrice":140.1,` +
+		`"lastSaleSize":100,"lastSaleTime":1480446905681}`)
+	lastMsg := []byte(`{"symbol":"SNAP","price":140.1,` +
+		`"size":100,"time":1480446905681}`)
+
+	if err := events.OnTOPSEvent(topsMsg); err != nil {
+		t.Fatal(err)
+	}
+	if err := events.OnLastEvent(lastMsg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trades) != 1 {
+		t.Fatalf("expected 1 de-duplicated trade, got: %v", trades)
+	}
+
+	trade := trades[0]
+	if trade.Symbol != "SNAP" || trade.Price != 140.1 || trade.Size != 100 {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+
+	expectedTime := time.Unix(0, 1480446905681*int64(time.Millisecond)).UTC()
+	if !trade.Time.Equal(expectedTime) {
+		t.Errorf("expected time %v, got: %v", expectedTime, trade.Time)
+	}
+
+	// A later trade for the same symbol is a distinct event.
+	later := []byte(`{"symbol":"SNAP","price":140.2,` +
+		`"size":50,"time":1480446906123}`)
+	if err := events.OnLastEvent(later); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 trades, got: %v", trades)
+	}
+}
+
+func TestTradeEvents_IgnoresQuoteOnlyUpdates(t *testing.T) {
+	events := NewTradeEvents()
+	var trades []TradeEvent
+	events.AddCallback(func(trade TradeEvent) {
+		trades = append(trades, trade)
+	})
+
+	// A TOPS quote update with no last sale.
+	quote := []byte(`{"symbol":"SNAP","bidPrice":140.0,"askPrice":140.2}`)
+	if err := events.OnTOPSEvent(quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trades) != 0 {
+		t.Errorf("expected no trades for quote-only update, got: %v", trades)
+	}
+}
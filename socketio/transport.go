@@ -0,0 +1,201 @@
+package socketio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	iex "github.com/timpalpant/go-iex"
+)
+
+const (
+	// DefaultEndpoint is the endpoint of IEX's streaming socket.io API.
+	DefaultEndpoint = "wss://ws-api.iextrading.com/socket.io/?EIO=3&transport=websocket"
+	// DefaultOrigin is the origin sent in the websocket handshake.
+	DefaultOrigin = "https://iextrading.com"
+)
+
+// Engine.io packet types. Each websocket frame is a single engine.io
+// packet whose first byte identifies the packet type.
+const (
+	enginePacketOpen    = '0'
+	enginePacketClose   = '1'
+	enginePacketPing    = '2'
+	enginePacketPong    = '3'
+	enginePacketMessage = '4'
+)
+
+// Transport represents a bidirectional stream of socket.io packets.
+type Transport interface {
+	// Write sends the given socket.io packet to the server.
+	Write(packet []byte) error
+	// AddPacketCallback registers a callback to be invoked with the
+	// content of each inbound socket.io message packet, with the
+	// engine.io framing removed.
+	AddPacketCallback(cb func(packet []byte))
+	// AddRawCallback registers a callback to be invoked with every
+	// inbound frame exactly as received on the wire, before any
+	// parsing is performed. It is intended for debugging tools and
+	// traffic recorders that need to capture the exact wire data.
+	AddRawCallback(cb func(frame []byte))
+	// Close shuts down the transport. Registered callbacks will not
+	// be invoked after Close returns.
+	Close() error
+}
+
+// handshake is the engine.io session description sent by the server
+// when the connection is opened.
+type handshake struct {
+	SID          string `json:"sid"`
+	PingInterval int    `json:"pingInterval"` // milliseconds
+	PingTimeout  int    `json:"pingTimeout"`  // milliseconds
+}
+
+// WSTransport implements Transport over a websocket connection.
+type WSTransport struct {
+	conn  *websocket.Conn
+	clock iex.Clock
+
+	mu              sync.Mutex
+	packetCallbacks []func([]byte)
+	rawCallbacks    []func([]byte)
+	closed          bool
+
+	heartbeatStop chan struct{}
+}
+
+// NewWSTransport connects to the given socket.io endpoint and returns
+// a Transport carrying socket.io packets over a websocket.
+func NewWSTransport(endpoint, origin string) (*WSTransport, error) {
+	conn, err := websocket.Dial(endpoint, "", origin)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWSTransportConn(conn, iex.SystemClock), nil
+}
+
+// NewWSTransportConn creates a WSTransport from an established
+// websocket connection, using the given Clock to schedule heartbeats.
+// It assumes ownership of the connection.
+func NewWSTransportConn(conn *websocket.Conn, clock iex.Clock) *WSTransport {
+	t := &WSTransport{
+		conn:          conn,
+		clock:         clock,
+		heartbeatStop: make(chan struct{}),
+	}
+
+	go t.readLoop()
+	return t
+}
+
+// Write implements Transport. The engine.io message framing is added
+// by the transport.
+func (t *WSTransport) Write(packet []byte) error {
+	frame := make([]byte, 0, len(packet)+1)
+	frame = append(frame, enginePacketMessage)
+	frame = append(frame, packet...)
+	return websocket.Message.Send(t.conn, string(frame))
+}
+
+// AddPacketCallback implements Transport.
+func (t *WSTransport) AddPacketCallback(cb func(packet []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.packetCallbacks = append(t.packetCallbacks, cb)
+}
+
+// AddRawCallback implements Transport.
+func (t *WSTransport) AddRawCallback(cb func(frame []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rawCallbacks = append(t.rawCallbacks, cb)
+}
+
+// Close implements Transport.
+func (t *WSTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	close(t.heartbeatStop)
+	t.mu.Unlock()
+
+	return t.conn.Close()
+}
+
+// readLoop receives frames from the websocket until the connection
+// is closed, dispatching each to the registered callbacks.
+func (t *WSTransport) readLoop() {
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(t.conn, &frame); err != nil {
+			t.Close()
+			return
+		}
+
+		t.dispatch(frame)
+	}
+}
+
+func (t *WSTransport) dispatch(frame []byte) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	rawCallbacks := t.rawCallbacks
+	packetCallbacks := t.packetCallbacks
+	t.mu.Unlock()
+
+	for _, cb := range rawCallbacks {
+		cb(frame)
+	}
+
+	if len(frame) == 0 {
+		return
+	}
+
+	switch frame[0] {
+	case enginePacketOpen:
+		var h handshake
+		if err := json.Unmarshal(frame[1:], &h); err == nil {
+			t.startHeartbeat(time.Duration(h.PingInterval) * time.Millisecond)
+		}
+	case enginePacketMessage:
+		for _, cb := range packetCallbacks {
+			cb(frame[1:])
+		}
+	case enginePacketClose:
+		t.Close()
+	}
+}
+
+// startHeartbeat sends an engine.io ping packet every interval to keep
+// the connection alive, until the transport is closed.
+func (t *WSTransport) startHeartbeat(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := t.clock.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		ping := fmt.Sprintf("%c", enginePacketPing)
+		for {
+			select {
+			case <-ticker.Chan():
+				if err := websocket.Message.Send(t.conn, ping); err != nil {
+					return
+				}
+			case <-t.heartbeatStop:
+				return
+			}
+		}
+	}()
+}
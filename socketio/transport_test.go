@@ -0,0 +1,195 @@
+package socketio
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	iex "github.com/timpalpant/go-iex"
+)
+
+// testHandshake is the engine.io handshake frame sent by fakeServer.
+const testHandshake = `0{"sid":"test","pingInterval":60000,"pingTimeout":120000}`
+
+// fakeServer is a websocket server that, once the client has sent its
+// first frame, sends the standard engine.io handshake followed by the
+// given frames. It records everything the client writes back.
+//
+// Waiting for the first client frame lets tests register their
+// callbacks before any inbound frames are dispatched.
+type fakeServer struct {
+	server *httptest.Server
+	frames []string
+
+	received chan string
+}
+
+func newFakeServer(frames ...string) *fakeServer {
+	fs := &fakeServer{
+		frames:   frames,
+		received: make(chan string, 64),
+	}
+
+	fs.server = httptest.NewServer(websocket.Handler(fs.handle))
+	return fs
+}
+
+func (fs *fakeServer) handle(conn *websocket.Conn) {
+	var first string
+	if err := websocket.Message.Receive(conn, &first); err != nil {
+		return
+	}
+	fs.received <- first
+
+	for _, frame := range append([]string{testHandshake}, fs.frames...) {
+		if err := websocket.Message.Send(conn, frame); err != nil {
+			return
+		}
+	}
+
+	for {
+		var frame string
+		if err := websocket.Message.Receive(conn, &frame); err != nil {
+			return
+		}
+
+		fs.received <- frame
+	}
+}
+
+func (fs *fakeServer) endpoint() string {
+	return strings.Replace(fs.server.URL, "http://", "ws://", 1)
+}
+
+func (fs *fakeServer) Close() {
+	fs.server.Close()
+}
+
+// dialTestTransport connects to the fake server and signals it to
+// begin sending frames. Callbacks should be registered on the
+// returned transport before calling begin.
+func dialTestTransport(t *testing.T, fs *fakeServer) (transport *WSTransport, begin func()) {
+	transport, err := NewWSTransport(fs.endpoint(), fs.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begin = func() {
+		if err := transport.Write([]byte("probe")); err != nil {
+			t.Fatal(err)
+		}
+		<-fs.received // The probe frame itself.
+	}
+
+	return transport, begin
+}
+
+func collectFrames(n int) (func([]byte), chan []string) {
+	var frames []string
+	done := make(chan []string, 1)
+	return func(frame []byte) {
+		frames = append(frames, string(frame))
+		if len(frames) == n {
+			done <- frames
+		}
+	}, done
+}
+
+func TestWSTransport_PacketCallback(t *testing.T) {
+	fs := newFakeServer(`42["message","hello"]`, `42["message","world"]`)
+	defer fs.Close()
+
+	transport, begin := dialTestTransport(t, fs)
+	defer transport.Close()
+	cb, done := collectFrames(2)
+	transport.AddPacketCallback(cb)
+	begin()
+
+	select {
+	case packets := <-done:
+		expected := []string{`2["message","hello"]`, `2["message","world"]`}
+		if !reflect.DeepEqual(packets, expected) {
+			t.Fatalf("expected packets %v, got: %v", expected, packets)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for packets")
+	}
+}
+
+func TestWSTransport_RawCallback(t *testing.T) {
+	fs := newFakeServer(`42["message","hello"]`)
+	defer fs.Close()
+
+	transport, begin := dialTestTransport(t, fs)
+	defer transport.Close()
+	// The raw callback should see every inbound frame unparsed,
+	// including the engine.io handshake.
+	cb, done := collectFrames(2)
+	transport.AddRawCallback(cb)
+	begin()
+
+	select {
+	case frames := <-done:
+		expected := []string{testHandshake, `42["message","hello"]`}
+		if !reflect.DeepEqual(frames, expected) {
+			t.Fatalf("expected frames %v, got: %v", expected, frames)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for frames")
+	}
+}
+
+func TestWSTransport_Write(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+
+	transport, _ := dialTestTransport(t, fs)
+	defer transport.Close()
+	if err := transport.Write([]byte(`2["subscribe","AAPL"]`)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case frame := <-fs.received:
+		if frame != `42["subscribe","AAPL"]` {
+			t.Fatalf("unexpected frame written: %v", frame)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for written frame")
+	}
+}
+
+func TestWSTransport_Heartbeat(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+
+	conn, err := websocket.Dial(fs.endpoint(), "", fs.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := iex.NewFakeClock(time.Now())
+	transport := NewWSTransportConn(conn, clock)
+	defer transport.Close()
+	if err := transport.Write([]byte("probe")); err != nil {
+		t.Fatal(err)
+	}
+	<-fs.received // The probe frame itself.
+
+	// The heartbeat ticker is created when the handshake is parsed.
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	select {
+	case frame := <-fs.received:
+		if frame != "2" {
+			t.Fatalf("expected ping frame, got: %v", frame)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ping frame")
+	}
+}
@@ -0,0 +1,190 @@
+// Package stats provides streaming statistics over bar sequences,
+// so indicators can be computed incrementally as bars are emitted
+// during replay or live consolidation.
+package stats
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-iex/consolidator"
+)
+
+// Pusher consumes a stream of values one at a time.
+type Pusher interface {
+	Push(x float64)
+}
+
+// PushBarCloses feeds the close price of each bar to p, in order.
+func PushBarCloses(p Pusher, bars []*consolidator.Bar) {
+	for _, bar := range bars {
+		p.Push(bar.Close)
+	}
+}
+
+// Rolling computes the mean and standard deviation of the trailing
+// window values.
+type Rolling struct {
+	window []float64
+	next   int
+	count  int
+	sum    float64
+	sumSq  float64
+}
+
+// NewRolling creates a Rolling statistic over a window of n values.
+func NewRolling(n int) *Rolling {
+	return &Rolling{window: make([]float64, n)}
+}
+
+// Push adds x, evicting the oldest value if the window is full.
+func (r *Rolling) Push(x float64) {
+	if r.count == len(r.window) {
+		old := r.window[r.next]
+		r.sum -= old
+		r.sumSq -= old * old
+	} else {
+		r.count++
+	}
+
+	r.window[r.next] = x
+	r.next = (r.next + 1) % len(r.window)
+	r.sum += x
+	r.sumSq += x * x
+}
+
+// Count returns the number of values currently in the window.
+func (r *Rolling) Count() int {
+	return r.count
+}
+
+// Full returns true once the window contains n values.
+func (r *Rolling) Full() bool {
+	return r.count == len(r.window)
+}
+
+// Mean returns the mean of the values in the window,
+// or 0 if the window is empty.
+func (r *Rolling) Mean() float64 {
+	if r.count == 0 {
+		return 0
+	}
+
+	return r.sum / float64(r.count)
+}
+
+// Std returns the sample standard deviation of the values in the
+// window, or 0 if the window holds fewer than two values.
+func (r *Rolling) Std() float64 {
+	if r.count < 2 {
+		return 0
+	}
+
+	n := float64(r.count)
+	variance := (r.sumSq - r.sum*r.sum/n) / (n - 1)
+	if variance < 0 { // Guard against rounding error.
+		return 0
+	}
+
+	return math.Sqrt(variance)
+}
+
+// EWMA computes an exponentially weighted moving average.
+type EWMA struct {
+	alpha float64
+	value float64
+	init  bool
+}
+
+// NewEWMA creates an EWMA with the given smoothing factor
+// 0 < alpha <= 1. Larger alpha discounts older values faster.
+func NewEWMA(alpha float64) *EWMA {
+	return &EWMA{alpha: alpha}
+}
+
+// NewEWMASpan creates an EWMA with alpha = 2 / (span + 1),
+// matching the span convention used by pandas.
+func NewEWMASpan(span float64) *EWMA {
+	return NewEWMA(2 / (span + 1))
+}
+
+// Push adds x. The first value initializes the average.
+func (e *EWMA) Push(x float64) {
+	if !e.init {
+		e.value = x
+		e.init = true
+		return
+	}
+
+	e.value = e.alpha*x + (1-e.alpha)*e.value
+}
+
+// Value returns the current average, or 0 before any values have
+// been pushed.
+func (e *EWMA) Value() float64 {
+	return e.value
+}
+
+// RealizedVolatility computes the realized volatility
+// sqrt(sum(r^2)) of log returns over the trailing window.
+type RealizedVolatility struct {
+	returns *Rolling
+	last    float64
+	init    bool
+}
+
+// NewRealizedVolatility creates a RealizedVolatility over a window
+// of n returns (n+1 prices).
+func NewRealizedVolatility(n int) *RealizedVolatility {
+	return &RealizedVolatility{returns: NewRolling(n)}
+}
+
+// Push adds a price observation. Non-positive prices are ignored.
+func (rv *RealizedVolatility) Push(price float64) {
+	if price <= 0 {
+		return
+	}
+
+	if rv.init {
+		rv.returns.Push(math.Log(price / rv.last))
+	}
+
+	rv.last = price
+	rv.init = true
+}
+
+// Value returns the realized volatility of the windowed returns.
+func (rv *RealizedVolatility) Value() float64 {
+	return math.Sqrt(rv.returns.sumSq)
+}
+
+// MaxDrawdown tracks the largest peak-to-trough decline seen so far,
+// as a fraction of the peak.
+type MaxDrawdown struct {
+	peak     float64
+	drawdown float64
+}
+
+// NewMaxDrawdown creates a MaxDrawdown statistic.
+func NewMaxDrawdown() *MaxDrawdown {
+	return &MaxDrawdown{}
+}
+
+// Push adds a price observation.
+func (m *MaxDrawdown) Push(price float64) {
+	if price > m.peak {
+		m.peak = price
+		return
+	}
+
+	if m.peak > 0 {
+		if dd := (m.peak - price) / m.peak; dd > m.drawdown {
+			m.drawdown = dd
+		}
+	}
+}
+
+// Value returns the maximum drawdown observed so far, as a fraction
+// in [0, 1].
+func (m *MaxDrawdown) Value() float64 {
+	return m.drawdown
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-iex/consolidator"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRolling(t *testing.T) {
+	r := NewRolling(3)
+	if r.Mean() != 0 || r.Std() != 0 {
+		t.Fatal("expected zero statistics for empty window")
+	}
+
+	r.Push(1)
+	r.Push(2)
+	r.Push(3)
+	if !r.Full() {
+		t.Fatal("expected full window after 3 values")
+	}
+	if !almostEqual(r.Mean(), 2) {
+		t.Errorf("expected mean 2, got: %v", r.Mean())
+	}
+	if !almostEqual(r.Std(), 1) {
+		t.Errorf("expected std 1, got: %v", r.Std())
+	}
+
+	// Push a fourth value; the first should be evicted.
+	r.Push(4)
+	if !almostEqual(r.Mean(), 3) {
+		t.Errorf("expected mean 3 after eviction, got: %v", r.Mean())
+	}
+	if r.Count() != 3 {
+		t.Errorf("expected count 3, got: %v", r.Count())
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := NewEWMA(0.5)
+	e.Push(2)
+	if !almostEqual(e.Value(), 2) {
+		t.Errorf("expected first value to initialize EWMA, got: %v", e.Value())
+	}
+
+	e.Push(4)
+	if !almostEqual(e.Value(), 3) {
+		t.Errorf("expected EWMA 3, got: %v", e.Value())
+	}
+
+	span := NewEWMASpan(3)
+	if !almostEqual(span.alpha, 0.5) {
+		t.Errorf("expected alpha 0.5 for span 3, got: %v", span.alpha)
+	}
+}
+
+func TestRealizedVolatility(t *testing.T) {
+	rv := NewRealizedVolatility(10)
+	rv.Push(100)
+	if rv.Value() != 0 {
+		t.Fatal("expected zero volatility with a single price")
+	}
+
+	rv.Push(110)
+	rv.Push(100)
+	r1 := math.Log(110.0 / 100.0)
+	r2 := math.Log(100.0 / 110.0)
+	expected := math.Sqrt(r1*r1 + r2*r2)
+	if !almostEqual(rv.Value(), expected) {
+		t.Errorf("expected volatility %v, got: %v", expected, rv.Value())
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	m := NewMaxDrawdown()
+	for _, price := range []float64{100, 120, 90, 110, 130} {
+		m.Push(price)
+	}
+
+	if !almostEqual(m.Value(), 0.25) {
+		t.Errorf("expected max drawdown 0.25, got: %v", m.Value())
+	}
+}
+
+func TestPushBarCloses(t *testing.T) {
+	bars := []*consolidator.Bar{
+		{Close: 1},
+		{Close: 2},
+		{Close: 3},
+	}
+
+	r := NewRolling(3)
+	PushBarCloses(r, bars)
+	if !almostEqual(r.Mean(), 2) {
+		t.Errorf("expected mean 2, got: %v", r.Mean())
+	}
+}
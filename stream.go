@@ -0,0 +1,218 @@
+package iex
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+)
+
+// DefaultStreamRetryInterval is how long StreamScanner waits between
+// reconnect attempts when no interval is configured.
+const DefaultStreamRetryInterval = time.Second
+
+// ErrStreamStopped is returned by NextMessage after the stream
+// scanner is stopped.
+var ErrStreamStopped = errors.New("stream scanner stopped")
+
+// DialFunc opens the underlying connection for a StreamScanner, e.g.
+// a closure over net.Dial or tls.Dial.
+type DialFunc func() (net.Conn, error)
+
+// StreamScanner reads IEXTP messages from a TCP or TLS segment
+// stream, such as an internal fan-out or retransmission service that
+// carries segments back to back. Broken connections are redialed
+// automatically, and on reconnect the scanner sends a retransmission
+// request for the first sequence number it has not yet delivered, so
+// messages replayed by the service are deduplicated and callers see
+// each message exactly once per session.
+type StreamScanner struct {
+	dial     DialFunc
+	clock    Clock
+	interval time.Duration
+
+	// The current connection, guarded so Stop can close it to
+	// unblock an in-progress read.
+	mu   sync.Mutex
+	conn net.Conn
+
+	reader *iextp.SegmentReader
+	// Messages from the last segment not yet delivered.
+	pending []iextp.Message
+
+	// The current session and the next message sequence number
+	// expected from it; zero until the first segment is seen.
+	sessionID uint32
+	nextSeq   int64
+
+	stop chan struct{}
+}
+
+// NewStreamScanner creates a StreamScanner reading from connections
+// opened by dial. The first connection is not opened until
+// NextMessage is called.
+func NewStreamScanner(dial DialFunc) *StreamScanner {
+	return newStreamScanner(dial, SystemClock)
+}
+
+func newStreamScanner(dial DialFunc, clock Clock) *StreamScanner {
+	return &StreamScanner{
+		dial:     dial,
+		clock:    clock,
+		interval: DefaultStreamRetryInterval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// SetRetryInterval changes the delay between reconnect attempts. It
+// must be called before NextMessage.
+func (s *StreamScanner) SetRetryInterval(interval time.Duration) {
+	s.interval = interval
+}
+
+// NextMessage returns the next message from the stream, reconnecting
+// as needed. It blocks until a message is available or the scanner is
+// stopped.
+func (s *StreamScanner) NextMessage() (iextp.Message, error) {
+	for {
+		if s.stopped() {
+			return nil, ErrStreamStopped
+		}
+
+		if len(s.pending) > 0 {
+			msg := s.pending[0]
+			s.pending = s.pending[1:]
+			return msg, nil
+		}
+
+		if s.reader == nil {
+			if err := s.connect(); err != nil {
+				if err := s.waitRetry(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		segment, err := s.reader.ReadSegment()
+		if err != nil {
+			if s.stopped() {
+				return nil, ErrStreamStopped
+			}
+
+			s.disconnect()
+			if err := s.waitRetry(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		s.fold(segment)
+	}
+}
+
+// Stop aborts any in-progress or future NextMessage calls, closing
+// the current connection to unblock a blocked read.
+func (s *StreamScanner) Stop() {
+	close(s.stop)
+
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.mu.Unlock()
+}
+
+func (s *StreamScanner) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// connect dials a new connection and, when resuming a session, sends
+// a retransmission request for the next expected sequence number.
+func (s *StreamScanner) connect() error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+
+	if s.nextSeq > 0 {
+		if err := writeRetransmissionRequest(conn, s.sessionID, s.nextSeq); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	s.reader = iextp.NewSegmentReader(conn)
+	return nil
+}
+
+func (s *StreamScanner) disconnect() {
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.mu.Unlock()
+	s.reader = nil
+}
+
+// waitRetry blocks for the retry interval, returning ErrStreamStopped
+// if the scanner is stopped first.
+func (s *StreamScanner) waitRetry() error {
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.Chan():
+		return nil
+	case <-s.stop:
+		return ErrStreamStopped
+	}
+}
+
+// fold queues the segment's messages for delivery, dropping any
+// already delivered before a reconnect. Sequence numbers reset with
+// the session, so a new session starts fresh.
+func (s *StreamScanner) fold(segment *iextp.Segment) {
+	first := segment.Header.FirstMessageSequenceNumber
+	if s.nextSeq == 0 || segment.Header.SessionID != s.sessionID {
+		s.sessionID = segment.Header.SessionID
+		s.nextSeq = first
+	}
+
+	skip := s.nextSeq - first
+	if skip < 0 {
+		// A gap the service did not fill; deliver what we have.
+		skip = 0
+	}
+	if skip >= int64(len(segment.Messages)) {
+		return
+	}
+
+	s.pending = segment.Messages[skip:]
+	s.nextSeq = first + int64(len(segment.Messages))
+}
+
+// writeRetransmissionRequest sends the 16-byte resume handshake
+// understood by segment fan-out services: the session being resumed
+// and the first wanted sequence number, both little-endian, with
+// bytes 4:8 reserved.
+func writeRetransmissionRequest(w net.Conn, sessionID uint32, firstSeq int64) error {
+	var request [16]byte
+	binary.LittleEndian.PutUint32(request[0:4], sessionID)
+	binary.LittleEndian.PutUint64(request[8:16], uint64(firstSeq))
+
+	_, err := w.Write(request[:])
+	return err
+}
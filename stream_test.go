@@ -0,0 +1,113 @@
+package iex
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// systemEventMessage builds a raw System Event message with the given
+// event byte.
+func systemEventMessage(event uint8) []byte {
+	return []byte{
+		0x53, event,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+}
+
+// makeStreamSegment builds a raw segment stamping the first message
+// sequence number, which makeSegment leaves zero.
+func makeStreamSegment(firstSeq int64, messages ...[]byte) []byte {
+	segment := makeSegment(
+		time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC), messages...)
+	binary.LittleEndian.PutUint64(segment[24:32], uint64(firstSeq))
+	return segment
+}
+
+func TestStreamScanner_ResumeAfterReconnect(t *testing.T) {
+	// The scripted server: the first connection serves messages 1-2
+	// and drops; the second verifies the retransmission request and
+	// re-serves message 2 alongside message 3.
+	conns := make(chan net.Conn, 2)
+	requests := make(chan [16]byte, 1)
+	go func() {
+		client, server := net.Pipe()
+		conns <- client
+		server.Write(makeStreamSegment(1,
+			systemEventMessage(tops.StartOfSystemHours),
+			systemEventMessage(tops.StartOfRegularMarketHours)))
+		server.Close()
+
+		client, server = net.Pipe()
+		conns <- client
+		var request [16]byte
+		if _, err := io.ReadFull(server, request[:]); err != nil {
+			return
+		}
+		requests <- request
+		server.Write(makeStreamSegment(2,
+			systemEventMessage(tops.StartOfRegularMarketHours),
+			systemEventMessage(tops.EndOfRegularMarketHours)))
+	}()
+
+	clock := NewFakeClock(time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC))
+	scanner := newStreamScanner(func() (net.Conn, error) {
+		return <-conns, nil
+	}, clock)
+
+	type result struct {
+		msg iextp.Message
+		err error
+	}
+	results := make(chan result)
+	go func() {
+		for {
+			msg, err := scanner.NextMessage()
+			results <- result{msg, err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	expectEvent := func(event uint8) {
+		t.Helper()
+		r := <-results
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		msg, ok := r.msg.(*tops.SystemEventMessage)
+		if !ok || msg.SystemEvent != event {
+			t.Fatalf("expected system event %v, got: %+v", event, r.msg)
+		}
+	}
+
+	expectEvent(tops.StartOfSystemHours)
+	expectEvent(tops.StartOfRegularMarketHours)
+
+	// The first connection has dropped; release the reconnect delay.
+	clock.BlockUntil(1)
+	clock.Advance(DefaultStreamRetryInterval)
+
+	// Message 2 is replayed by the server but already delivered, so
+	// the next message out is message 3.
+	expectEvent(tops.EndOfRegularMarketHours)
+
+	request := <-requests
+	if sessionID := binary.LittleEndian.Uint32(request[0:4]); sessionID != 42 {
+		t.Errorf("unexpected session in retransmission request: %v", sessionID)
+	}
+	if firstSeq := binary.LittleEndian.Uint64(request[8:16]); firstSeq != 3 {
+		t.Errorf("unexpected sequence in retransmission request: %v", firstSeq)
+	}
+
+	scanner.Stop()
+	if r := <-results; r.err != ErrStreamStopped {
+		t.Errorf("expected ErrStreamStopped, got: %v", r.err)
+	}
+}
@@ -0,0 +1,37 @@
+package summary_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+	"github.com/timpalpant/go-iex/summary"
+)
+
+// Fold a day's messages into per-symbol summaries. In a real pipeline
+// the messages come from a PcapScanner over a HIST dump.
+func ExampleReport() {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+
+	report := summary.NewReport()
+	report.OnMessage(&tops.OfficialPriceMessage{
+		PriceType:     tops.OpeningPrice,
+		Symbol:        "SNAP",
+		OfficialPrice: 20.00,
+		Timestamp:     t0,
+	})
+	report.OnMessage(&tops.TradeReportMessage{
+		Symbol: "SNAP", Price: 20.10, Size: 100, Timestamp: t0.Add(time.Minute),
+	})
+	report.OnMessage(&tops.TradeReportMessage{
+		Symbol: "SNAP", Price: 20.30, Size: 300, Timestamp: t0.Add(2 * time.Minute),
+	})
+
+	for _, s := range report.Summaries() {
+		fmt.Printf("%v: open=%.2f volume=%v vwap=%.4f high=%.2f low=%.2f\n",
+			s.Symbol, s.OfficialOpen, s.Volume, s.VWAP, s.High, s.Low)
+	}
+
+	// Output:
+	// SNAP: open=20.00 volume=400 vwap=20.2500 high=20.30 low=20.10
+}
@@ -0,0 +1,190 @@
+// Package summary builds per-symbol end-of-day summaries from a
+// decode pass over a HIST dump: official open/close, volumes, halts,
+// closing auction results, VWAP, and the day's high/low with their
+// times.
+package summary
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// oddLotSize is the share count below which a trade is an odd lot.
+const oddLotSize = 100
+
+// SymbolSummary is one symbol's daily summary row.
+type SymbolSummary struct {
+	Symbol string
+	// IEX official opening and closing prices, zero if not observed.
+	OfficialOpen  float64
+	OfficialClose float64
+	// Total single-counted trade volume, and the portion executed in
+	// odd lots (fewer than 100 shares).
+	Volume       int64
+	OddLotVolume int64
+	// Number of times the symbol entered a trading halt.
+	Halts int
+	// Results of the closing auction, from its final update.
+	ClosingAuctionShares uint32
+	ClosingAuctionPrice  float64
+	// Volume-weighted average trade price.
+	VWAP float64
+	// The day's trade price extremes and when they occurred.
+	High     float64
+	HighTime time.Time
+	Low      float64
+	LowTime  time.Time
+
+	// VWAP numerator, accumulated across trades.
+	notional float64
+	// Last observed trading status, for counting halt transitions.
+	lastStatus uint8
+}
+
+// Report accumulates daily summaries for all symbols seen in a feed.
+type Report struct {
+	symbols map[string]*SymbolSummary
+}
+
+// NewReport creates an empty end-of-day report.
+func NewReport() *Report {
+	return &Report{
+		symbols: make(map[string]*SymbolSummary),
+	}
+}
+
+func (r *Report) symbol(symbol string) *SymbolSummary {
+	s, ok := r.symbols[symbol]
+	if !ok {
+		s = &SymbolSummary{
+			Symbol:     symbol,
+			lastStatus: tops.Trading,
+		}
+		r.symbols[symbol] = s
+	}
+
+	return s
+}
+
+// OnMessage folds the given message into the report. Messages that do
+// not contribute to the summaries are ignored.
+func (r *Report) OnMessage(msg iextp.Message) {
+	switch msg := msg.(type) {
+	case *tops.TradeReportMessage:
+		s := r.symbol(msg.Symbol)
+		size := int64(msg.Size)
+		s.Volume += size
+		if msg.Size < oddLotSize {
+			s.OddLotVolume += size
+		}
+		s.notional += msg.Price * float64(msg.Size)
+		s.VWAP = s.notional / float64(s.Volume)
+		if msg.Price > s.High {
+			s.High = msg.Price
+			s.HighTime = msg.Timestamp
+		}
+		if s.Low == 0 || msg.Price < s.Low {
+			s.Low = msg.Price
+			s.LowTime = msg.Timestamp
+		}
+	case *tops.OfficialPriceMessage:
+		s := r.symbol(msg.Symbol)
+		switch msg.PriceType {
+		case tops.OpeningPrice:
+			s.OfficialOpen = msg.OfficialPrice
+		case tops.ClosingPrice:
+			s.OfficialClose = msg.OfficialPrice
+		}
+	case *tops.TradingStatusMessage:
+		s := r.symbol(msg.Symbol)
+		if msg.TradingStatus == tops.TradingHalt && s.lastStatus != tops.TradingHalt {
+			s.Halts++
+		}
+		s.lastStatus = msg.TradingStatus
+	case *tops.AuctionInformationMessage:
+		if msg.AuctionType == tops.ClosingAuction {
+			s := r.symbol(msg.Symbol)
+			s.ClosingAuctionShares = msg.PairedShares
+			s.ClosingAuctionPrice = msg.AuctionBookClearingPrice
+		}
+	}
+}
+
+// Summaries returns the accumulated summaries, sorted by symbol.
+func (r *Report) Summaries() []*SymbolSummary {
+	summaries := make([]*SymbolSummary, 0, len(r.symbols))
+	for _, s := range r.symbols {
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Symbol < summaries[j].Symbol
+	})
+
+	return summaries
+}
+
+var csvHeader = []string{
+	"symbol",
+	"officialOpen",
+	"officialClose",
+	"volume",
+	"oddLotVolume",
+	"halts",
+	"closingAuctionShares",
+	"closingAuctionPrice",
+	"vwap",
+	"high",
+	"highTime",
+	"low",
+	"lowTime",
+}
+
+// WriteCSV writes the report as CSV, one row per symbol.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, s := range r.Summaries() {
+		row := []string{
+			s.Symbol,
+			formatPrice(s.OfficialOpen),
+			formatPrice(s.OfficialClose),
+			strconv.FormatInt(s.Volume, 10),
+			strconv.FormatInt(s.OddLotVolume, 10),
+			strconv.Itoa(s.Halts),
+			strconv.FormatUint(uint64(s.ClosingAuctionShares), 10),
+			formatPrice(s.ClosingAuctionPrice),
+			formatPrice(s.VWAP),
+			formatPrice(s.High),
+			formatTime(s.HighTime),
+			formatPrice(s.Low),
+			formatTime(s.LowTime),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 4, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
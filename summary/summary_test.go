@@ -0,0 +1,115 @@
+package summary
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+func trade(symbol string, ts time.Time, price float64, size uint32) *tops.TradeReportMessage {
+	return &tops.TradeReportMessage{
+		Symbol:    symbol,
+		Timestamp: ts,
+		Price:     price,
+		Size:      size,
+	}
+}
+
+func TestReport(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	report := NewReport()
+
+	report.OnMessage(&tops.OfficialPriceMessage{
+		PriceType:     tops.OpeningPrice,
+		Symbol:        "SNAP",
+		OfficialPrice: 22.50,
+	})
+	report.OnMessage(trade("SNAP", t0, 22.50, 100))
+	report.OnMessage(trade("SNAP", t0.Add(time.Hour), 23.00, 50))
+	report.OnMessage(trade("SNAP", t0.Add(2*time.Hour), 22.00, 100))
+	report.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.TradingHalt,
+		Symbol:        "SNAP",
+	})
+	// A repeated halt status is not a new halt.
+	report.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.TradingHalt,
+		Symbol:        "SNAP",
+	})
+	report.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.Trading,
+		Symbol:        "SNAP",
+	})
+	report.OnMessage(&tops.AuctionInformationMessage{
+		AuctionType:              tops.ClosingAuction,
+		Symbol:                   "SNAP",
+		PairedShares:             10000,
+		AuctionBookClearingPrice: 22.61,
+	})
+	report.OnMessage(&tops.OfficialPriceMessage{
+		PriceType:     tops.ClosingPrice,
+		Symbol:        "SNAP",
+		OfficialPrice: 22.61,
+	})
+	report.OnMessage(trade("AAPL", t0, 140.0, 100))
+
+	summaries := report.Summaries()
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 summaries, got: %v", len(summaries))
+	}
+	if summaries[0].Symbol != "AAPL" || summaries[1].Symbol != "SNAP" {
+		t.Errorf("expected summaries sorted by symbol: %v, %v",
+			summaries[0].Symbol, summaries[1].Symbol)
+	}
+
+	snap := summaries[1]
+	if snap.OfficialOpen != 22.50 || snap.OfficialClose != 22.61 {
+		t.Errorf("unexpected official prices: %+v", snap)
+	}
+	if snap.Volume != 250 || snap.OddLotVolume != 50 {
+		t.Errorf("unexpected volumes: %+v", snap)
+	}
+	if snap.Halts != 1 {
+		t.Errorf("expected 1 halt, got: %v", snap.Halts)
+	}
+	if snap.ClosingAuctionShares != 10000 || snap.ClosingAuctionPrice != 22.61 {
+		t.Errorf("unexpected closing auction: %+v", snap)
+	}
+	wantVWAP := (22.50*100 + 23.00*50 + 22.00*100) / 250
+	if snap.VWAP != wantVWAP {
+		t.Errorf("unexpected VWAP: %v != %v", snap.VWAP, wantVWAP)
+	}
+	if snap.High != 23.00 || !snap.HighTime.Equal(t0.Add(time.Hour)) {
+		t.Errorf("unexpected high: %+v", snap)
+	}
+	if snap.Low != 22.00 || !snap.LowTime.Equal(t0.Add(2*time.Hour)) {
+		t.Errorf("unexpected low: %+v", snap)
+	}
+}
+
+func TestReportWriteCSV(t *testing.T) {
+	t0 := time.Date(2017, time.April, 17, 9, 30, 0, 0, time.UTC)
+	report := NewReport()
+	report.OnMessage(trade("SNAP", t0, 22.50, 100))
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got: %v", lines)
+	}
+	if !strings.HasPrefix(lines[0], "symbol,officialOpen,officialClose") {
+		t.Errorf("unexpected header: %v", lines[0])
+	}
+	want := "SNAP,0.0000,0.0000,100,0,0,0,0.0000,22.5000,22.5000," +
+		"2017-04-17T09:30:00Z,22.5000,2017-04-17T09:30:00Z"
+	if lines[1] != want {
+		t.Errorf("unexpected row:\ngot:  %v\nwant: %v", lines[1], want)
+	}
+}
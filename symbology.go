@@ -0,0 +1,90 @@
+package iex
+
+import "strings"
+
+// InstrumentType classifies a security by the suffix conventions of
+// Nasdaq integrated symbology, as used for IEX listing-venue symbols.
+type InstrumentType string
+
+const (
+	CommonStock    InstrumentType = "cs"
+	ClassShares    InstrumentType = "class"
+	PreferredStock InstrumentType = "ps"
+	Warrant        InstrumentType = "wt"
+	Unit           InstrumentType = "ut"
+	Right          InstrumentType = "rt"
+	CalledSecurity InstrumentType = "called"
+	WhenIssued     InstrumentType = "wi"
+)
+
+// ParsedSymbol is a symbol decomposed into its base symbol and the
+// instrument type implied by its suffix. Symbols like "AIG+"
+// (warrants) and units with "=" break naive normalization; use
+// ParseSymbol when matching user-supplied symbols against feed data.
+type ParsedSymbol struct {
+	// The symbol exactly as it appears on the feed.
+	Raw string
+	// The symbol with any suffix conventions removed, e.g. "AIG"
+	// for "AIG+".
+	Base string
+	// The class or series, if any, e.g. "A" for "BRK.A" or "AGM-A".
+	Class string
+	// The instrument type implied by the suffix.
+	Type InstrumentType
+}
+
+// Suffix characters in Nasdaq integrated symbology.
+const symbolSuffixes = "+-=^#*."
+
+// ParseSymbol decomposes a symbol in Nasdaq integrated symbology
+// into its base symbol, class, and instrument type.
+func ParseSymbol(raw string) ParsedSymbol {
+	parsed := ParsedSymbol{
+		Raw:  raw,
+		Base: raw,
+		Type: CommonStock,
+	}
+
+	i := strings.IndexAny(raw, symbolSuffixes)
+	if i <= 0 {
+		// No suffix, or a bare suffix character; treat the whole
+		// symbol as the base.
+		return parsed
+	}
+
+	parsed.Base = raw[:i]
+	rest := raw[i+1:]
+	switch raw[i] {
+	case '+':
+		parsed.Type = Warrant
+		parsed.Class = rest
+	case '-':
+		parsed.Type = PreferredStock
+		parsed.Class = rest
+	case '=':
+		parsed.Type = Unit
+	case '^':
+		parsed.Type = Right
+	case '#':
+		parsed.Type = WhenIssued
+	case '*':
+		parsed.Type = CalledSecurity
+	case '.':
+		parsed.Type = ClassShares
+		parsed.Class = rest
+	}
+
+	return parsed
+}
+
+// Matches returns true if the given symbol refers to the same
+// security, comparing raw symbols exactly and treating a bare base
+// symbol as matching all of its listings.
+func (p ParsedSymbol) Matches(symbol string) bool {
+	if symbol == p.Raw {
+		return true
+	}
+
+	other := ParseSymbol(symbol)
+	return other.Raw == other.Base && other.Base == p.Base
+}
@@ -0,0 +1,49 @@
+package iex
+
+import "testing"
+
+func TestParseSymbol(t *testing.T) {
+	cases := []struct {
+		raw      string
+		base     string
+		class    string
+		instType InstrumentType
+	}{
+		{"AAPL", "AAPL", "", CommonStock},
+		{"AIG+", "AIG", "", Warrant},
+		{"AIG+B", "AIG", "B", Warrant},
+		{"AGM-A", "AGM", "A", PreferredStock},
+		{"BRK.A", "BRK", "A", ClassShares},
+		{"HCAC=", "HCAC", "", Unit},
+		{"XYZ^", "XYZ", "", Right},
+		{"XYZ#", "XYZ", "", WhenIssued},
+		{"XYZ*", "XYZ", "", CalledSecurity},
+		// Degenerate input: a bare suffix character.
+		{"+", "+", "", CommonStock},
+	}
+
+	for _, tc := range cases {
+		parsed := ParseSymbol(tc.raw)
+		if parsed.Base != tc.base || parsed.Class != tc.class ||
+			parsed.Type != tc.instType {
+			t.Errorf("ParseSymbol(%q) = %+v, expected base=%q class=%q type=%q",
+				tc.raw, parsed, tc.base, tc.class, tc.instType)
+		}
+	}
+}
+
+func TestParsedSymbolMatches(t *testing.T) {
+	warrant := ParseSymbol("AIG+")
+	if !warrant.Matches("AIG+") {
+		t.Error("expected exact raw match")
+	}
+	if !warrant.Matches("AIG") {
+		t.Error("expected bare base symbol to match its listings")
+	}
+	if warrant.Matches("AIG-A") {
+		t.Error("expected distinct suffixed symbols not to match")
+	}
+	if warrant.Matches("AI") {
+		t.Error("expected unrelated symbols not to match")
+	}
+}
@@ -0,0 +1,305 @@
+// Package tca computes transaction-cost-analysis benchmarks for a
+// user-supplied list of executions against the decoded TOPS data of
+// the day they traded: arrival-price slippage and spread capture per
+// execution, measured against the prevailing IEX quote at the time
+// each order arrived.
+package tca
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Side is the side of an execution.
+type Side string
+
+const (
+	Buy  Side = "B"
+	Sell Side = "S"
+)
+
+// ParseSide parses a side field, accepting "B", "BUY", "S", or "SELL"
+// in any case.
+func ParseSide(value string) (Side, error) {
+	switch strings.ToUpper(value) {
+	case "B", "BUY":
+		return Buy, nil
+	case "S", "SELL":
+		return Sell, nil
+	}
+
+	return "", fmt.Errorf("invalid side: %q", value)
+}
+
+// sign is +1 for buys and -1 for sells, so side-adjusted costs are
+// positive when the execution was worse than the benchmark.
+func (s Side) sign() float64 {
+	if s == Sell {
+		return -1
+	}
+
+	return 1
+}
+
+// Execution is one fill from the user's trade list.
+type Execution struct {
+	Symbol    string
+	Timestamp time.Time
+	Side      Side
+	Size      int64
+	Price     float64
+}
+
+// ReadExecutions parses a CSV trade list with columns
+// symbol,time,side,size,price. Times are RFC3339; a header row is
+// skipped if present.
+func ReadExecutions(r io.Reader) ([]*Execution, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]*Execution, 0, len(records))
+	for i, record := range records {
+		if len(record) != 5 {
+			return nil, fmt.Errorf("line %v: expected 5 columns, got %v",
+				i+1, len(record))
+		}
+		if i == 0 && strings.EqualFold(record[0], "symbol") {
+			continue
+		}
+
+		execution, err := parseExecution(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		}
+
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
+
+func parseExecution(record []string) (*Execution, error) {
+	timestamp, err := time.Parse(time.RFC3339, record[1])
+	if err != nil {
+		return nil, err
+	}
+	side, err := ParseSide(record[2])
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.ParseInt(record[3], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	price, err := strconv.ParseFloat(record[4], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Execution{
+		Symbol:    strings.ToUpper(record[0]),
+		Timestamp: timestamp,
+		Side:      side,
+		Size:      size,
+		Price:     price,
+	}, nil
+}
+
+// Benchmark is one execution's arrival-price benchmarks.
+type Benchmark struct {
+	Execution *Execution
+	// Whether a quote for the symbol had been observed by the arrival
+	// time. The remaining fields are zero when false.
+	HasQuote bool
+	// The prevailing quote when the execution arrived.
+	ArrivalBid float64
+	ArrivalAsk float64
+	// Midpoint and quoted spread of the arrival quote.
+	ArrivalMid   float64
+	QuotedSpread float64
+	// Side-adjusted cost per share versus the arrival midpoint:
+	// positive means the fill was worse than mid (a buy above it or a
+	// sell below it).
+	SlippagePerShare float64
+	// SlippagePerShare as basis points of the arrival midpoint.
+	SlippageBPS float64
+	// Fraction of the half-spread saved versus crossing it: 1 for a
+	// fill at mid, 0 for a fill at the touch, negative for a fill
+	// outside the quote. Zero when the quote was locked.
+	SpreadCapture float64
+}
+
+// quote is the last observed bid/ask for one symbol.
+type quote struct {
+	bid, ask float64
+}
+
+// Calculator streams feed messages, snapshotting the prevailing quote
+// for each execution as the feed clock passes its arrival time.
+type Calculator struct {
+	// Executions not yet benchmarked, sorted by arrival time.
+	pending []*Execution
+	quotes  map[string]quote
+	results []*Benchmark
+}
+
+// NewCalculator creates a Calculator for the given trade list.
+func NewCalculator(executions []*Execution) *Calculator {
+	pending := make([]*Execution, len(executions))
+	copy(pending, executions)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Timestamp.Before(pending[j].Timestamp)
+	})
+
+	return &Calculator{
+		pending: pending,
+		quotes:  make(map[string]quote),
+	}
+}
+
+// OnMessage folds one feed message into the calculator. Messages must
+// be delivered in feed order.
+func (c *Calculator) OnMessage(msg iextp.Message) {
+	// Resolve arrivals strictly before this message's event time, so
+	// a quote update at the arrival instant itself is not mistaken
+	// for the prevailing quote.
+	if update, ok := msg.(*tops.QuoteUpdateMessage); ok {
+		c.advance(update.Timestamp)
+		c.quotes[update.Symbol] = quote{
+			bid: update.BidPrice,
+			ask: update.AskPrice,
+		}
+	} else if trade, ok := msg.(*tops.TradeReportMessage); ok {
+		c.advance(trade.Timestamp)
+	}
+}
+
+// advance benchmarks every pending execution that arrived before the
+// given feed time.
+func (c *Calculator) advance(now time.Time) {
+	for len(c.pending) > 0 && c.pending[0].Timestamp.Before(now) {
+		c.results = append(c.results, c.benchmark(c.pending[0]))
+		c.pending = c.pending[1:]
+	}
+}
+
+// Results benchmarks any executions still pending against the last
+// observed quotes and returns all benchmarks in arrival order.
+func (c *Calculator) Results() []*Benchmark {
+	for _, execution := range c.pending {
+		c.results = append(c.results, c.benchmark(execution))
+	}
+	c.pending = nil
+
+	return c.results
+}
+
+func (c *Calculator) benchmark(execution *Execution) *Benchmark {
+	b := &Benchmark{Execution: execution}
+
+	q, ok := c.quotes[execution.Symbol]
+	if !ok || q.bid == 0 || q.ask == 0 {
+		return b
+	}
+
+	b.HasQuote = true
+	b.ArrivalBid = q.bid
+	b.ArrivalAsk = q.ask
+	b.ArrivalMid = (q.bid + q.ask) / 2
+	b.QuotedSpread = q.ask - q.bid
+
+	b.SlippagePerShare = execution.Side.sign() * (execution.Price - b.ArrivalMid)
+	b.SlippageBPS = b.SlippagePerShare / b.ArrivalMid * 1e4
+	if halfSpread := b.QuotedSpread / 2; halfSpread > 0 {
+		b.SpreadCapture = (halfSpread - b.SlippagePerShare) / halfSpread
+	}
+
+	return b
+}
+
+var csvHeader = []string{
+	"symbol",
+	"time",
+	"side",
+	"size",
+	"price",
+	"arrivalBid",
+	"arrivalAsk",
+	"arrivalMid",
+	"quotedSpread",
+	"slippagePerShare",
+	"slippageBps",
+	"spreadCapture",
+}
+
+// WriteCSV writes one row per execution in arrival order, resolving
+// any still-pending executions first. Benchmark columns are empty for
+// executions with no observed arrival quote.
+func (c *Calculator) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, b := range c.Results() {
+		execution := b.Execution
+		row := []string{
+			execution.Symbol,
+			execution.Timestamp.Format(time.RFC3339Nano),
+			string(execution.Side),
+			strconv.FormatInt(execution.Size, 10),
+			formatPrice(execution.Price),
+		}
+
+		if b.HasQuote {
+			row = append(row,
+				formatPrice(b.ArrivalBid),
+				formatPrice(b.ArrivalAsk),
+				formatPrice(b.ArrivalMid),
+				formatPrice(b.QuotedSpread),
+				formatPrice(b.SlippagePerShare),
+				formatBPS(b.SlippageBPS),
+				formatBPS(b.SpreadCapture),
+			)
+		} else {
+			row = append(row, "", "", "", "", "", "", "")
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 4, 64)
+}
+
+func formatBPS(value float64) string {
+	return strconv.FormatFloat(roundBPS(value), 'f', 2, 64)
+}
+
+// roundBPS avoids "-0.00" rows for slippage within rounding noise.
+func roundBPS(value float64) float64 {
+	rounded := math.Round(value*100) / 100
+	if rounded == 0 {
+		return 0
+	}
+
+	return rounded
+}
@@ -0,0 +1,133 @@
+package tca
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var t0 = time.Date(2017, time.April, 17, 14, 30, 0, 0, time.UTC)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestReadExecutions(t *testing.T) {
+	input := strings.Join([]string{
+		"symbol,time,side,size,price",
+		"snap,2017-04-17T14:30:10Z,buy,100,20.08",
+		"SNAP,2017-04-17T14:30:30Z,S,50,20.12",
+	}, "\n")
+
+	executions, err := ReadExecutions(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 executions, got %v", len(executions))
+	}
+
+	first := executions[0]
+	if first.Symbol != "SNAP" || first.Side != Buy ||
+		first.Size != 100 || first.Price != 20.08 ||
+		!first.Timestamp.Equal(t0.Add(10*time.Second)) {
+		t.Errorf("unexpected execution: %+v", first)
+	}
+	if executions[1].Side != Sell {
+		t.Errorf("expected sell side, got %v", executions[1].Side)
+	}
+
+	if _, err := ReadExecutions(strings.NewReader(
+		"SNAP,2017-04-17T14:30:10Z,X,100,20.08")); err == nil {
+		t.Error("expected error for invalid side")
+	}
+}
+
+func TestCalculator(t *testing.T) {
+	executions := []*Execution{
+		// Arrives before any quote for its symbol.
+		{Symbol: "FB", Timestamp: t0.Add(5 * time.Second), Side: Buy, Size: 100, Price: 140.00},
+		{Symbol: "SNAP", Timestamp: t0.Add(10 * time.Second), Side: Buy, Size: 100, Price: 20.08},
+		{Symbol: "SNAP", Timestamp: t0.Add(30 * time.Second), Side: Sell, Size: 50, Price: 20.12},
+	}
+
+	calc := NewCalculator(executions)
+	calc.OnMessage(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", Timestamp: t0, BidPrice: 20.00, AskPrice: 20.10,
+	})
+	calc.OnMessage(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", Timestamp: t0.Add(20 * time.Second), BidPrice: 20.10, AskPrice: 20.20,
+	})
+	calc.OnMessage(&tops.TradeReportMessage{
+		Symbol: "SNAP", Timestamp: t0.Add(40 * time.Second), Price: 20.12, Size: 50,
+	})
+
+	results := calc.Results()
+	if len(results) != 3 {
+		t.Fatalf("expected 3 benchmarks, got %v", len(results))
+	}
+
+	if results[0].HasQuote {
+		t.Error("expected no arrival quote for FB")
+	}
+
+	buy := results[1]
+	if !buy.HasQuote {
+		t.Fatal("expected an arrival quote for the SNAP buy")
+	}
+	if buy.ArrivalBid != 20.00 || buy.ArrivalAsk != 20.10 {
+		t.Errorf("unexpected arrival quote: %v x %v", buy.ArrivalBid, buy.ArrivalAsk)
+	}
+	if !almostEqual(buy.ArrivalMid, 20.05) || !almostEqual(buy.QuotedSpread, 0.10) {
+		t.Errorf("unexpected mid/spread: %v / %v", buy.ArrivalMid, buy.QuotedSpread)
+	}
+	if !almostEqual(buy.SlippagePerShare, 0.03) {
+		t.Errorf("unexpected slippage: %v", buy.SlippagePerShare)
+	}
+	if !almostEqual(buy.SlippageBPS, 0.03/20.05*1e4) {
+		t.Errorf("unexpected slippage bps: %v", buy.SlippageBPS)
+	}
+	if !almostEqual(buy.SpreadCapture, 0.4) {
+		t.Errorf("unexpected spread capture: %v", buy.SpreadCapture)
+	}
+
+	// The sell arrived after the second quote update: mid 20.15, so
+	// selling at 20.12 cost 0.03/share.
+	sell := results[2]
+	if !almostEqual(sell.ArrivalMid, 20.15) || !almostEqual(sell.SlippagePerShare, 0.03) {
+		t.Errorf("unexpected sell benchmark: mid %v slippage %v",
+			sell.ArrivalMid, sell.SlippagePerShare)
+	}
+}
+
+func TestCalculatorWriteCSV(t *testing.T) {
+	executions := []*Execution{
+		{Symbol: "SNAP", Timestamp: t0.Add(10 * time.Second), Side: Buy, Size: 100, Price: 20.08},
+	}
+
+	calc := NewCalculator(executions)
+	calc.OnMessage(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", Timestamp: t0, BidPrice: 20.00, AskPrice: 20.10,
+	})
+
+	var buf bytes.Buffer
+	if err := calc.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %v lines", len(lines))
+	}
+
+	expected := "SNAP,2017-04-17T14:30:10Z,B,100,20.0800," +
+		"20.0000,20.1000,20.0500,0.1000,0.0300,14.96,0.40"
+	if lines[1] != expected {
+		t.Errorf("unexpected row:\n got: %v\nwant: %v", lines[1], expected)
+	}
+}
@@ -0,0 +1,154 @@
+package tickstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is a TickStorage persisting ticks as per-symbol NDJSON
+// files under a directory, one <SYMBOL>.trades.ndjson and
+// <SYMBOL>.quotes.ndjson per symbol. Files are opened in append mode,
+// so a store can be reopened over an existing directory to extend it
+// with further days.
+type FileStore struct {
+	dir   string
+	files map[string]*fileAppender
+}
+
+// fileAppender is one open append-mode file with its buffered writer.
+type fileAppender struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+// NewFileStore creates a store rooted at the given directory,
+// creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:   dir,
+		files: make(map[string]*fileAppender),
+	}, nil
+}
+
+// AppendTrade implements TickStorage.
+func (s *FileStore) AppendTrade(trade *Trade) error {
+	return s.appendJSON(trade.Symbol+".trades.ndjson", trade)
+}
+
+// AppendQuote implements TickStorage.
+func (s *FileStore) AppendQuote(quote *Quote) error {
+	return s.appendJSON(quote.Symbol+".quotes.ndjson", quote)
+}
+
+// QueryTrades implements TickStorage.
+func (s *FileStore) QueryTrades(q Query) ([]*Trade, error) {
+	var result []*Trade
+	err := s.scan(q.Symbol+".trades.ndjson", func(line []byte) error {
+		trade := &Trade{}
+		if err := json.Unmarshal(line, trade); err != nil {
+			return err
+		}
+
+		if q.matches(trade.Timestamp) {
+			result = append(result, trade)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// QueryQuotes implements TickStorage.
+func (s *FileStore) QueryQuotes(q Query) ([]*Quote, error) {
+	var result []*Quote
+	err := s.scan(q.Symbol+".quotes.ndjson", func(line []byte) error {
+		quote := &Quote{}
+		if err := json.Unmarshal(line, quote); err != nil {
+			return err
+		}
+
+		if q.matches(quote.Timestamp) {
+			result = append(result, quote)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// Close flushes and closes all open files. The store may not be used
+// afterwards.
+func (s *FileStore) Close() error {
+	var firstErr error
+	for _, appender := range s.files {
+		if err := appender.w.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := appender.f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	s.files = make(map[string]*fileAppender)
+	return firstErr
+}
+
+func (s *FileStore) appendJSON(name string, tick interface{}) error {
+	appender, ok := s.files[name]
+	if !ok {
+		f, err := os.OpenFile(filepath.Join(s.dir, name),
+			os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+
+		appender = &fileAppender{f: f, w: bufio.NewWriter(f)}
+		s.files[name] = appender
+	}
+
+	data, err := json.Marshal(tick)
+	if err != nil {
+		return err
+	}
+
+	if _, err := appender.w.Write(data); err != nil {
+		return err
+	}
+	return appender.w.WriteByte('\n')
+}
+
+// scan invokes fn with each line of the named file. A file that does
+// not exist yields no lines. Buffered appends are flushed first so
+// queries see every tick appended so far.
+func (s *FileStore) scan(name string, fn func(line []byte) error) error {
+	if appender, ok := s.files[name]; ok {
+		if err := appender.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
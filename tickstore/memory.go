@@ -0,0 +1,57 @@
+package tickstore
+
+// MemoryStore is an in-memory TickStorage, for tests and small
+// single-day jobs.
+type MemoryStore struct {
+	trades map[string][]*Trade
+	quotes map[string][]*Quote
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		trades: make(map[string][]*Trade),
+		quotes: make(map[string][]*Quote),
+	}
+}
+
+// AppendTrade implements TickStorage.
+func (s *MemoryStore) AppendTrade(trade *Trade) error {
+	s.trades[trade.Symbol] = append(s.trades[trade.Symbol], trade)
+	return nil
+}
+
+// AppendQuote implements TickStorage.
+func (s *MemoryStore) AppendQuote(quote *Quote) error {
+	s.quotes[quote.Symbol] = append(s.quotes[quote.Symbol], quote)
+	return nil
+}
+
+// QueryTrades implements TickStorage.
+func (s *MemoryStore) QueryTrades(q Query) ([]*Trade, error) {
+	var result []*Trade
+	for _, trade := range s.trades[q.Symbol] {
+		if q.matches(trade.Timestamp) {
+			result = append(result, trade)
+		}
+	}
+
+	return result, nil
+}
+
+// QueryQuotes implements TickStorage.
+func (s *MemoryStore) QueryQuotes(q Query) ([]*Quote, error) {
+	var result []*Quote
+	for _, quote := range s.quotes[q.Symbol] {
+		if q.matches(quote.Timestamp) {
+			result = append(result, quote)
+		}
+	}
+
+	return result, nil
+}
+
+// Close implements TickStorage. It is a no-op.
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package tickstore defines a pluggable storage interface for decoded
+// trade and quote ticks, with a common query API over symbol and time
+// range. The in-memory driver backs unit tests and small jobs; the
+// file driver persists multi-day datasets with no external
+// dependencies. Drivers for external databases (e.g. ClickHouse over
+// its HTTP interface) implement the same interface out of tree.
+package tickstore
+
+import (
+	"time"
+
+	"github.com/timpalpant/go-iex"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// Trade is one stored trade tick.
+type Trade struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	Size      uint32    `json:"size"`
+	TradeID   int64     `json:"tradeId"`
+}
+
+// Quote is one stored quote tick.
+type Quote struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+	BidPrice  float64   `json:"bidPrice"`
+	BidSize   uint32    `json:"bidSize"`
+	AskPrice  float64   `json:"askPrice"`
+	AskSize   uint32    `json:"askSize"`
+}
+
+// Query selects the ticks of one symbol within [Start, End). A zero
+// Start or End leaves that bound open.
+type Query struct {
+	Symbol string
+	Start  time.Time
+	End    time.Time
+}
+
+// matches reports whether a tick at the given time falls within the
+// query window.
+func (q Query) matches(ts time.Time) bool {
+	if !q.Start.IsZero() && ts.Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && !ts.Before(q.End) {
+		return false
+	}
+
+	return true
+}
+
+// TickStorage stores decoded ticks and answers range queries over
+// them. Appends must be in feed order; queries return ticks in that
+// order. Implementations are not safe for concurrent use unless
+// documented otherwise.
+type TickStorage interface {
+	AppendTrade(trade *Trade) error
+	AppendQuote(quote *Quote) error
+	QueryTrades(q Query) ([]*Trade, error)
+	QueryQuotes(q Query) ([]*Quote, error)
+	Close() error
+}
+
+// TradeFromMessage converts a decoded trade report to a stored tick.
+func TradeFromMessage(msg *tops.TradeReportMessage) *Trade {
+	return &Trade{
+		Symbol:    msg.Symbol,
+		Timestamp: msg.Timestamp,
+		Price:     msg.Price,
+		Size:      msg.Size,
+		TradeID:   msg.TradeID,
+	}
+}
+
+// QuoteFromMessage converts a decoded quote update to a stored tick.
+func QuoteFromMessage(msg *tops.QuoteUpdateMessage) *Quote {
+	return &Quote{
+		Symbol:    msg.Symbol,
+		Timestamp: msg.Timestamp,
+		BidPrice:  msg.BidPrice,
+		BidSize:   msg.BidSize,
+		AskPrice:  msg.AskPrice,
+		AskSize:   msg.AskSize,
+	}
+}
+
+// TradeFromEnvelope converts the envelope's message to a stored tick
+// stamped with the envelope's time under the given base, or nil if it
+// is not a trade report.
+func TradeFromEnvelope(e *iex.MessageEnvelope, base iex.TimeBase) *Trade {
+	msg, ok := e.Message.(*tops.TradeReportMessage)
+	if !ok {
+		return nil
+	}
+
+	trade := TradeFromMessage(msg)
+	trade.Timestamp = base.Time(e)
+	return trade
+}
+
+// QuoteFromEnvelope converts the envelope's message to a stored tick
+// stamped with the envelope's time under the given base, or nil if it
+// is not a quote update.
+func QuoteFromEnvelope(e *iex.MessageEnvelope, base iex.TimeBase) *Quote {
+	msg, ok := e.Message.(*tops.QuoteUpdateMessage)
+	if !ok {
+		return nil
+	}
+
+	quote := QuoteFromMessage(msg)
+	quote.Timestamp = base.Time(e)
+	return quote
+}
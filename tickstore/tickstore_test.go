@@ -0,0 +1,140 @@
+package tickstore
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var t0 = time.Date(2017, time.April, 17, 14, 30, 0, 0, time.UTC)
+
+// testStorage exercises the TickStorage contract against any driver.
+func testStorage(t *testing.T, store TickStorage) {
+	t.Helper()
+
+	trades := []*Trade{
+		{Symbol: "SNAP", Timestamp: t0, Price: 20.10, Size: 100, TradeID: 1},
+		{Symbol: "SNAP", Timestamp: t0.Add(time.Minute), Price: 20.25, Size: 200, TradeID: 2},
+		{Symbol: "SNAP", Timestamp: t0.Add(2 * time.Minute), Price: 20.05, Size: 100, TradeID: 3},
+		{Symbol: "FB", Timestamp: t0, Price: 140.00, Size: 50, TradeID: 4},
+	}
+	for _, trade := range trades {
+		if err := store.AppendTrade(trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AppendQuote(&Quote{
+		Symbol: "SNAP", Timestamp: t0,
+		BidPrice: 20.00, BidSize: 300, AskPrice: 20.10, AskSize: 400,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A bounded window selects only the ticks inside [Start, End).
+	got, err := store.QueryTrades(Query{
+		Symbol: "SNAP",
+		Start:  t0.Add(time.Minute),
+		End:    t0.Add(2 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], trades[1]) {
+		t.Errorf("unexpected window result: %+v", got)
+	}
+
+	// Zero bounds are open: all of the symbol's trades, in order.
+	got, err = store.QueryTrades(Query{Symbol: "SNAP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].TradeID != 1 || got[2].TradeID != 3 {
+		t.Errorf("unexpected unbounded result: %+v", got)
+	}
+
+	quotes, err := store.QueryQuotes(Query{Symbol: "SNAP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].BidPrice != 20.00 {
+		t.Errorf("unexpected quotes: %+v", quotes)
+	}
+
+	// An unknown symbol yields no ticks, not an error.
+	if got, err := store.QueryTrades(Query{Symbol: "ZIEXT"}); err != nil || len(got) != 0 {
+		t.Errorf("unexpected unknown-symbol result: %v, %v", got, err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStorage(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStorage(t, store)
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendTrade(&Trade{
+		Symbol: "SNAP", Timestamp: t0, Price: 20.10, Size: 100, TradeID: 1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening the directory extends the existing files.
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.AppendTrade(&Trade{
+		Symbol: "SNAP", Timestamp: t0.Add(24 * time.Hour), Price: 21.00, Size: 200, TradeID: 2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.QueryTrades(Query{Symbol: "SNAP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].TradeID != 1 || got[1].TradeID != 2 {
+		t.Errorf("unexpected trades after reopen: %+v", got)
+	}
+}
+
+func TestTickFromMessage(t *testing.T) {
+	trade := TradeFromMessage(&tops.TradeReportMessage{
+		Symbol: "SNAP", Timestamp: t0, Price: 20.10, Size: 100, TradeID: 7,
+	})
+	if trade.Symbol != "SNAP" || trade.Price != 20.10 || trade.TradeID != 7 {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+
+	quote := QuoteFromMessage(&tops.QuoteUpdateMessage{
+		Symbol: "SNAP", Timestamp: t0,
+		BidPrice: 20.00, BidSize: 300, AskPrice: 20.10, AskSize: 400,
+	})
+	if quote.AskPrice != 20.10 || quote.BidSize != 300 {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+}
@@ -0,0 +1,197 @@
+// Package timeline builds a chronological per-symbol timeline of
+// trading status changes, operational halts, and short sale price
+// test changes from a decode pass over a HIST dump. The status
+// message types are shared between TOPS and DEEP, so either feed (or
+// a merge of both) can drive it.
+package timeline
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp"
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+// EventType identifies the kind of status change.
+type EventType string
+
+const (
+	TradingStatus      EventType = "trading_status"
+	OperationalHalt    EventType = "operational_halt"
+	ShortSalePriceTest EventType = "short_sale_price_test"
+)
+
+// Event is one status change for a symbol.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Symbol    string    `json:"symbol"`
+	Type      EventType `json:"type"`
+	// The decoded new status, e.g. "halted" or "trading".
+	Status string `json:"status"`
+	// Halt reason or short sale restriction detail, when populated.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Timeline accumulates status change events for all symbols seen in a
+// feed. Repeats of a symbol's current status (such as the pre-market
+// spin re-asserting it) are not recorded; only transitions are.
+type Timeline struct {
+	events []*Event
+	// Last recorded status per symbol and event type, for dropping
+	// no-change repeats.
+	last map[EventType]map[string]string
+}
+
+// NewTimeline creates an empty timeline.
+func NewTimeline() *Timeline {
+	return &Timeline{
+		last: map[EventType]map[string]string{
+			TradingStatus:      make(map[string]string),
+			OperationalHalt:    make(map[string]string),
+			ShortSalePriceTest: make(map[string]string),
+		},
+	}
+}
+
+// OnMessage incorporates the next message from the stream. Messages
+// other than the status types are ignored.
+func (t *Timeline) OnMessage(msg iextp.Message) {
+	switch msg := msg.(type) {
+	case *tops.TradingStatusMessage:
+		t.record(&Event{
+			Timestamp: msg.Timestamp,
+			Symbol:    msg.Symbol,
+			Type:      TradingStatus,
+			Status:    tradingStatusName(msg.TradingStatus),
+			Detail:    msg.Reason,
+		})
+	case *tops.OperationalHaltStatusMessage:
+		t.record(&Event{
+			Timestamp: msg.Timestamp,
+			Symbol:    msg.Symbol,
+			Type:      OperationalHalt,
+			Status:    operationalHaltName(msg.OperationalHaltStatus),
+		})
+	case *tops.ShortSalePriceTestStatusMessage:
+		status := "not_in_effect"
+		if msg.ShortSalePriceTestStatus {
+			status = "in_effect"
+		}
+		t.record(&Event{
+			Timestamp: msg.Timestamp,
+			Symbol:    msg.Symbol,
+			Type:      ShortSalePriceTest,
+			Status:    status,
+			Detail:    shortSaleDetailName(msg.Detail),
+		})
+	}
+}
+
+func (t *Timeline) record(event *Event) {
+	if t.last[event.Type][event.Symbol] == event.Status {
+		return
+	}
+
+	t.last[event.Type][event.Symbol] = event.Status
+	t.events = append(t.events, event)
+}
+
+// Events returns the recorded status changes in chronological order.
+func (t *Timeline) Events() []*Event {
+	sort.SliceStable(t.events, func(i, j int) bool {
+		if !t.events[i].Timestamp.Equal(t.events[j].Timestamp) {
+			return t.events[i].Timestamp.Before(t.events[j].Timestamp)
+		}
+		return t.events[i].Symbol < t.events[j].Symbol
+	})
+
+	return t.events
+}
+
+var csvHeader = []string{"time", "symbol", "type", "status", "detail"}
+
+// WriteCSV writes the timeline in chronological order, one event per
+// row.
+func (t *Timeline) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, event := range t.Events() {
+		row := []string{
+			event.Timestamp.Format(time.RFC3339Nano),
+			event.Symbol,
+			string(event.Type),
+			event.Status,
+			event.Detail,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the timeline in chronological order, one JSON
+// event per line.
+func (t *Timeline) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, event := range t.Events() {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func tradingStatusName(status uint8) string {
+	switch status {
+	case tops.TradingHalt:
+		return "halted"
+	case tops.TradingOrderAcceptancePeriod:
+		return "order_acceptance_period"
+	case tops.TradingPaused:
+		return "paused"
+	case tops.Trading:
+		return "trading"
+	}
+
+	return fmt.Sprintf("unknown (0x%x)", status)
+}
+
+func operationalHaltName(status uint8) string {
+	switch status {
+	case tops.IEXSpecificOperationalHalt:
+		return "operationally_halted"
+	case tops.NotOperationallyHalted:
+		return "not_halted"
+	}
+
+	return fmt.Sprintf("unknown (0x%x)", status)
+}
+
+func shortSaleDetailName(detail uint8) string {
+	switch detail {
+	case tops.NoPriceTest:
+		return ""
+	case tops.ShortSalePriceTestActivated:
+		return "activated"
+	case tops.ShortSalePriceTestContinued:
+		return "continued"
+	case tops.ShortSalePriceTestDeactivated:
+		return "deactivated"
+	case tops.DetailNotAvailable:
+		return "not_available"
+	}
+
+	return fmt.Sprintf("unknown (0x%x)", detail)
+}
@@ -0,0 +1,95 @@
+package timeline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-iex/iextp/tops"
+)
+
+var t0 = time.Date(2017, time.April, 17, 8, 0, 0, 0, time.UTC)
+
+func TestTimeline(t *testing.T) {
+	timeline := NewTimeline()
+
+	// Pre-market spin: initial statuses are recorded once each.
+	timeline.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.Trading, Timestamp: t0, Symbol: "SNAP",
+	})
+	timeline.OnMessage(&tops.ShortSalePriceTestStatusMessage{
+		ShortSalePriceTestStatus: true,
+		Detail:                   tops.ShortSalePriceTestContinued,
+		Timestamp:                t0,
+		Symbol:                   "SNAP",
+	})
+	// A repeat of the current status is not a transition.
+	timeline.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.Trading, Timestamp: t0.Add(time.Second), Symbol: "SNAP",
+	})
+
+	// An intraday halt and release.
+	timeline.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.TradingHalt,
+		Reason:        tops.HaltNewsPending,
+		Timestamp:     t0.Add(2 * time.Hour),
+		Symbol:        "SNAP",
+	})
+	timeline.OnMessage(&tops.OperationalHaltStatusMessage{
+		OperationalHaltStatus: tops.IEXSpecificOperationalHalt,
+		Timestamp:             t0.Add(3 * time.Hour),
+		Symbol:                "FB",
+	})
+	timeline.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.Trading,
+		Timestamp:     t0.Add(4 * time.Hour),
+		Symbol:        "SNAP",
+	})
+
+	events := timeline.Events()
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %v: %+v", len(events), events)
+	}
+
+	halt := events[2]
+	if halt.Type != TradingStatus || halt.Symbol != "SNAP" ||
+		halt.Status != "halted" || halt.Detail != tops.HaltNewsPending {
+		t.Errorf("unexpected halt event: %+v", halt)
+	}
+	if events[3].Status != "operationally_halted" || events[3].Symbol != "FB" {
+		t.Errorf("unexpected operational halt event: %+v", events[3])
+	}
+	if events[4].Status != "trading" {
+		t.Errorf("unexpected release event: %+v", events[4])
+	}
+	if events[1].Type != ShortSalePriceTest || events[1].Status != "in_effect" ||
+		events[1].Detail != "continued" {
+		t.Errorf("unexpected short sale event: %+v", events[1])
+	}
+}
+
+func TestTimelineWriteCSV(t *testing.T) {
+	timeline := NewTimeline()
+	timeline.OnMessage(&tops.TradingStatusMessage{
+		TradingStatus: tops.TradingHalt,
+		Reason:        tops.HaltNewsPending,
+		Timestamp:     t0,
+		Symbol:        "SNAP",
+	})
+
+	var buf bytes.Buffer
+	if err := timeline.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %v lines", len(lines))
+	}
+
+	expected := "2017-04-17T08:00:00Z,SNAP,trading_status,halted,T1"
+	if lines[1] != expected {
+		t.Errorf("unexpected row:\n got: %v\nwant: %v", lines[1], expected)
+	}
+}
@@ -0,0 +1,94 @@
+package iex
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UniverseEntry is one symbol of a universe spec, optionally tagged
+// with its sector.
+type UniverseEntry struct {
+	Symbol string `json:"symbol"`
+	Sector string `json:"sector,omitempty"`
+}
+
+// Universe is a set of symbols to restrict processing to, shared by
+// the command-line tools via their -universe flags.
+type Universe struct {
+	entries map[string]UniverseEntry
+}
+
+// LoadUniverse loads a universe spec from the given file. Files
+// ending in .json hold a JSON array of entries ({"symbol": ...,
+// "sector": ...}) or bare symbol strings; any other file is plain
+// text with one symbol per line, ignoring blank lines and #-comments.
+func LoadUniverse(path string) (*Universe, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	u := &Universe{entries: make(map[string]UniverseEntry)}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		var raw []json.RawMessage
+		if err := json.NewDecoder(f).Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		for _, item := range raw {
+			var entry UniverseEntry
+			if err := json.Unmarshal(item, &entry); err != nil {
+				// Fall back to a bare symbol string.
+				if err := json.Unmarshal(item, &entry.Symbol); err != nil {
+					return nil, err
+				}
+			}
+			u.add(entry)
+		}
+
+		return u, nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u.add(UniverseEntry{Symbol: line})
+	}
+
+	return u, scanner.Err()
+}
+
+func (u *Universe) add(entry UniverseEntry) {
+	entry.Symbol = strings.ToUpper(entry.Symbol)
+	u.entries[entry.Symbol] = entry
+}
+
+// Contains returns whether the given symbol is in the universe.
+func (u *Universe) Contains(symbol string) bool {
+	_, ok := u.entries[strings.ToUpper(strings.TrimSpace(symbol))]
+	return ok
+}
+
+// Sector returns the sector recorded for the given symbol, if any.
+func (u *Universe) Sector(symbol string) (sector string, ok bool) {
+	entry, ok := u.entries[strings.ToUpper(strings.TrimSpace(symbol))]
+	return entry.Sector, ok && entry.Sector != ""
+}
+
+// Symbols returns the symbols of the universe, sorted.
+func (u *Universe) Symbols() []string {
+	symbols := make([]string, 0, len(u.entries))
+	for symbol := range u.entries {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
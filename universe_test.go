@@ -0,0 +1,55 @@
+package iex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadUniverse_PlainText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "universe.txt")
+	spec := "# watchlist\nSNAP\nfb\n\nAAPL\n"
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := LoadUniverse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := u.Symbols()
+	if len(symbols) != 3 || symbols[0] != "AAPL" || symbols[1] != "FB" || symbols[2] != "SNAP" {
+		t.Errorf("unexpected symbols: %v", symbols)
+	}
+	if !u.Contains("fb") || u.Contains("ZIEXT") {
+		t.Error("unexpected membership")
+	}
+	if _, ok := u.Sector("SNAP"); ok {
+		t.Error("expected no sector for plain text universe")
+	}
+}
+
+func TestLoadUniverse_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "universe.json")
+	spec := `[{"symbol": "SNAP", "sector": "Technology"}, "FB"]`
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := LoadUniverse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !u.Contains("SNAP") || !u.Contains("FB") {
+		t.Errorf("unexpected symbols: %v", u.Symbols())
+	}
+	sector, ok := u.Sector("SNAP")
+	if !ok || sector != "Technology" {
+		t.Errorf("unexpected sector: %v (ok=%v)", sector, ok)
+	}
+	if _, ok := u.Sector("FB"); ok {
+		t.Error("expected no sector for bare string entry")
+	}
+}